@@ -9,11 +9,15 @@ import (
 	providercfg "github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci/config"
 	"github.com/nom3ad/oci-lb-ingress-controller/src/configholder"
 	"github.com/nom3ad/oci-lb-ingress-controller/src/controller"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/health"
 	"github.com/nom3ad/oci-lb-ingress-controller/src/ingress"
+	ingressmanager "github.com/nom3ad/oci-lb-ingress-controller/src/manager"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/metrics"
 	"github.com/nom3ad/oci-lb-ingress-controller/version"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 func main() {
@@ -37,6 +41,10 @@ func main() {
 	zap.ReplaceGlobals(logger)
 	defer logger.Sync()
 
+	if len(os.Args) > 1 && os.Args[1] == "render" {
+		os.Exit(runRender(os.Args[2:], logger))
+	}
+
 	configPath := flag.String("config", "config.yml", "Path to config file")
 	ingressClass := flag.String("ingress-class", "oci", "Ingress class to be used")
 	controllerName := flag.String("controller-name", "ingress.beta.kubernetes.io/oci", "controller name.  must be a domain-prefixed path (such as 'acme.io/foo')")
@@ -44,8 +52,30 @@ func main() {
 	defaultLoadBalancerShape := flag.String("default-loadbalancer-shape", "", "Default loadbalancer shape.  eg: 'flexible', '10mbps', '100mbps'")
 	defaultFlexShapeMinMbps := flag.Int("default-flexible-shape-min-mbps", 0, "Default minimum bandwidth if loadbalancer shape is 'flexible'")
 	defaultFlexShapeMaxMbps := flag.Int("default-flexible-shape-max-mbps", 0, "Default maximum bandwidth if loadbalancer shape is 'flexible'")
+	backendNodeSelector := flag.String("backend-node-selector", "", "Label selector nodes must match to be loadbalancer backends. eg: 'pool=web,!spot'")
+	backendDrainPeriod := flag.Duration("backend-drain-period", ingress.BackendDrainPeriod, "How long backends removed from a backend set are drained before they are removed. 0 removes them right away")
 	forceHTTPSRedirection := flag.Bool("force-https-redirection", false, "If set HTTPS Redirection will be forced for ingresses by default")
 
+	maxConcurrentReconciles := flag.Int("max-concurrent-reconciles", controller.MaxConcurrentReconciles, "Number of ingresses reconciled in parallel")
+	leaderElect := flag.Bool("leader-elect", controller.LeaderElection, "Enable leader election. Required when running more than one replica")
+	leaderElectionID := flag.String("leader-election-id", controller.LeaderElectionID, "Name of the lease object used for leader election")
+	leaderElectionNamespace := flag.String("leader-election-namespace", controller.LeaderElectionNamespace, "Namespace of the leader election lease. Defaults to the namespace of the controller if running in cluster")
+	leaseDuration := flag.Duration("leader-election-lease-duration", controller.LeaseDuration, "Duration non-leaders wait before trying to acquire leadership")
+	renewDeadline := flag.Duration("leader-election-renew-deadline", controller.RenewDeadline, "Duration the leader retries refreshing leadership before giving it up")
+	retryPeriod := flag.Duration("leader-election-retry-period", controller.RetryPeriod, "Duration between leader election attempts")
+	metricsBindAddress := flag.String("metrics-bind-address", controller.MetricsBindAddress, "The address the prometheus metrics endpoint binds to. '0' disables it")
+	webhookPort := flag.Int("webhook-port", controller.WebhookPort, "Port the validating admission webhook for ingresses is served on. 0 disables it")
+	webhookCertDir := flag.String("webhook-cert-dir", controller.WebhookCertDir, "Directory holding tls.crt and tls.key of the webhook server")
+	healthProbeBindAddress := flag.String("health-probe-bind-address", controller.HealthProbeBindAddress, "The address /healthz and /readyz endpoints bind to. '0' disables them")
+	reconcileDeadline := flag.Duration("reconcile-deadline", health.ReconcileDeadline, "Liveness fails if a single reconcile runs longer than this. 0 disables the check")
+	ociMetricsPushInterval := flag.Duration("oci-metrics-push-interval", metrics.OCIMonitoringPushInterval, "Interval between pushes of metrics to OCI Monitoring. Only used if 'metrics' is set in config")
+	orphanGCInterval := flag.Duration("orphan-gc-interval", ingressmanager.OrphanGCInterval, "Interval between sweeps for orphaned loadbalancers. 0 disables the collector")
+	orphanGCGracePeriod := flag.Duration("orphan-gc-grace-period", ingressmanager.OrphanGCGracePeriod, "How long a loadbalancer must stay orphaned before it is deleted")
+	orphanGCDryRun := flag.Bool("orphan-gc-dry-run", ingressmanager.OrphanGCDryRun, "If set orphaned loadbalancers are only reported, not deleted")
+	clusterID := flag.String("cluster-id", ingressmanager.ClusterID, "Identifies the cluster in the ClusterID freeform tag of loadbalancers. Defaults to the UID of the kube-system namespace")
+	planOnly := flag.Bool("plan-only", ingressmanager.PlanOnly, "If set changes to loadbalancers are only logged and recorded as events, not applied. Implies -orphan-gc-dry-run")
+	enableGatewayAPI := flag.Bool("enable-gateway-api", controller.GatewayAPI, "If set Gateways of GatewayClasses with the controller name, and their HTTPRoutes, are reconciled too. Requires Gateway API CRDs")
+
 	flag.Parse()
 
 	// Config loading
@@ -59,11 +89,21 @@ func main() {
 	if ingressClass != nil && *ingressClass != "" {
 		ingress.OCILoadbalancerIngressClass = *ingressClass
 	}
+	if backendNodeSelector != nil && *backendNodeSelector != "" {
+		if _, err := labels.Parse(*backendNodeSelector); err != nil {
+			logger.Sugar().Fatalf("Invalid -backend-node-selector %q: %s", *backendNodeSelector, err)
+		}
+		ingress.BackendNodeSelector = *backendNodeSelector
+	}
+	if backendDrainPeriod != nil && *backendDrainPeriod >= 0 {
+		ingress.BackendDrainPeriod = *backendDrainPeriod
+	}
 	if forceHTTPSRedirection != nil {
 		ingress.ForceHTTPSRedirectionByDefault = *forceHTTPSRedirection
 	}
 	if controllerName != nil && *controllerName != "" {
 		controller.ControllerName = *controllerName
+		ingress.GatewayControllerName = *controllerName
 	}
 	if defaultLoadBalancerSubnetIds != nil && *defaultLoadBalancerSubnetIds != "" {
 		configholder.DefaultLoadBalancerSubnetIds = *defaultLoadBalancerSubnetIds
@@ -77,11 +117,76 @@ func main() {
 	if defaultFlexShapeMaxMbps != nil && *defaultFlexShapeMaxMbps != 0 {
 		ingress.DefaultFlexShapeMaxMbps = *defaultFlexShapeMaxMbps
 	}
+	if maxConcurrentReconciles != nil && *maxConcurrentReconciles > 0 {
+		controller.MaxConcurrentReconciles = *maxConcurrentReconciles
+	}
+	if leaderElect != nil {
+		controller.LeaderElection = *leaderElect
+	}
+	if leaderElectionID != nil && *leaderElectionID != "" {
+		controller.LeaderElectionID = *leaderElectionID
+	}
+	if leaderElectionNamespace != nil && *leaderElectionNamespace != "" {
+		controller.LeaderElectionNamespace = *leaderElectionNamespace
+	}
+	if leaseDuration != nil && *leaseDuration > 0 {
+		controller.LeaseDuration = *leaseDuration
+	}
+	if renewDeadline != nil && *renewDeadline > 0 {
+		controller.RenewDeadline = *renewDeadline
+	}
+	if retryPeriod != nil && *retryPeriod > 0 {
+		controller.RetryPeriod = *retryPeriod
+	}
+	if metricsBindAddress != nil && *metricsBindAddress != "" {
+		controller.MetricsBindAddress = *metricsBindAddress
+	}
+	if webhookPort != nil {
+		controller.WebhookPort = *webhookPort
+	}
+	if webhookCertDir != nil && *webhookCertDir != "" {
+		controller.WebhookCertDir = *webhookCertDir
+	}
+	if healthProbeBindAddress != nil && *healthProbeBindAddress != "" {
+		controller.HealthProbeBindAddress = *healthProbeBindAddress
+	}
+	if reconcileDeadline != nil {
+		health.ReconcileDeadline = *reconcileDeadline
+	}
+	if ociMetricsPushInterval != nil && *ociMetricsPushInterval > 0 {
+		metrics.OCIMonitoringPushInterval = *ociMetricsPushInterval
+	}
+	if orphanGCInterval != nil {
+		ingressmanager.OrphanGCInterval = *orphanGCInterval
+	}
+	if orphanGCGracePeriod != nil {
+		ingressmanager.OrphanGCGracePeriod = *orphanGCGracePeriod
+	}
+	if orphanGCDryRun != nil {
+		ingressmanager.OrphanGCDryRun = *orphanGCDryRun
+	}
+	if clusterID != nil && *clusterID != "" {
+		ingressmanager.ClusterID = *clusterID
+	}
+	if planOnly != nil {
+		ingressmanager.PlanOnly = *planOnly
+	}
+	if ingressmanager.PlanOnly {
+		ingressmanager.OrphanGCDryRun = true
+	}
+	if enableGatewayAPI != nil {
+		controller.GatewayAPI = *enableGatewayAPI
+	}
 
 	logger.Sugar().With("OCILoadbalancerIngressClass", ingress.OCILoadbalancerIngressClass, "ControllerName", controller.ControllerName,
 		"ForceHTTPSRedirectionByDefault", ingress.ForceHTTPSRedirectionByDefault, "DefaultLoadBalancerSubnetIds", configholder.DefaultLoadBalancerSubnetIds,
-		"DefaultLBShape", ingress.DefaultLBShape, "DefaultFlexShapeMinMbps", ingress.DefaultFlexShapeMinMbps,
-		"DefaultFlexShapeMaxMbps", ingress.DefaultFlexShapeMaxMbps).Info("Settings")
+		"DefaultLBShape", ingress.DefaultLBShape, "BackendNodeSelector", ingress.BackendNodeSelector, "BackendDrainPeriod", ingress.BackendDrainPeriod, "DefaultFlexShapeMinMbps", ingress.DefaultFlexShapeMinMbps,
+		"DefaultFlexShapeMaxMbps", ingress.DefaultFlexShapeMaxMbps, "OrphanGCInterval", ingressmanager.OrphanGCInterval, "MetricsBindAddress", controller.MetricsBindAddress,
+		"HealthProbeBindAddress", controller.HealthProbeBindAddress, "WebhookPort", controller.WebhookPort, "ReconcileDeadline", health.ReconcileDeadline,
+		"MaxConcurrentReconciles", controller.MaxConcurrentReconciles, "LeaderElection", controller.LeaderElection, "LeaderElectionID", controller.LeaderElectionID,
+		"OCIMonitoringPushInterval", metrics.OCIMonitoringPushInterval,
+		"OrphanGCGracePeriod", ingressmanager.OrphanGCGracePeriod, "OrphanGCDryRun", ingressmanager.OrphanGCDryRun, "PlanOnly", ingressmanager.PlanOnly,
+		"GatewayAPI", controller.GatewayAPI).Info("Settings")
 
 	// Start ingress controller
 	logger.Sugar().With("kubernetes.io/ingress.class", ingress.OCILoadbalancerIngressClass, "controllerName", controller.ControllerName).Infof("Starting ingress controller")
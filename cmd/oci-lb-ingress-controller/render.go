@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/nom3ad/oci-lb-ingress-controller/src/ingress"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/render"
+	"go.uber.org/zap"
+)
+
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringsFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// runRender implements the render subcommand, which prints the load balancer spec of ingresses read from manifest files
+func runRender(args []string, logger *zap.Logger) int {
+	fs := flag.NewFlagSet("render", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s render -f ingress.yaml [-f services.yaml ...] [-o json|yaml]\n\n", os.Args[0])
+		fmt.Fprintln(fs.Output(), "Renders Ingress manifests into the OCI load balancer spec, without cluster or OCI access.")
+		fmt.Fprintln(fs.Output(), "Services, Nodes and Secrets referenced by ingresses must be given too. Private keys are redacted.")
+		fmt.Fprintln(fs.Output(), "So must be EndpointSlices of services, for ingresses targeting pods and for services with externalTrafficPolicy=Local.")
+		fs.PrintDefaults()
+	}
+	var files stringsFlag
+	fs.Var(&files, "f", "Manifest file with Ingress, Service, Node, Secret or EndpointSlice objects. '-' reads stdin. Can be repeated")
+	output := fs.String("o", "yaml", "Output format. 'json' or 'yaml'")
+	subnets := fs.String("subnets", "", "comma separated list of subnet ocids (max=2). Placeholder is used if not set")
+	defaultLoadBalancerShape := fs.String("default-loadbalancer-shape", "", "Default loadbalancer shape.  eg: 'flexible', '10mbps', '100mbps'")
+	forceHTTPSRedirection := fs.Bool("force-https-redirection", false, "If set HTTPS Redirection will be forced for ingresses by default")
+	fs.Parse(args)
+
+	if len(files) == 0 {
+		fs.Usage()
+		return 2
+	}
+	if *defaultLoadBalancerShape != "" {
+		ingress.DefaultLBShape = *defaultLoadBalancerShape
+	}
+	ingress.ForceHTTPSRedirectionByDefault = *forceHTTPSRedirection
+
+	var manifests []io.Reader
+	for _, path := range files {
+		if path == "-" {
+			manifests = append(manifests, os.Stdin)
+			continue
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer f.Close()
+		manifests = append(manifests, f)
+	}
+	opts := render.Options{}
+	for _, s := range strings.SplitN(*subnets, ",", 2) {
+		if s = strings.TrimSpace(s); s != "" {
+			opts.Subnets = append(opts.Subnets, s)
+		}
+	}
+	specs, err := render.Render(manifests, opts, logger)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := render.Write(os.Stdout, specs, *output); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -94,6 +94,101 @@ const (
 	// specifying a reserved IP for the load balancer.
 	AnnotationLoadBalancerReservedIP = "oci-load-balancer-reserved-ip"
 
+	// AnnotationLoadBalancerID holds the OCID of the load balancer backing the ingress. It is set by the controller
+	// after the load balancer is created. Setting it manually makes the controller adopt an existing load balancer of its
+	// compartment, see AnnotationAdoptLoadBalancer.
+	AnnotationLoadBalancerID = "oci-load-balancer-id"
+
+	// AnnotationAdoptLoadBalancer is an annotation for letting the controller adopt the load balancer set in AnnotationLoadBalancerID
+	// while it carries none of the tags of the controller, eg: one created by hand. Value is "true" or "false". Once adopted, the
+	// load balancer is managed, and deleted, along with the ingress.
+	AnnotationAdoptLoadBalancer = "oci-adopt-load-balancer"
+
+	// AnnotationLastSyncedGeneration is set by the controller to the ingress generation last applied to the load balancer successfully
+	AnnotationLastSyncedGeneration = "oci-last-synced-generation"
+
+	// AnnotationSyncStatus is set by the controller to the result of the last sync. Either "Synced", "Planned" or "Failed"
+	AnnotationSyncStatus = "oci-sync-status"
+
+	// AnnotationSyncGeneration is set by the controller to the ingress generation the sync status refers to
+	AnnotationSyncGeneration = "oci-sync-generation"
+
+	// AnnotationSyncError is set by the controller to the error of the last sync if it has failed
+	AnnotationSyncError = "oci-sync-error"
+
+	// AnnotationPlanOnly is an annotation for computing and publishing the changes required on the load balancer,
+	// without applying them. Value is "true" or "false".
+	AnnotationPlanOnly = "oci-plan-only"
+
+	// AnnotationLoadBalancerGroup is an annotation for sharing a single load balancer between all ingresses of the same group.
+	// Value is a DNS label. The namespace of the ingress must allow the group, see AnnotationAllowedLoadBalancerGroups.
+	AnnotationLoadBalancerGroup = "oci-load-balancer-group"
+
+	// AnnotationAllowedLoadBalancerGroups is a Namespace annotation listing (comma separated) the load balancer groups
+	// ingresses of the namespace may join. "*" allows any group. Ingresses of a namespace without it can't join groups.
+	AnnotationAllowedLoadBalancerGroups = "oci-allowed-load-balancer-groups"
+
+	// AnnotationBackendTarget is an annotation for choosing what backend sets point at. "node" (default) adds every node
+	// on the NodePort of the service. "pod" adds the ready pods of the service on their target port, as listed by its
+	// EndpointSlices. "pod" requires VCN-native pod networking, where pod IPs are reachable from the load balancer.
+	AnnotationBackendTarget = "oci-backend-target"
+
+	// AnnotationBackendNodeSelector is an annotation for narrowing the nodes backend sets point at to the ones matching
+	// a label selector, on top of the global one. eg: "pool=web,!spot"
+	AnnotationBackendNodeSelector = "oci-backend-node-selector"
+
+	// AnnotationBackendDrainPeriod is an annotation for how long backends removed from a backend set are drained
+	// before they are removed. A duration like "30s". "0s" removes them right away.
+	AnnotationBackendDrainPeriod = "oci-backend-drain-period"
+
+	// AnnotationDrainingBackends is set by the controller to the backends being drained, along with the time draining started
+	AnnotationDrainingBackends = "oci-draining-backends"
+
+	// AnnotationHealthCheckProtocol is a Service annotation for the protocol of health checks of its backend sets. "HTTP" or "TCP".
+	// Setting any of the health check annotations makes backends be checked on their own port, unless the port is set too.
+	AnnotationHealthCheckProtocol = "oci-health-check-protocol"
+
+	// AnnotationHealthCheckPath is a Service annotation for the URL path of HTTP health checks
+	AnnotationHealthCheckPath = "oci-health-check-path"
+
+	// AnnotationHealthCheckPort is a Service annotation for the port health checks connect to, on nodes or pods
+	AnnotationHealthCheckPort = "oci-health-check-port"
+
+	// AnnotationHealthCheckStatusCode is a Service annotation for the status code HTTP health checks expect
+	AnnotationHealthCheckStatusCode = "oci-health-check-status-code"
+
+	// AnnotationHealthCheckResponseBodyRegex is a Service annotation for a regular expression the body of HTTP health check
+	// responses must match
+	AnnotationHealthCheckResponseBodyRegex = "oci-health-check-response-body-regex"
+
+	// AnnotationBackendTLSSecret is a Service annotation naming a TLS secret for connecting to backends over TLS. Both
+	// traffic and health checks of its backend sets use TLS, and peer certificates are not verified.
+	AnnotationBackendTLSSecret = "oci-backend-tls-secret"
+
+	// AnnotationPathHealthChecks is an annotation for health checks of the backends of ingress paths. Value is a JSON object
+	// mapping paths to objects with any of "protocol", "urlPath", "port", "statusCode", "responseBodyRegex" and "backendTLSSecret".
+	// They override the health check annotations of the services.
+	AnnotationPathHealthChecks = "oci-path-health-checks"
+
+	// AnnotationPathBackendWeights is an annotation for splitting traffic of ingress paths between services, eg: for canaries.
+	// Value is a JSON object mapping paths to objects of weights by service name, which must include the service of the path.
+	// Other services are routed to on the same port.
+	AnnotationPathBackendWeights = "oci-path-backend-weights"
+
+	// AnnotationRouteConditions is an annotation for matching requests of ingress paths on headers, query parameters and
+	// cookies too. Value is YAML mapping paths to route conditions, see RouteConditions.
+	AnnotationRouteConditions = "route-conditions"
+
 	// AnnotationForceHTTPSRedirect is an annotation for setting up a load balancer RuleSet for HTTP -> HTTPS 301 redirection on TLS enabled hostnames
 	AnnotationForceHTTPSRedirect = "force-https-redirect"
 )
+
+// ControllerIngressAnnotations lists the ingress annotations maintained by the controller, as opposed to the ones set by users
+var ControllerIngressAnnotations = []string{
+	AnnotationLoadBalancerID,
+	AnnotationLastSyncedGeneration,
+	AnnotationSyncStatus,
+	AnnotationSyncGeneration,
+	AnnotationSyncError,
+	AnnotationDrainingBackends,
+}
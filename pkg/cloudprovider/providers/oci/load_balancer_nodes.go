@@ -0,0 +1,47 @@
+package oci
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	controlPlaneNodeRoleLabel = "node-role.kubernetes.io/control-plane"
+	masterNodeRoleLabel       = "node-role.kubernetes.io/master"
+
+	// toBeDeletedTaint is added by the cluster autoscaler to nodes it is about to remove
+	toBeDeletedTaint = "ToBeDeletedByClusterAutoscaler"
+)
+
+// IsNodeEligibleBackend tells whether the node may be a backend at all.
+// NotReady, control plane and explicitly excluded nodes are not.
+func IsNodeEligibleBackend(node *corev1.Node) bool {
+	if _, excluded := node.Labels[corev1.LabelNodeExcludeBalancers]; excluded {
+		return false
+	}
+	if _, controlPlane := node.Labels[controlPlaneNodeRoleLabel]; controlPlane {
+		return false
+	}
+	if _, master := node.Labels[masterNodeRoleLabel]; master {
+		return false
+	}
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// IsNodeDraining tells whether the node is on its way out: cordoned, or about to be removed by the cluster autoscaler.
+// Backends on such nodes are drained, they keep their connections but get no new ones.
+func IsNodeDraining(node *corev1.Node) bool {
+	if node.Spec.Unschedulable {
+		return true
+	}
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == toBeDeletedTaint {
+			return true
+		}
+	}
+	return false
+}
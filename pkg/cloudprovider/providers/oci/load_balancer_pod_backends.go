@@ -0,0 +1,127 @@
+package oci
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/oracle/oci-go-sdk/v46/common"
+	"github.com/oracle/oci-go-sdk/v46/loadbalancer"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+)
+
+// Values of AnnotationBackendTarget
+const (
+	BackendTargetNode = "node"
+	BackendTargetPod  = "pod"
+)
+
+// GetPodBackendSets is GetBackendSets for VCN-native pod networking. Backends are the ready pods of the service,
+// on their target port, as listed by the EndpointSlices of the service.
+func GetPodBackendSets(logger *zap.SugaredLogger, svc *corev1.Service, endpointSlices []discoveryv1.EndpointSlice, sslCfg *SSLConfig, loadbalancerPolicy string) (map[string]loadbalancer.BackendSetDetails, error) {
+	backendSets := make(map[string]loadbalancer.BackendSetDetails)
+	for _, servicePort := range svc.Spec.Ports {
+		name := GetBackendSetName(svc.Name, string(servicePort.Protocol), int(servicePort.Port))
+		port := int(servicePort.Port)
+		var secretName string
+		if sslCfg != nil && len(sslCfg.BackendSetSSLSecretName) != 0 {
+			secretName = sslCfg.BackendSetSSLSecretName
+		}
+		healthChecker, err := getPodHealthChecker(svc)
+		if err != nil {
+			return nil, err
+		}
+		backendSets[name] = loadbalancer.BackendSetDetails{
+			Policy:           common.String(loadbalancerPolicy),
+			Backends:         getPodBackends(logger, endpointSlices, servicePort),
+			HealthChecker:    healthChecker,
+			SslConfiguration: getSSLConfiguration(sslCfg, secretName, port),
+		}
+	}
+	return backendSets, nil
+}
+
+// getPodBackends lists the ready endpoints serving the service port
+func getPodBackends(logger *zap.SugaredLogger, endpointSlices []discoveryv1.EndpointSlice, servicePort corev1.ServicePort) []loadbalancer.BackendDetails {
+	backends := make([]loadbalancer.BackendDetails, 0)
+	seen := map[string]bool{}
+	for _, slice := range endpointSlices {
+		if slice.AddressType != discoveryv1.AddressTypeIPv4 {
+			continue
+		}
+		targetPort := getEndpointSlicePort(slice, servicePort)
+		if targetPort == 0 {
+			continue
+		}
+		for _, endpoint := range slice.Endpoints {
+			if !IsEndpointReady(endpoint) {
+				continue
+			}
+			for _, address := range endpoint.Addresses {
+				key := fmt.Sprintf("%s:%d", address, targetPort)
+				if seen[key] {
+					// An endpoint may be listed by more than one slice while it moves between them
+					continue
+				}
+				seen[key] = true
+				backends = append(backends, loadbalancer.BackendDetails{
+					IpAddress: common.String(address),
+					Port:      common.Int(int(targetPort)),
+					Weight:    common.Int(1),
+				})
+			}
+		}
+	}
+	if len(backends) == 0 {
+		logger.Warnf("No ready endpoints for port %d (%s) of the service", servicePort.Port, servicePort.Name)
+	}
+	sort.Slice(backends, func(i, j int) bool {
+		if *backends[i].IpAddress != *backends[j].IpAddress {
+			return *backends[i].IpAddress < *backends[j].IpAddress
+		}
+		return *backends[i].Port < *backends[j].Port
+	})
+	return backends
+}
+
+// IsEndpointReady tells whether the endpoint should get traffic. Endpoints not ready, or terminating, should not.
+func IsEndpointReady(endpoint discoveryv1.Endpoint) bool {
+	if endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+		return false
+	}
+	return endpoint.Conditions.Terminating == nil || !*endpoint.Conditions.Terminating
+}
+
+// getEndpointSlicePort finds the target port of the service port in the slice. Ports are matched by name and protocol.
+func getEndpointSlicePort(slice discoveryv1.EndpointSlice, servicePort corev1.ServicePort) int32 {
+	for _, port := range slice.Ports {
+		if port.Port == nil {
+			continue
+		}
+		name, protocol := "", corev1.ProtocolTCP
+		if port.Name != nil {
+			name = *port.Name
+		}
+		if port.Protocol != nil {
+			protocol = *port.Protocol
+		}
+		if name == servicePort.Name && protocol == servicePort.Protocol {
+			return *port.Port
+		}
+	}
+	return 0
+}
+
+// getPodHealthChecker checks pods with a TCP connection to their target port.
+// Node health checks (kube-proxy or the HealthCheckNodePort) don't listen on pod IPs.
+func getPodHealthChecker(svc *corev1.Service) (*loadbalancer.HealthCheckerDetails, error) {
+	healthChecker, err := getHealthChecker(svc)
+	if err != nil {
+		return nil, err
+	}
+	healthChecker.Protocol = common.String("TCP")
+	healthChecker.UrlPath = nil
+	healthChecker.Port = common.Int(0) // 0 checks the port of each backend
+	return healthChecker, nil
+}
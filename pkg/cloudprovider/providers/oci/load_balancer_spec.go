@@ -94,6 +94,9 @@ type LBSpec struct {
 	SecurityListManager     securityListManager
 	NetworkSecurityGroupIds []string
 	Nodes                   []*corev1.Node
+
+	// OnActionApplied is called, if set, after a backend set or listener action is applied with the result of it
+	OnActionApplied func(action Action, err error)
 }
 
 // Certificates builds a map of required SSL certificates.
@@ -192,6 +195,7 @@ func getBackends(logger *zap.SugaredLogger, nodes []*corev1.Node, nodePort int32
 			IpAddress: nodeAddressString,
 			Port:      common.Int(int(nodePort)),
 			Weight:    common.Int(1),
+			Drain:     common.Bool(IsNodeDraining(node)),
 		})
 	}
 	return backends
@@ -410,6 +414,8 @@ func IsInternalLB(ingOrSvc interface{}) (bool, error) {
 		panic("TODO")
 	case *networking.Ingress:
 		obj = o
+	case AnnotatedObject:
+		obj = o
 	default:
 		panic("shouldn't be here!")
 	}
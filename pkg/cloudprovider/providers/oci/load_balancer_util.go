@@ -95,6 +95,36 @@ func (b *BackendSetAction) String() string {
 	return fmt.Sprintf("BackendSetAction:{Name: %s, Type: %v, Ports: %+v}", b.Name(), b.actionType, b.Ports)
 }
 
+// BackendAction denotes the action that should be taken on a single backend of a BackendSet. Only updates of
+// backend flags (eg: drain) are done this way, adding and removing backends updates the whole BackendSet.
+type BackendAction struct {
+	Action
+
+	actionType ActionType
+	name       string
+
+	BackendSetName string
+	Backend        loadbalancer.BackendDetails
+}
+
+func (b *BackendAction) Entity() string {
+	return "Backend"
+}
+
+// Type of the Action.
+func (b *BackendAction) Type() ActionType {
+	return b.actionType
+}
+
+// Name of the action's
+func (b *BackendAction) Name() string {
+	return b.name
+}
+
+func (b *BackendAction) String() string {
+	return fmt.Sprintf("BackendAction:{Name: %s, BackendSet: %s, Type: %v, Drain: %t}", b.Name(), b.BackendSetName, b.actionType, toBool(b.Backend.Drain))
+}
+
 // ListenerAction denotes the action that should be taken on the given Listener.
 type ListenerAction struct {
 	Action
@@ -209,12 +239,20 @@ func getHealthCheckerChanges(actual *loadbalancer.HealthChecker, desired *loadba
 func hasBackendSetChanged(logger *zap.SugaredLogger, actual loadbalancer.BackendSet, desired loadbalancer.BackendSetDetails) bool {
 	logger = logger.With("BackEndSetName", toString(actual.Name))
 	backendSetChanges := getHealthCheckerChanges(actual.HealthChecker, desired.HealthChecker)
-	// Need to update the seclist if service nodeport has changed
+	// Need to update the seclist if service nodeport has changed. Backend sets splitting traffic between services have
+	// backends on more than one port.
 	if len(actual.Backends) > 0 && len(desired.Backends) > 0 {
-		if *actual.Backends[0].Port != *desired.Backends[0].Port {
+		actualPorts, desiredPorts := sets.NewInt(), sets.NewInt()
+		for _, backend := range actual.Backends {
+			actualPorts.Insert(*backend.Port)
+		}
+		for _, backend := range desired.Backends {
+			desiredPorts.Insert(*backend.Port)
+		}
+		if !actualPorts.Equal(desiredPorts) {
 			backendSetChanges = append(backendSetChanges,
 				fmt.Sprintf(changeFmtStr, "BackEndSet:BackendPort",
-					*actual.Backends[0].Port, *desired.Backends[0].Port))
+					actualPorts.List(), desiredPorts.List()))
 		}
 	}
 
@@ -248,7 +286,7 @@ func hasBackendSetChanged(logger *zap.SugaredLogger, actual loadbalancer.Backend
 	}
 
 	if len(backendChanges) != 0 {
-		backendSetChanges = append(backendChanges)
+		backendSetChanges = append(backendSetChanges, backendChanges...)
 	}
 
 	if len(backendSetChanges) != 0 {
@@ -352,16 +390,18 @@ func getBackendSetChanges(logger *zap.SugaredLogger, actual map[string]loadbalan
 			continue
 		}
 
-		if hasBackendSetChanged(logger, actualBackendSet, desiredBackendSet) {
-			oldPorts := portsFromBackendSet(logger, name, &actualBackendSet)
-			backendSetActions = append(backendSetActions, &BackendSetAction{
-				name:       name,
-				BackendSet: desiredBackendSet,
-				Ports:      portsFromBackendSetDetails(logger, name, &desiredBackendSet),
-				OldPorts:   &oldPorts,
-				actionType: Update,
-			})
+		if !hasBackendSetChanged(logger, actualBackendSet, desiredBackendSet) {
+			backendSetActions = append(backendSetActions, getBackendChanges(logger, name, actualBackendSet.Backends, desiredBackendSet.Backends)...)
+			continue
 		}
+		oldPorts := portsFromBackendSet(logger, name, &actualBackendSet)
+		backendSetActions = append(backendSetActions, &BackendSetAction{
+			name:       name,
+			BackendSet: desiredBackendSet,
+			Ports:      portsFromBackendSetDetails(logger, name, &desiredBackendSet),
+			OldPorts:   &oldPorts,
+			actionType: Update,
+		})
 	}
 
 	// Now check if any need to be created.
@@ -380,6 +420,49 @@ func getBackendSetChanges(logger *zap.SugaredLogger, actual map[string]loadbalan
 	return backendSetActions
 }
 
+// getBackendChanges updates backends of an unchanged BackendSet whose drain flag or weight differs
+func getBackendChanges(logger *zap.SugaredLogger, backendSetName string, actual []loadbalancer.Backend, desired []loadbalancer.BackendDetails) []Action {
+	nameFormat := "%s:%d"
+	actualBackends := map[string]loadbalancer.Backend{}
+	for _, backend := range actual {
+		actualBackends[fmt.Sprintf(nameFormat, *backend.IpAddress, *backend.Port)] = backend
+	}
+	var backendActions []Action
+	for _, backend := range desired {
+		name := fmt.Sprintf(nameFormat, *backend.IpAddress, *backend.Port)
+		actualBackend, found := actualBackends[name]
+		if !found {
+			continue
+		}
+		var backendChanges []string
+		if toBool(actualBackend.Drain) != toBool(backend.Drain) {
+			backendChanges = append(backendChanges, fmt.Sprintf(changeFmtStr, "Backend:"+name+":Drain", toBool(actualBackend.Drain), toBool(backend.Drain)))
+		}
+		// Weight defaults to 1
+		if backendWeight(actualBackend.Weight) != backendWeight(backend.Weight) {
+			backendChanges = append(backendChanges, fmt.Sprintf(changeFmtStr, "Backend:"+name+":Weight", backendWeight(actualBackend.Weight), backendWeight(backend.Weight)))
+		}
+		if len(backendChanges) == 0 {
+			continue
+		}
+		logger.With("BackEndSetName", backendSetName).Infof("Backend needs to be updated for the change(s) - %s", strings.Join(backendChanges, ","))
+		backendActions = append(backendActions, &BackendAction{
+			name:           name,
+			BackendSetName: backendSetName,
+			Backend:        backend,
+			actionType:     Update,
+		})
+	}
+	return backendActions
+}
+
+func backendWeight(weight *int) int {
+	if weight == nil {
+		return 1
+	}
+	return *weight
+}
+
 func getSSLConfigurationChanges(actual *loadbalancer.SslConfiguration, desired *loadbalancer.SslConfigurationDetails) []string {
 	var sslConfigurationChanges []string
 	if actual == nil && desired == nil {
@@ -631,7 +714,7 @@ func parseSecretString(secretString string) (string, string) {
 }
 
 func actionIndex(action Action) int {
-	var actionOrder = []string{"BackendSet:create", "BackendSet:update", "Listener:create", "Listener:update", "Listener:delete", "BackendSet:delete"}
+	var actionOrder = []string{"BackendSet:create", "BackendSet:update", "Backend:update", "Listener:create", "Listener:update", "Listener:delete", "BackendSet:delete"}
 	for i := range actionOrder {
 		if actionOrder[i] == action.Entity()+":"+string(action.Type()) {
 			return i
@@ -18,9 +18,11 @@ import (
 	"context"
 
 	"github.com/nom3ad/oci-lb-ingress-controller/pkg/oci/client"
+	"github.com/oracle/oci-go-sdk/v46/common"
 	"github.com/oracle/oci-go-sdk/v46/core"
 	"github.com/oracle/oci-go-sdk/v46/loadbalancer"
 	"github.com/pkg/errors"
+	"go.uber.org/zap"
 	v1 "k8s.io/api/core/v1"
 	sets "k8s.io/apimachinery/pkg/util/sets"
 )
@@ -477,6 +479,25 @@ func (cp *CloudProvider) ensureSSLCertificates(ctx context.Context, lb *loadbala
 // 	return subnets, nil
 // }
 
+// LoadBalancerChanges is the change set UpdateLoadBalancer() applies on a load balancer
+type LoadBalancerChanges struct {
+	ShapeChanged                 bool
+	NetworkSecurityGroupsChanged bool
+	// Actions on backend sets and listeners, in the order they are applied
+	Actions []Action
+}
+
+// GetLoadBalancerChanges computes the changes required to bring lb to spec, without calling any OCI API
+func GetLoadBalancerChanges(ctx context.Context, logger *zap.SugaredLogger, lb *loadbalancer.LoadBalancer, spec *LBSpec) LoadBalancerChanges {
+	backendSetActions := getBackendSetChanges(logger, lb.BackendSets, spec.BackendSets)
+	listenerActions := getListenerChanges(logger, lb.Listeners, spec.Listeners)
+	return LoadBalancerChanges{
+		ShapeChanged:                 hasLoadbalancerShapeChanged(ctx, spec, lb),
+		NetworkSecurityGroupsChanged: hasLoadBalancerNetworkSecurityGroupsChanged(ctx, lb.NetworkSecurityGroupIds, spec.NetworkSecurityGroupIds),
+		Actions:                      sortAndCombineActions(logger, backendSetActions, listenerActions),
+	}
+}
+
 func (cp *CloudProvider) UpdateLoadBalancer(ctx context.Context, lb *loadbalancer.LoadBalancer, spec *LBSpec) error {
 	lbID := *lb.Id
 
@@ -502,13 +523,7 @@ func (cp *CloudProvider) UpdateLoadBalancer(ctx context.Context, lb *loadbalance
 		}
 	}
 
-	actualBackendSets := lb.BackendSets
-	desiredBackendSets := spec.BackendSets
-	backendSetActions := getBackendSetChanges(logger, actualBackendSets, desiredBackendSets)
-
-	actualListeners := lb.Listeners
-	desiredListeners := spec.Listeners
-	listenerActions := getListenerChanges(logger, actualListeners, desiredListeners)
+	changes := GetLoadBalancerChanges(ctx, logger, lb, spec)
 
 	lbSubnets, err := getSubnets(ctx, spec.Subnets, cp.client.Networking())
 	if err != nil {
@@ -519,17 +534,14 @@ func (cp *CloudProvider) UpdateLoadBalancer(ctx context.Context, lb *loadbalance
 		return errors.Wrap(err, "get subnets for nodes")
 	}
 
-	shapeChanged := hasLoadbalancerShapeChanged(ctx, spec, lb)
-
-	if shapeChanged {
+	if changes.ShapeChanged {
 		err = cp.updateLoadbalancerShape(ctx, lb, spec)
 		if err != nil {
 			return err
 		}
 	}
 
-	nsgChanged := hasLoadBalancerNetworkSecurityGroupsChanged(ctx, lb.NetworkSecurityGroupIds, spec.NetworkSecurityGroupIds)
-	if nsgChanged {
+	if changes.NetworkSecurityGroupsChanged {
 		err = cp.updateLoadBalancerNetworkSecurityGroups(ctx, lb, spec)
 		if err != nil {
 			return err
@@ -549,14 +561,24 @@ func (cp *CloudProvider) UpdateLoadBalancer(ctx context.Context, lb *loadbalance
 	// 	}
 	// }
 
-	actions := sortAndCombineActions(logger, backendSetActions, listenerActions)
-	for _, action := range actions {
+	for _, action := range changes.Actions {
 		switch a := action.(type) {
 		case *BackendSetAction:
 			err := cp.updateBackendSet(ctx, lbID, a, lbSubnets, nodeSubnets, spec.SecurityListManager)
+			if spec.OnActionApplied != nil {
+				spec.OnActionApplied(a, err)
+			}
 			if err != nil {
 				return errors.Wrap(err, "updating BackendSet")
 			}
+		case *BackendAction:
+			err := cp.updateBackend(ctx, lbID, a)
+			if spec.OnActionApplied != nil {
+				spec.OnActionApplied(a, err)
+			}
+			if err != nil {
+				return errors.Wrap(err, "updating backend")
+			}
 		case *ListenerAction:
 			backendSetName := *a.Listener.DefaultBackendSetName
 			var ports PortSpec
@@ -571,6 +593,9 @@ func (cp *CloudProvider) UpdateLoadBalancer(ctx context.Context, lb *loadbalance
 			}
 
 			err := cp.updateListener(ctx, lbID, a, ports, lbSubnets, nodeSubnets, spec.SourceCIDRs, spec.SecurityListManager)
+			if spec.OnActionApplied != nil {
+				spec.OnActionApplied(a, err)
+			}
 			if err != nil {
 				return errors.Wrap(err, "updating listener")
 			}
@@ -628,6 +653,31 @@ func (cp *CloudProvider) updateBackendSet(ctx context.Context, lbID string, acti
 	return nil
 }
 
+func (cp *CloudProvider) updateBackend(ctx context.Context, lbID string, action *BackendAction) error {
+	cp.logger.With(
+		"actionType", action.Type(),
+		"backendSetName", action.BackendSetName,
+		"backendName", action.Name(),
+		"drain", toBool(action.Backend.Drain),
+		"weight", backendWeight(action.Backend.Weight),
+		"loadBalancerID", lbID).Info("Applying action on backend")
+
+	// All fields are required
+	backend := action.Backend
+	workRequestID, err := cp.client.LoadBalancer().UpdateBackend(ctx, lbID, action.BackendSetName, action.Name(), loadbalancer.UpdateBackendDetails{
+		Weight:  common.Int(backendWeight(backend.Weight)),
+		Backup:  common.Bool(toBool(backend.Backup)),
+		Drain:   common.Bool(toBool(backend.Drain)),
+		Offline: common.Bool(toBool(backend.Offline)),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = cp.client.LoadBalancer().AwaitWorkRequest(ctx, workRequestID)
+	return err
+}
+
 func (cp *CloudProvider) updateListener(ctx context.Context, lbID string, action *ListenerAction, ports PortSpec, lbSubnets, nodeSubnets []*core.Subnet, sourceCIDRs []string, secListManager securityListManager) error {
 	var workRequestID string
 	var err error
@@ -11,10 +11,20 @@ import (
 // 	return fmt.Sprintf("%s-%d", protocol, port)
 // }
 func GetBackendSetName(serviceName string, protocol string, port int) string {
+	return getBackendSetName(serviceName, serviceName, protocol, port)
+}
+
+// GetNamespacedBackendSetName returns the name of the backend set of a service port, unique across namespaces.
+// Used for load balancers shared by ingresses of several namespaces.
+func GetNamespacedBackendSetName(namespace, serviceName string, protocol string, port int) string {
+	return getBackendSetName(serviceName, namespace+"/"+serviceName, protocol, port)
+}
+
+func getBackendSetName(serviceName, digested string, protocol string, port int) string {
 	// serviceName could be up to  63 chars (dns label limit)
 	portStr := protocol[0:1] + strconv.Itoa(port) // 2 - 6 chars
 	minPaddingLen := 6
 	maxServiceNameLen := 32 - len(portStr) - minPaddingLen - 2 // considering two underscores,
-	name := fmt.Sprintf("%s_%s_%s", utils.SafeSlice(serviceName, 0, maxServiceNameLen), portStr, utils.ByteAlphaNumericDigest([]byte(serviceName), 32))
+	name := fmt.Sprintf("%s_%s_%s", utils.SafeSlice(serviceName, 0, maxServiceNameLen), portStr, utils.ByteAlphaNumericDigest([]byte(digested), 32))
 	return utils.SafeSlice(name, 0, 32)
 }
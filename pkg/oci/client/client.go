@@ -17,6 +17,7 @@
 package client
 
 import (
+	"context"
 	"time"
 
 	"go.uber.org/zap"
@@ -33,6 +34,9 @@ type Interface interface {
 	LoadBalancer() LoadBalancerInterface
 	Networking() NetworkingInterface
 	Compute() ComputeInterface
+
+	// Ping does a cheap authenticated round-trip to OCI. It bypasses the rate limiter, as it is meant for health checks.
+	Ping(ctx context.Context, compartmentID string) error
 }
 
 type client struct {
@@ -88,6 +92,17 @@ func (c *client) Compute() ComputeInterface {
 	return c
 }
 
+func (c *client) Ping(ctx context.Context, compartmentID string) error {
+	limit := int64(1)
+	startTime := time.Now()
+	_, err := c.loadbalancer.ListLoadBalancers(ctx, loadbalancer.ListLoadBalancersRequest{
+		CompartmentId: &compartmentID,
+		Limit:         &limit,
+	})
+	recordRequest(startTime, err, listVerb, loadBalancerResource)
+	return errors.WithStack(err)
+}
+
 // func (c *client) ListLoadBalancers(ctx context.Context, compartmentID string) ([]loadbalancer.LoadBalancer, error) {
 // 	var page *string
 // 	var result []loadbalancer.LoadBalancer
@@ -17,6 +17,7 @@ package client
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/oracle/oci-go-sdk/v46/core"
 	"github.com/pkg/errors"
@@ -41,10 +42,11 @@ func (c *client) GetInstance(ctx context.Context, id string) (*core.Instance, er
 		return nil, RateLimitError(false, "GetInstance")
 	}
 
+	startTime := time.Now()
 	resp, err := c.compute.GetInstance(ctx, core.GetInstanceRequest{
 		InstanceId:      &id,
 		RequestMetadata: c.requestMetadata})
-	// incRequestCounter(err, getVerb, instanceResource)
+	recordRequest(startTime, err, getVerb, instanceResource)
 
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -62,13 +64,14 @@ func (c *client) getInstanceByDisplayName(ctx context.Context, compartmentID, di
 		if !c.rateLimiter.Reader.TryAccept() {
 			return nil, RateLimitError(false, "ListInstances")
 		}
+		startTime := time.Now()
 		resp, err := c.compute.ListInstances(ctx, core.ListInstancesRequest{
 			CompartmentId:   &compartmentID,
 			DisplayName:     &displayName,
 			Page:            page,
 			RequestMetadata: c.requestMetadata,
 		})
-		// incRequestCounter(err, listVerb, instanceResource)
+		recordRequest(startTime, err, listVerb, instanceResource)
 
 		if err != nil {
 			return nil, errors.WithStack(err)
@@ -95,8 +98,9 @@ func (c *client) listVNICAttachments(ctx context.Context, req core.ListVnicAttac
 		return core.ListVnicAttachmentsResponse{}, RateLimitError(false, "ListVnicAttachments")
 	}
 
+	startTime := time.Now()
 	resp, err := c.compute.ListVnicAttachments(ctx, req)
-	// incRequestCounter(err, listVerb, vnicAttachmentResource)
+	recordRequest(startTime, err, listVerb, vnicAttachmentResource)
 
 	if err != nil {
 		return resp, errors.WithStack(err)
@@ -31,6 +31,8 @@ type LoadBalancerInterface interface {
 
 	GetLoadBalancer(ctx context.Context, id string) (*loadbalancer.LoadBalancer, error)
 	GetLoadBalancerByName(ctx context.Context, compartmentID, name string) (*loadbalancer.LoadBalancer, error)
+	ListLoadBalancers(ctx context.Context, compartmentID string) ([]loadbalancer.LoadBalancer, error)
+	UpdateLoadBalancer(ctx context.Context, id string, details loadbalancer.UpdateLoadBalancerDetails) (string, error)
 	DeleteLoadBalancer(ctx context.Context, id string) (string, error)
 
 	GetCertificateByName(ctx context.Context, lbID, name string) (*loadbalancer.Certificate, error)
@@ -40,6 +42,8 @@ type LoadBalancerInterface interface {
 	UpdateBackendSet(ctx context.Context, lbID, name string, details loadbalancer.BackendSetDetails) (string, error)
 	DeleteBackendSet(ctx context.Context, lbID, name string) (string, error)
 
+	UpdateBackend(ctx context.Context, lbID, backendSetName, name string, details loadbalancer.UpdateBackendDetails) (string, error)
+
 	UpdateListener(ctx context.Context, lbID, name string, details loadbalancer.ListenerDetails) (string, error)
 	CreateListener(ctx context.Context, lbID, name string, details loadbalancer.ListenerDetails) (string, error)
 	DeleteListener(ctx context.Context, lbID, name string) (string, error)
@@ -68,11 +72,12 @@ func (c *client) GetLoadBalancer(ctx context.Context, id string) (*loadbalancer.
 		return nil, RateLimitError(false, "GetLoadBalancer")
 	}
 
+	startTime := time.Now()
 	resp, err := c.loadbalancer.GetLoadBalancer(ctx, loadbalancer.GetLoadBalancerRequest{
 		LoadBalancerId:  &id,
 		RequestMetadata: c.requestMetadata,
 	})
-	// incRequestCounter(err, getVerb, loadBalancerResource)
+	recordRequest(startTime, err, getVerb, loadBalancerResource)
 
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -87,13 +92,14 @@ func (c *client) GetLoadBalancerByName(ctx context.Context, compartmentID, name
 		if !c.rateLimiter.Reader.TryAccept() {
 			return nil, RateLimitError(false, "ListLoadBalancers")
 		}
+		startTime := time.Now()
 		resp, err := c.loadbalancer.ListLoadBalancers(ctx, loadbalancer.ListLoadBalancersRequest{
 			CompartmentId:   &compartmentID,
 			DisplayName:     &name,
 			Page:            page,
 			RequestMetadata: c.requestMetadata,
 		})
-		// incRequestCounter(err, listVerb, loadBalancerResource)
+		recordRequest(startTime, err, listVerb, loadBalancerResource)
 
 		if err != nil {
 			return nil, errors.WithStack(err)
@@ -111,16 +117,64 @@ func (c *client) GetLoadBalancerByName(ctx context.Context, compartmentID, name
 	return nil, errors.WithStack(errNotFound)
 }
 
+func (c *client) ListLoadBalancers(ctx context.Context, compartmentID string) ([]loadbalancer.LoadBalancer, error) {
+	var page *string
+	var result []loadbalancer.LoadBalancer
+	for {
+		if !c.rateLimiter.Reader.TryAccept() {
+			return nil, RateLimitError(false, "ListLoadBalancers")
+		}
+		startTime := time.Now()
+		resp, err := c.loadbalancer.ListLoadBalancers(ctx, loadbalancer.ListLoadBalancersRequest{
+			CompartmentId:   &compartmentID,
+			Page:            page,
+			RequestMetadata: c.requestMetadata,
+		})
+		recordRequest(startTime, err, listVerb, loadBalancerResource)
+
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		result = append(result, resp.Items...)
+		if page = resp.OpcNextPage; page == nil {
+			break
+		}
+	}
+
+	return result, nil
+}
+
 func (c *client) CreateLoadBalancer(ctx context.Context, details loadbalancer.CreateLoadBalancerDetails) (string, error) {
 	if !c.rateLimiter.Writer.TryAccept() {
 		return "", RateLimitError(true, "CreateLoadBalancer")
 	}
 
+	startTime := time.Now()
 	resp, err := c.loadbalancer.CreateLoadBalancer(ctx, loadbalancer.CreateLoadBalancerRequest{
 		CreateLoadBalancerDetails: details,
 		RequestMetadata:           c.requestMetadata,
 	})
-	// incRequestCounter(err, createVerb, loadBalancerResource)
+	recordRequest(startTime, err, createVerb, loadBalancerResource)
+
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return *resp.OpcWorkRequestId, nil
+}
+
+func (c *client) UpdateLoadBalancer(ctx context.Context, id string, details loadbalancer.UpdateLoadBalancerDetails) (string, error) {
+	if !c.rateLimiter.Writer.TryAccept() {
+		return "", RateLimitError(true, "UpdateLoadBalancer")
+	}
+
+	startTime := time.Now()
+	resp, err := c.loadbalancer.UpdateLoadBalancer(ctx, loadbalancer.UpdateLoadBalancerRequest{
+		LoadBalancerId:            &id,
+		UpdateLoadBalancerDetails: details,
+		RequestMetadata:           c.requestMetadata,
+	})
+	recordRequest(startTime, err, updateVerb, loadBalancerResource)
 
 	if err != nil {
 		return "", errors.WithStack(err)
@@ -134,11 +188,12 @@ func (c *client) DeleteLoadBalancer(ctx context.Context, id string) (string, err
 		return "", RateLimitError(true, "DeleteLoadBalancer")
 	}
 
+	startTime := time.Now()
 	resp, err := c.loadbalancer.DeleteLoadBalancer(ctx, loadbalancer.DeleteLoadBalancerRequest{
 		LoadBalancerId:  &id,
 		RequestMetadata: c.requestMetadata,
 	})
-	// incRequestCounter(err, deleteVerb, loadBalancerResource)
+	recordRequest(startTime, err, deleteVerb, loadBalancerResource)
 
 	if err != nil {
 		return "", errors.WithStack(err)
@@ -152,11 +207,12 @@ func (c *client) GetCertificateByName(ctx context.Context, lbID, name string) (*
 		return nil, RateLimitError(false, "ListCertificates")
 	}
 
+	startTime := time.Now()
 	resp, err := c.loadbalancer.ListCertificates(ctx, loadbalancer.ListCertificatesRequest{
 		LoadBalancerId:  &lbID,
 		RequestMetadata: c.requestMetadata,
 	})
-	// incRequestCounter(err, listVerb, certificateResource)
+	recordRequest(startTime, err, listVerb, certificateResource)
 
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -175,6 +231,7 @@ func (c *client) CreateCertificate(ctx context.Context, lbID string, cert loadba
 		return "", RateLimitError(true, "CreateCertificate")
 	}
 
+	startTime := time.Now()
 	resp, err := c.loadbalancer.CreateCertificate(ctx, loadbalancer.CreateCertificateRequest{
 		LoadBalancerId: &lbID,
 		CreateCertificateDetails: loadbalancer.CreateCertificateDetails{
@@ -186,7 +243,7 @@ func (c *client) CreateCertificate(ctx context.Context, lbID string, cert loadba
 		},
 		RequestMetadata: c.requestMetadata,
 	})
-	// incRequestCounter(err, createVerb, certificateResource)
+	recordRequest(startTime, err, createVerb, certificateResource)
 
 	if err != nil {
 		return "", errors.WithStack(err)
@@ -200,11 +257,12 @@ func (c *client) GetWorkRequest(ctx context.Context, id string) (*loadbalancer.W
 		return nil, RateLimitError(false, "GetWorkRequest")
 	}
 
+	startTime := time.Now()
 	resp, err := c.loadbalancer.GetWorkRequest(ctx, loadbalancer.GetWorkRequestRequest{
 		WorkRequestId:   &id,
 		RequestMetadata: c.requestMetadata,
 	})
-	// incRequestCounter(err, getVerb, workRequestResource)
+	recordRequest(startTime, err, getVerb, workRequestResource)
 
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -218,6 +276,7 @@ func (c *client) CreateBackendSet(ctx context.Context, lbID, name string, detail
 		return "", RateLimitError(true, "CreateBackendSet")
 	}
 
+	startTime := time.Now()
 	resp, err := c.loadbalancer.CreateBackendSet(ctx, loadbalancer.CreateBackendSetRequest{
 		LoadBalancerId: &lbID,
 		CreateBackendSetDetails: loadbalancer.CreateBackendSetDetails{
@@ -230,7 +289,7 @@ func (c *client) CreateBackendSet(ctx context.Context, lbID, name string, detail
 		},
 		RequestMetadata: c.requestMetadata,
 	})
-	// incRequestCounter(err, createVerb, backendSetResource)
+	recordRequest(startTime, err, createVerb, backendSetResource)
 
 	if err != nil {
 		return "", errors.WithStack(err)
@@ -244,6 +303,7 @@ func (c *client) UpdateBackendSet(ctx context.Context, lbID, name string, detail
 		return "", RateLimitError(true, "UpdateBackendSet")
 	}
 
+	startTime := time.Now()
 	resp, err := c.loadbalancer.UpdateBackendSet(ctx, loadbalancer.UpdateBackendSetRequest{
 		LoadBalancerId: &lbID,
 		BackendSetName: &name,
@@ -256,7 +316,29 @@ func (c *client) UpdateBackendSet(ctx context.Context, lbID, name string, detail
 		},
 		RequestMetadata: c.requestMetadata,
 	})
-	// incRequestCounter(err, updateVerb, backendSetResource)
+	recordRequest(startTime, err, updateVerb, backendSetResource)
+
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return *resp.OpcWorkRequestId, nil
+}
+
+func (c *client) UpdateBackend(ctx context.Context, lbID, backendSetName, name string, details loadbalancer.UpdateBackendDetails) (string, error) {
+	if !c.rateLimiter.Writer.TryAccept() {
+		return "", RateLimitError(true, "UpdateBackend")
+	}
+
+	startTime := time.Now()
+	resp, err := c.loadbalancer.UpdateBackend(ctx, loadbalancer.UpdateBackendRequest{
+		LoadBalancerId:       &lbID,
+		BackendSetName:       &backendSetName,
+		BackendName:          &name,
+		UpdateBackendDetails: details,
+		RequestMetadata:      c.requestMetadata,
+	})
+	recordRequest(startTime, err, updateVerb, backendResource)
 
 	if err != nil {
 		return "", errors.WithStack(err)
@@ -270,12 +352,13 @@ func (c *client) DeleteBackendSet(ctx context.Context, lbID, name string) (strin
 		return "", RateLimitError(true, "DeleteBackendSet")
 	}
 
+	startTime := time.Now()
 	resp, err := c.loadbalancer.DeleteBackendSet(ctx, loadbalancer.DeleteBackendSetRequest{
 		LoadBalancerId:  &lbID,
 		BackendSetName:  &name,
 		RequestMetadata: c.requestMetadata,
 	})
-	// incRequestCounter(err, deleteVerb, backendSetResource)
+	recordRequest(startTime, err, deleteVerb, backendSetResource)
 
 	if err != nil {
 		return "", errors.WithStack(err)
@@ -289,6 +372,7 @@ func (c *client) CreateListener(ctx context.Context, lbID, name string, details
 		return "", RateLimitError(true, "CreateListener")
 	}
 
+	startTime := time.Now()
 	resp, err := c.loadbalancer.CreateListener(ctx, loadbalancer.CreateListenerRequest{
 		LoadBalancerId: &lbID,
 		CreateListenerDetails: loadbalancer.CreateListenerDetails{
@@ -304,7 +388,7 @@ func (c *client) CreateListener(ctx context.Context, lbID, name string, details
 		},
 		RequestMetadata: c.requestMetadata,
 	})
-	// incRequestCounter(err, createVerb, listenerResource)
+	recordRequest(startTime, err, createVerb, listenerResource)
 
 	if err != nil {
 		return "", errors.WithStack(err)
@@ -318,6 +402,7 @@ func (c *client) UpdateListener(ctx context.Context, lbID, name string, details
 		return "", RateLimitError(true, "UpdateListener")
 	}
 
+	startTime := time.Now()
 	resp, err := c.loadbalancer.UpdateListener(ctx, loadbalancer.UpdateListenerRequest{
 		LoadBalancerId: &lbID,
 		ListenerName:   &name,
@@ -334,7 +419,7 @@ func (c *client) UpdateListener(ctx context.Context, lbID, name string, details
 		},
 		RequestMetadata: c.requestMetadata,
 	})
-	// incRequestCounter(err, updateVerb, listenerResource)
+	recordRequest(startTime, err, updateVerb, listenerResource)
 
 	if err != nil {
 		return "", errors.WithStack(err)
@@ -345,6 +430,8 @@ func (c *client) UpdateListener(ctx context.Context, lbID, name string, details
 
 func (c *client) AwaitWorkRequest(ctx context.Context, id string) (*loadbalancer.WorkRequest, error) {
 	var wr *loadbalancer.WorkRequest
+	operation := "unknown"
+	startTime := time.Now()
 	err := wait.PollUntil(workRequestPollInterval, func() (done bool, err error) {
 		twr, err := c.GetWorkRequest(ctx, id)
 		if err != nil {
@@ -353,6 +440,9 @@ func (c *client) AwaitWorkRequest(ctx context.Context, id string) (*loadbalancer
 			}
 			return true, errors.WithStack(err)
 		}
+		if twr.Type != nil {
+			operation = *twr.Type
+		}
 		switch twr.LifecycleState {
 		case loadbalancer.WorkRequestLifecycleStateSucceeded:
 			wr = twr
@@ -362,6 +452,11 @@ func (c *client) AwaitWorkRequest(ctx context.Context, id string) (*loadbalancer
 		}
 		return false, nil
 	}, ctx.Done())
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	workRequestWaitDuration.WithLabelValues(operation, result).Observe(time.Since(startTime).Seconds())
 	return wr, err
 }
 
@@ -370,12 +465,13 @@ func (c *client) DeleteListener(ctx context.Context, lbID, name string) (string,
 		return "", RateLimitError(true, "DeleteListener")
 	}
 
+	startTime := time.Now()
 	resp, err := c.loadbalancer.DeleteListener(ctx, loadbalancer.DeleteListenerRequest{
 		LoadBalancerId:  &lbID,
 		ListenerName:    &name,
 		RequestMetadata: c.requestMetadata,
 	})
-	// incRequestCounter(err, deleteVerb, listenerResource)
+	recordRequest(startTime, err, deleteVerb, listenerResource)
 
 	if err != nil {
 		return "", errors.WithStack(err)
@@ -389,11 +485,12 @@ func (c *client) UpdateLoadBalancerShape(ctx context.Context, lbID string, lbSha
 		return "", RateLimitError(true, "UpdateListener")
 	}
 
+	startTime := time.Now()
 	resp, err := c.loadbalancer.UpdateLoadBalancerShape(ctx, loadbalancer.UpdateLoadBalancerShapeRequest{
 		LoadBalancerId:                 &lbID,
 		UpdateLoadBalancerShapeDetails: lbShapeDetails,
 	})
-	// incRequestCounter(err, updateVerb, shapeResource)
+	recordRequest(startTime, err, updateVerb, shapeResource)
 
 	if err != nil {
 		return "", errors.WithStack(err)
@@ -407,11 +504,12 @@ func (c *client) UpdateNetworkSecurityGroups(ctx context.Context, lbID string, l
 		return "", RateLimitError(true, "UpdateNetworkSecurityGroups")
 	}
 
+	startTime := time.Now()
 	resp, err := c.loadbalancer.UpdateNetworkSecurityGroups(ctx, loadbalancer.UpdateNetworkSecurityGroupsRequest{
 		LoadBalancerId:                     &lbID,
 		UpdateNetworkSecurityGroupsDetails: lbNetworkSecurityGroupDetails,
 	})
-	// incRequestCounter(err, updateVerb, nsgResource)
+	recordRequest(startTime, err, updateVerb, nsgResource)
 
 	if err != nil {
 		return "", errors.WithStack(err)
@@ -16,6 +16,7 @@ package client
 
 import (
 	"context"
+	"time"
 
 	"github.com/oracle/oci-go-sdk/v46/loadbalancer"
 	"github.com/pkg/errors"
@@ -27,12 +28,13 @@ func (c *client) CreateRoutingPolicy(ctx context.Context, lbID string, details l
 		return "", RateLimitError(true, "CreateRoutingPolicy")
 	}
 
+	startTime := time.Now()
 	resp, err := c.loadbalancer.CreateRoutingPolicy(ctx, loadbalancer.CreateRoutingPolicyRequest{
 		LoadBalancerId:             &lbID,
 		CreateRoutingPolicyDetails: details,
 		RequestMetadata:            c.requestMetadata,
 	})
-	// incRequestCounter(err, createVerb, loadBalancerRoutingPolicyResource)
+	recordRequest(startTime, err, createVerb, loadBalancerRoutingPolicyResource)
 
 	if err != nil {
 		return "", errors.WithStack(err)
@@ -47,13 +49,14 @@ func (c *client) UpdateRoutingPolicy(ctx context.Context, lbID string, name stri
 		return "", RateLimitError(true, "CreateRoutingPolicy")
 	}
 
+	startTime := time.Now()
 	resp, err := c.loadbalancer.UpdateRoutingPolicy(ctx, loadbalancer.UpdateRoutingPolicyRequest{
 		LoadBalancerId:             &lbID,
 		RoutingPolicyName:          &name,
 		UpdateRoutingPolicyDetails: details,
 		RequestMetadata:            c.requestMetadata,
 	})
-	// incRequestCounter(err, updateVerb, loadBalancerRoutingPolicyResource)
+	recordRequest(startTime, err, updateVerb, loadBalancerRoutingPolicyResource)
 
 	if err != nil {
 		return "", errors.WithStack(err)
@@ -68,12 +71,13 @@ func (c *client) DeleteRoutingPolicy(ctx context.Context, lbID string, name stri
 		return "", RateLimitError(true, "DeleteRoutingPolicy")
 	}
 
+	startTime := time.Now()
 	resp, err := c.loadbalancer.DeleteRoutingPolicy(ctx, loadbalancer.DeleteRoutingPolicyRequest{
 		LoadBalancerId:    &lbID,
 		RoutingPolicyName: &name,
 		RequestMetadata:   c.requestMetadata,
 	})
-	// incRequestCounter(err, deleteVerb, loadBalancerRoutingPolicyResource)
+	recordRequest(startTime, err, deleteVerb, loadBalancerRoutingPolicyResource)
 
 	if err != nil {
 		return "", errors.WithStack(err)
@@ -88,12 +92,13 @@ func (c *client) CreateRuleSet(ctx context.Context, lbID string, details loadbal
 		return "", RateLimitError(true, "CreateRuleSet")
 	}
 
+	startTime := time.Now()
 	resp, err := c.loadbalancer.CreateRuleSet(ctx, loadbalancer.CreateRuleSetRequest{
 		LoadBalancerId:       &lbID,
 		CreateRuleSetDetails: details,
 		RequestMetadata:      c.requestMetadata,
 	})
-	// incRequestCounter(err, createVerb, loadBalancerRuleSetResource)
+	recordRequest(startTime, err, createVerb, loadBalancerRuleSetResource)
 
 	if err != nil {
 		return "", errors.WithStack(err)
@@ -108,13 +113,14 @@ func (c *client) UpdateRuleSet(ctx context.Context, lbID string, name string, de
 		return "", RateLimitError(true, "CreateRuleSet")
 	}
 
+	startTime := time.Now()
 	resp, err := c.loadbalancer.UpdateRuleSet(ctx, loadbalancer.UpdateRuleSetRequest{
 		LoadBalancerId:       &lbID,
 		RuleSetName:          &name,
 		UpdateRuleSetDetails: details,
 		RequestMetadata:      c.requestMetadata,
 	})
-	// incRequestCounter(err, updateVerb, loadBalancerRuleSetResource)
+	recordRequest(startTime, err, updateVerb, loadBalancerRuleSetResource)
 
 	if err != nil {
 		return "", errors.WithStack(err)
@@ -129,12 +135,13 @@ func (c *client) DeleteRuleSet(ctx context.Context, lbID string, name string) (s
 		return "", RateLimitError(true, "DeleteRuleSet")
 	}
 
+	startTime := time.Now()
 	resp, err := c.loadbalancer.DeleteRuleSet(ctx, loadbalancer.DeleteRuleSetRequest{
 		LoadBalancerId:  &lbID,
 		RuleSetName:     &name,
 		RequestMetadata: c.requestMetadata,
 	})
-	// incRequestCounter(err, deleteVerb, loadBalancerRuleSetResource)
+	recordRequest(startTime, err, deleteVerb, loadBalancerRuleSetResource)
 
 	if err != nil {
 		return "", errors.WithStack(err)
@@ -149,12 +156,13 @@ func (c *client) CreateHostname(ctx context.Context, lbID string, details loadba
 		return "", RateLimitError(true, "CreateHostname")
 	}
 
+	startTime := time.Now()
 	resp, err := c.loadbalancer.CreateHostname(ctx, loadbalancer.CreateHostnameRequest{
 		LoadBalancerId:        &lbID,
 		CreateHostnameDetails: loadbalancer.CreateHostnameDetails(details),
 		RequestMetadata:       c.requestMetadata,
 	})
-	// incRequestCounter(err, createVerb, loadBalancerHostnameResource)
+	recordRequest(startTime, err, createVerb, loadBalancerHostnameResource)
 
 	if err != nil {
 		return "", errors.WithStack(err)
@@ -169,12 +177,13 @@ func (c *client) DeleteHostname(ctx context.Context, lbID string, name string) (
 		return "", RateLimitError(true, "DeleteHostname")
 	}
 
+	startTime := time.Now()
 	resp, err := c.loadbalancer.DeleteHostname(ctx, loadbalancer.DeleteHostnameRequest{
 		LoadBalancerId:  &lbID,
 		Name:            &name,
 		RequestMetadata: c.requestMetadata,
 	})
-	// incRequestCounter(err, deleteVerb, loadBalancerHostnameResource)
+	recordRequest(startTime, err, deleteVerb, loadBalancerHostnameResource)
 
 	if err != nil {
 		return "", errors.WithStack(err)
@@ -189,12 +198,13 @@ func (c *client) DeleteCertificate(ctx context.Context, lbID string, name string
 		return "", RateLimitError(true, "DeleteCertificate")
 	}
 
+	startTime := time.Now()
 	resp, err := c.loadbalancer.DeleteCertificate(ctx, loadbalancer.DeleteCertificateRequest{
 		LoadBalancerId:  &lbID,
 		CertificateName: &name,
 		RequestMetadata: c.requestMetadata,
 	})
-	// incRequestCounter(err, deleteVerb, certificateResource)
+	recordRequest(startTime, err, deleteVerb, certificateResource)
 
 	if err != nil {
 		return "", errors.WithStack(err)
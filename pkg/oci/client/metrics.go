@@ -0,0 +1,88 @@
+package client
+
+import (
+	"time"
+
+	"github.com/oracle/oci-go-sdk/v46/common"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricsNamespace = "oci"
+
+	getVerb    = "get"
+	listVerb   = "list"
+	createVerb = "create"
+	updateVerb = "update"
+	deleteVerb = "delete"
+
+	loadBalancerResource              = "load_balancer"
+	backendSetResource                = "backend_set"
+	backendResource                   = "backend"
+	listenerResource                  = "listener"
+	certificateResource               = "certificate"
+	workRequestResource               = "work_request"
+	shapeResource                     = "shape"
+	nsgResource                       = "network_security_group"
+	loadBalancerRoutingPolicyResource = "routing_policy"
+	loadBalancerRuleSetResource       = "rule_set"
+	loadBalancerHostnameResource      = "hostname"
+	instanceResource                  = "instance"
+	vnicAttachmentResource            = "vnic_attachment"
+	vnicResource                      = "vnic"
+	subnetResource                    = "subnet"
+	vcnResource                       = "vcn"
+	securityListResource              = "security_list"
+	privateIPResource                 = "private_ip"
+	publicReservedIPResource          = "public_reserved_ip"
+)
+
+var (
+	requestCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "requests_total",
+		Help:      "Number of OCI API requests, partitioned by verb, resource and result.",
+	}, []string{"verb", "resource", "result"})
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "request_duration_seconds",
+		Help:      "Latency of OCI API requests (including SDK retries), partitioned by verb and resource.",
+		Buckets:   prometheus.ExponentialBuckets(0.05, 2, 10),
+	}, []string{"verb", "resource"})
+
+	rateLimitRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "rate_limit_rejections_total",
+		Help:      "Number of OCI API requests rejected by the client side rate limiter, partitioned by type (read/write) and operation.",
+	}, []string{"type", "operation"})
+
+	workRequestWaitDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "work_request_wait_duration_seconds",
+		Help:      "Time spent waiting for load balancer work requests to complete, partitioned by operation and result.",
+		Buckets:   prometheus.ExponentialBuckets(5, 2, 8),
+	}, []string{"operation", "result"})
+)
+
+// Collectors returns the prometheus collectors of the OCI client, to be registered by the caller
+func Collectors() []prometheus.Collector {
+	return []prometheus.Collector{requestCounter, requestDuration, rateLimitRejections, workRequestWaitDuration}
+}
+
+// requestResult maps an OCI API error to a low cardinality label value
+func requestResult(err error) string {
+	if err == nil {
+		return "success"
+	}
+	if serviceErr, ok := common.IsServiceError(errors.Cause(err)); ok {
+		return serviceErr.GetCode()
+	}
+	return "error"
+}
+
+func recordRequest(startTime time.Time, err error, verb, resource string) {
+	requestCounter.WithLabelValues(verb, resource, requestResult(err)).Inc()
+	requestDuration.WithLabelValues(verb, resource).Observe(time.Since(startTime).Seconds())
+}
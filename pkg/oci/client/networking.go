@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"time"
 
 	"github.com/oracle/oci-go-sdk/v46/core"
 	"github.com/pkg/errors"
@@ -46,11 +47,12 @@ func (c *client) GetVNIC(ctx context.Context, id string) (*core.Vnic, error) {
 		return nil, RateLimitError(false, "GetVNIC")
 	}
 
+	startTime := time.Now()
 	resp, err := c.network.GetVnic(ctx, core.GetVnicRequest{
 		VnicId:          &id,
 		RequestMetadata: c.requestMetadata,
 	})
-	// incRequestCounter(err, getVerb, vnicResource)
+	recordRequest(startTime, err, getVerb, vnicResource)
 
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -72,11 +74,12 @@ func (c *client) GetSubnet(ctx context.Context, id string) (*core.Subnet, error)
 		return nil, RateLimitError(false, "GetSubnet")
 	}
 
+	startTime := time.Now()
 	resp, err := c.network.GetSubnet(ctx, core.GetSubnetRequest{
 		SubnetId:        &id,
 		RequestMetadata: c.requestMetadata,
 	})
-	// incRequestCounter(err, getVerb, subnetResource)
+	recordRequest(startTime, err, getVerb, subnetResource)
 
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -92,12 +95,13 @@ func (c *client) ListSubnets(ctx context.Context, compartmentId string, vcnId st
 		return nil, RateLimitError(false, "GetSubnet")
 	}
 
+	startTime := time.Now()
 	resp, err := c.network.ListSubnets(ctx, core.ListSubnetsRequest{
 		CompartmentId:   &compartmentId,
 		VcnId:           &vcnId,
 		RequestMetadata: c.requestMetadata,
 	})
-	// incRequestCounter(err, getVerb, subnetResource)
+	recordRequest(startTime, err, getVerb, subnetResource)
 
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -141,11 +145,12 @@ func (c *client) GetVcn(ctx context.Context, id string) (*core.Vcn, error) {
 	if !c.rateLimiter.Reader.TryAccept() {
 		return nil, RateLimitError(false, "GetVcn")
 	}
+	startTime := time.Now()
 	resp, err := c.network.GetVcn(ctx, core.GetVcnRequest{
 		VcnId:           &id,
 		RequestMetadata: c.requestMetadata,
 	})
-	// incRequestCounter(err, getVerb, vcnResource)
+	recordRequest(startTime, err, getVerb, vcnResource)
 
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -160,11 +165,12 @@ func (c *client) GetSecurityList(ctx context.Context, id string) (core.GetSecuri
 		return core.GetSecurityListResponse{}, RateLimitError(false, "GetSecurityList")
 	}
 
+	startTime := time.Now()
 	resp, err := c.network.GetSecurityList(ctx, core.GetSecurityListRequest{
 		SecurityListId:  &id,
 		RequestMetadata: c.requestMetadata,
 	})
-	// incRequestCounter(err, getVerb, securityListResource)
+	recordRequest(startTime, err, getVerb, securityListResource)
 
 	return resp, errors.WithStack(err)
 }
@@ -174,6 +180,7 @@ func (c *client) UpdateSecurityList(ctx context.Context, id string, etag string,
 		return core.UpdateSecurityListResponse{}, RateLimitError(true, "UpdateSecurityList")
 	}
 
+	startTime := time.Now()
 	resp, err := c.network.UpdateSecurityList(ctx, core.UpdateSecurityListRequest{
 		SecurityListId: &id,
 		IfMatch:        &etag,
@@ -183,7 +190,7 @@ func (c *client) UpdateSecurityList(ctx context.Context, id string, etag string,
 		},
 		RequestMetadata: c.requestMetadata,
 	})
-	// incRequestCounter(err, updateVerb, securityListResource)
+	recordRequest(startTime, err, updateVerb, securityListResource)
 	return resp, errors.WithStack(err)
 }
 
@@ -196,11 +203,12 @@ func (c *client) GetPrivateIP(ctx context.Context, id string) (*core.PrivateIp,
 		return nil, RateLimitError(false, "GetPrivateIp")
 	}
 
+	startTime := time.Now()
 	resp, err := c.network.GetPrivateIp(ctx, core.GetPrivateIpRequest{
 		PrivateIpId:     &id,
 		RequestMetadata: c.requestMetadata,
 	})
-	// incRequestCounter(err, getVerb, privateIPResource)
+	recordRequest(startTime, err, getVerb, privateIPResource)
 
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -213,13 +221,14 @@ func (c *client) GetPublicIpByIpAddress(ctx context.Context, ip string) (*core.P
 	if !c.rateLimiter.Reader.TryAccept() {
 		return nil, RateLimitError(false, "GetPublicIpByIpAddress")
 	}
+	startTime := time.Now()
 	resp, err := c.network.GetPublicIpByIpAddress(ctx, core.GetPublicIpByIpAddressRequest{
 		GetPublicIpByIpAddressDetails: core.GetPublicIpByIpAddressDetails{
 			IpAddress: &ip,
 		},
 		RequestMetadata: c.requestMetadata,
 	})
-	// incRequestCounter(err, getVerb, publicReservedIPResource)
+	recordRequest(startTime, err, getVerb, publicReservedIPResource)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
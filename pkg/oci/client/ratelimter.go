@@ -1,6 +1,9 @@
 package client
 
 import (
+	"sync"
+	"time"
+
 	providercfg "github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci/config"
 	"go.uber.org/zap"
 	"k8s.io/client-go/util/flowcontrol"
@@ -48,12 +51,12 @@ func NewRateLimiter(logger *zap.SugaredLogger, config *providercfg.RateLimiterCo
 	}
 
 	rateLimiter := RateLimiter{
-		Reader: flowcontrol.NewTokenBucketRateLimiter(
+		Reader: &saturationTrackingRateLimiter{RateLimiter: flowcontrol.NewTokenBucketRateLimiter(
 			config.RateLimitQPSRead,
-			config.RateLimitBucketRead),
-		Writer: flowcontrol.NewTokenBucketRateLimiter(
+			config.RateLimitBucketRead)},
+		Writer: &saturationTrackingRateLimiter{RateLimiter: flowcontrol.NewTokenBucketRateLimiter(
 			config.RateLimitQPSWrite,
-			config.RateLimitBucketWrite),
+			config.RateLimitBucketWrite)},
 	}
 
 	logger.Infof("OCI using read rate limit configuration: QPS=%g, bucket=%d",
@@ -66,3 +69,54 @@ func NewRateLimiter(logger *zap.SugaredLogger, config *providercfg.RateLimiterCo
 
 	return rateLimiter
 }
+
+// SaturatedFor returns the longest duration, reader or writer has been rejecting every request for.
+// Zero if the last request of both were accepted, or if neither rejected one within saturationWindow.
+func (rl RateLimiter) SaturatedFor() time.Duration {
+	var d time.Duration
+	for _, l := range []flowcontrol.RateLimiter{rl.Reader, rl.Writer} {
+		if tl, ok := l.(*saturationTrackingRateLimiter); ok {
+			if s := tl.saturatedFor(); s > d {
+				d = s
+			}
+		}
+	}
+	return d
+}
+
+// saturationWindow is how long after its last rejected request a rate limiter is still taken as saturated.
+// Past that, eg: once the controller went idle, its token bucket has long refilled.
+const saturationWindow = 10 * time.Second
+
+// saturationTrackingRateLimiter remembers since when TryAccept() has been failing
+type saturationTrackingRateLimiter struct {
+	flowcontrol.RateLimiter
+	mu            sync.Mutex
+	rejectedSince time.Time
+	lastRejected  time.Time
+}
+
+func (l *saturationTrackingRateLimiter) TryAccept() bool {
+	accepted := l.RateLimiter.TryAccept()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if accepted {
+		l.rejectedSince = time.Time{}
+		return true
+	}
+	now := time.Now()
+	if l.rejectedSince.IsZero() || now.Sub(l.lastRejected) > saturationWindow {
+		l.rejectedSince = now
+	}
+	l.lastRejected = now
+	return false
+}
+
+func (l *saturationTrackingRateLimiter) saturatedFor() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rejectedSince.IsZero() || time.Since(l.lastRejected) > saturationWindow {
+		return 0
+	}
+	return time.Since(l.rejectedSince)
+}
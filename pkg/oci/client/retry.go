@@ -14,6 +14,7 @@ func RateLimitError(isWrite bool, opName string) error {
 	if isWrite {
 		opType = "write"
 	}
+	rateLimitRejections.WithLabelValues(opType, opName).Inc()
 	return errors.Errorf("rate limited(%s) for operation: %s", opType, opName)
 }
 
@@ -0,0 +1,3 @@
+// Package v1alpha1 contains the custom resources of the controller.
+// +groupName=oci-lb-ingress.nom3ad.github.io
+package v1alpha1
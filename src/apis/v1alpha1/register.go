@@ -0,0 +1,21 @@
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+var (
+	// GroupVersion is the group and version of the custom resources
+	GroupVersion = schema.GroupVersion{Group: "oci-lb-ingress.nom3ad.github.io", Version: "v1alpha1"}
+
+	// SchemeBuilder registers the custom resources into a scheme
+	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
+
+	// AddToScheme adds the custom resources to a scheme
+	AddToScheme = SchemeBuilder.AddToScheme
+)
+
+func init() {
+	SchemeBuilder.Register(&IngressClassParameters{}, &IngressClassParametersList{})
+}
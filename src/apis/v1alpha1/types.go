@@ -0,0 +1,43 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IngressClassParametersKind is the kind referenced by spec.parameters of an IngressClass
+const IngressClassParametersKind = "IngressClassParameters"
+
+// IngressClassParametersSpec holds the defaults for load balancers of ingresses having the IngressClass.
+// Unset fields fall back to the controller flags and config. Ingress annotations always take precedence.
+type IngressClassParametersSpec struct {
+	// CompartmentID is the compartment load balancers are created in
+	CompartmentID string `json:"compartmentId,omitempty"`
+	// SubnetIDs are the subnets of load balancers. At most 2.
+	SubnetIDs []string `json:"subnetIds,omitempty"`
+	// Shape of load balancers. eg: "flexible", "100Mbps"
+	Shape string `json:"shape,omitempty"`
+	// FlexMinMbps is the minimum bandwidth if the shape is flexible
+	FlexMinMbps *int `json:"flexMinMbps,omitempty"`
+	// FlexMaxMbps is the maximum bandwidth if the shape is flexible
+	FlexMaxMbps *int `json:"flexMaxMbps,omitempty"`
+	// ForceHTTPSRedirect sets up HTTP -> HTTPS redirection for TLS enabled hosts
+	ForceHTTPSRedirect *bool `json:"forceHTTPSRedirect,omitempty"`
+	// NetworkSecurityGroupIDs are attached to load balancers
+	NetworkSecurityGroupIDs []string `json:"networkSecurityGroupIds,omitempty"`
+}
+
+// IngressClassParameters is a cluster scoped resource referenced by IngressClasses of this controller
+type IngressClassParameters struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec IngressClassParametersSpec `json:"spec,omitempty"`
+}
+
+// IngressClassParametersList is a list of IngressClassParameters
+type IngressClassParametersList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []IngressClassParameters `json:"items"`
+}
@@ -0,0 +1,91 @@
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto copies the receiver into out
+func (in *IngressClassParametersSpec) DeepCopyInto(out *IngressClassParametersSpec) {
+	*out = *in
+	if in.SubnetIDs != nil {
+		out.SubnetIDs = make([]string, len(in.SubnetIDs))
+		copy(out.SubnetIDs, in.SubnetIDs)
+	}
+	if in.FlexMinMbps != nil {
+		v := *in.FlexMinMbps
+		out.FlexMinMbps = &v
+	}
+	if in.FlexMaxMbps != nil {
+		v := *in.FlexMaxMbps
+		out.FlexMaxMbps = &v
+	}
+	if in.ForceHTTPSRedirect != nil {
+		v := *in.ForceHTTPSRedirect
+		out.ForceHTTPSRedirect = &v
+	}
+	if in.NetworkSecurityGroupIDs != nil {
+		out.NetworkSecurityGroupIDs = make([]string, len(in.NetworkSecurityGroupIDs))
+		copy(out.NetworkSecurityGroupIDs, in.NetworkSecurityGroupIDs)
+	}
+}
+
+// DeepCopy creates a new IngressClassParametersSpec
+func (in *IngressClassParametersSpec) DeepCopy() *IngressClassParametersSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressClassParametersSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *IngressClassParameters) DeepCopyInto(out *IngressClassParameters) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy creates a new IngressClassParameters
+func (in *IngressClassParameters) DeepCopy() *IngressClassParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressClassParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object
+func (in *IngressClassParameters) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *IngressClassParametersList) DeepCopyInto(out *IngressClassParametersList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		out.Items = make([]IngressClassParameters, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+}
+
+// DeepCopy creates a new IngressClassParametersList
+func (in *IngressClassParametersList) DeepCopy() *IngressClassParametersList {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressClassParametersList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object
+func (in *IngressClassParametersList) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
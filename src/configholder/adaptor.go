@@ -43,3 +43,19 @@ func NewConfigHolder(conf *providercfg.Config) ConfigHolder {
 		_conf: *conf,
 	}
 }
+
+func (c *configHolder) GetLoadBalancerShape() string {
+	return ""
+}
+
+func (c *configHolder) GetFlexShapeMbps() (*int, *int) {
+	return nil, nil
+}
+
+func (c *configHolder) GetForceHTTPSRedirection() *bool {
+	return nil
+}
+
+func (c *configHolder) GetNetworkSecurityGroupIds() []string {
+	return nil
+}
@@ -3,4 +3,10 @@ package configholder
 type ConfigHolder interface {
 	GetCompartmentId() string
 	GetSubnetIds() []string
+
+	// Following return zero values unless set by IngressClassParameters. Defaults from flags apply then.
+	GetLoadBalancerShape() string
+	GetFlexShapeMbps() (min *int, max *int)
+	GetForceHTTPSRedirection() *bool
+	GetNetworkSecurityGroupIds() []string
 }
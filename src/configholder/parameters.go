@@ -0,0 +1,59 @@
+package configholder
+
+import "github.com/nom3ad/oci-lb-ingress-controller/src/apis/v1alpha1"
+
+// parametersOverlay overrides a config with the fields set in IngressClassParameters
+type parametersOverlay struct {
+	ConfigHolder
+	params v1alpha1.IngressClassParametersSpec
+}
+
+// WithIngressClassParameters returns conf overridden by params. conf is returned as is if params is nil.
+func WithIngressClassParameters(conf ConfigHolder, params *v1alpha1.IngressClassParametersSpec) ConfigHolder {
+	if params == nil {
+		return conf
+	}
+	return &parametersOverlay{ConfigHolder: conf, params: *params.DeepCopy()}
+}
+
+func (o *parametersOverlay) GetCompartmentId() string {
+	if o.params.CompartmentID != "" {
+		return o.params.CompartmentID
+	}
+	return o.ConfigHolder.GetCompartmentId()
+}
+
+func (o *parametersOverlay) GetSubnetIds() []string {
+	if len(o.params.SubnetIDs) > 0 {
+		return o.params.SubnetIDs
+	}
+	return o.ConfigHolder.GetSubnetIds()
+}
+
+func (o *parametersOverlay) GetLoadBalancerShape() string {
+	if o.params.Shape != "" {
+		return o.params.Shape
+	}
+	return o.ConfigHolder.GetLoadBalancerShape()
+}
+
+func (o *parametersOverlay) GetFlexShapeMbps() (*int, *int) {
+	if o.params.FlexMinMbps != nil || o.params.FlexMaxMbps != nil {
+		return o.params.FlexMinMbps, o.params.FlexMaxMbps
+	}
+	return o.ConfigHolder.GetFlexShapeMbps()
+}
+
+func (o *parametersOverlay) GetForceHTTPSRedirection() *bool {
+	if o.params.ForceHTTPSRedirect != nil {
+		return o.params.ForceHTTPSRedirect
+	}
+	return o.ConfigHolder.GetForceHTTPSRedirection()
+}
+
+func (o *parametersOverlay) GetNetworkSecurityGroupIds() []string {
+	if len(o.params.NetworkSecurityGroupIDs) > 0 {
+		return o.params.NetworkSecurityGroupIDs
+	}
+	return o.ConfigHolder.GetNetworkSecurityGroupIds()
+}
@@ -1,28 +1,76 @@
 package controller
 
 import (
+	"context"
+	"time"
+
 	"github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci"
 	providercfg "github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci/config"
 	"github.com/nom3ad/oci-lb-ingress-controller/pkg/oci/client"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/apis/v1alpha1"
 	"github.com/nom3ad/oci-lb-ingress-controller/src/configholder"
 	"github.com/nom3ad/oci-lb-ingress-controller/src/controller/handlers"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/health"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/ingress"
 	ingressmanager "github.com/nom3ad/oci-lb-ingress-controller/src/manager"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/metrics"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/webhook"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
 	"sigs.k8s.io/controller-runtime/pkg/source"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 )
 
 // https://pkg.go.dev/sigs.k8s.io/controller-runtime/pkg/builder#example-Builder
 
 var ControllerName = "ingress.beta.kubernetes.io/oci" // must be a domain-prefixed path (such as "acme.io/foo")
 
+// Leader election settings. Only the leader reconciles ingresses, others keep their caches warm to take over quickly.
+var (
+	LeaderElection          = false
+	LeaderElectionID        = "oci-lb-ingress-controller-leader"
+	LeaderElectionNamespace = "" // detected from the service account if running in cluster
+	LeaseDuration           = 15 * time.Second
+	RenewDeadline           = 10 * time.Second
+	RetryPeriod             = 2 * time.Second
+)
+
+// MetricsBindAddress is the address the prometheus metrics endpoint binds to. "0" disables it.
+var MetricsBindAddress = ":8080"
+
+// MaxConcurrentReconciles is the number of ingresses reconciled in parallel. Operations on the same load balancer are always serialized.
+var MaxConcurrentReconciles = 4
+
+// HealthProbeBindAddress is the address /healthz and /readyz endpoints bind to. "0" disables them.
+var HealthProbeBindAddress = ":8081"
+
+// GatewayAPI enables reconciling Gateways of GatewayClasses with the controller name, and their HTTPRoutes
+var GatewayAPI = false
+
+// Validating admission webhook settings. Webhook is served by every replica, regardless of leadership.
+var (
+	WebhookPort    = 0  // 0 disables the webhook server
+	WebhookCertDir = "" // must contain tls.crt and tls.key. Defaults to <tmp>/k8s-webhook-server/serving-certs
+)
+
 func Run(conf *providercfg.Config, logger *zap.Logger) error {
 	cp, err := providercfg.NewConfigurationProvider(conf)
 	if err != nil {
@@ -39,35 +87,116 @@ func Run(conf *providercfg.Config, logger *zap.Logger) error {
 	if err != nil {
 		return errors.Wrap(err, "Unable get k8s config")
 	}
-	controllerMgr, err := manager.New(kubeConfig, manager.Options{})
+	if err := v1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		return errors.Wrap(err, "Couldn't register custom resources")
+	}
+	if err := gatewayv1beta1.AddToScheme(scheme.Scheme); err != nil {
+		return errors.Wrap(err, "Couldn't register gateway API resources")
+	}
+	controllerMgr, err := manager.New(kubeConfig, manager.Options{
+		MetricsBindAddress:            MetricsBindAddress,
+		HealthProbeBindAddress:        HealthProbeBindAddress,
+		LeaderElection:                LeaderElection,
+		LeaderElectionID:              LeaderElectionID,
+		LeaderElectionNamespace:       LeaderElectionNamespace,
+		LeaderElectionResourceLock:    resourcelock.LeasesResourceLock,
+		LeaderElectionReleaseOnCancel: true,
+		LeaseDuration:                 &LeaseDuration,
+		RenewDeadline:                 &RenewDeadline,
+		RetryPeriod:                   &RetryPeriod,
+		Port:                          WebhookPort,
+		CertDir:                       WebhookCertDir,
+	})
 	if err != nil {
 		return errors.Wrap(err, "Unable to set up controller manager")
 	}
+	if ingressmanager.ClusterID == "" {
+		kubeSystem := &corev1.Namespace{}
+		if err := controllerMgr.GetAPIReader().Get(context.Background(), types.NamespacedName{Name: metav1.NamespaceSystem}, kubeSystem); err != nil {
+			return errors.Wrap(err, "Couldn't get kube-system namespace to identify the cluster")
+		}
+		ingressmanager.ClusterID = string(kubeSystem.UID)
+	}
+	logger.Sugar().With("clusterID", ingressmanager.ClusterID).Info("Identified cluster")
 	dummyCp := oci.DummyCp(ociClient, conf, logger.Sugar())
-	ociIngressManager := ingressmanager.New(ociClient, configholder.NewConfigHolder(conf), controllerMgr, dummyCp, logger)
-	reconciler, err := NewReconciler(controllerMgr, ociIngressManager, logger)
+	confHolder := configholder.NewConfigHolder(conf)
+	recorder := controllerMgr.GetEventRecorderFor(ControllerName)
+
+	// Checks don't depend on leadership. Non-leaders are ready to take over as long as they can reach OCI and caches are warm.
+	reconcileTracker := health.NewReconcileTracker()
+	if err := controllerMgr.AddHealthzCheck("ping", healthz.Ping); err != nil {
+		return errors.Wrap(err, "Couldn't add ping health check")
+	}
+	if err := controllerMgr.AddHealthzCheck("reconcile", reconcileTracker.Check); err != nil {
+		return errors.Wrap(err, "Couldn't add reconcile health check")
+	}
+	if err := controllerMgr.AddReadyzCheck("oci", health.OCIConnectivityChecker(ociClient, confHolder.GetCompartmentId())); err != nil {
+		return errors.Wrap(err, "Couldn't add OCI readiness check")
+	}
+	if err := controllerMgr.AddReadyzCheck("informers", health.CacheSyncChecker(controllerMgr.GetCache())); err != nil {
+		return errors.Wrap(err, "Couldn't add informers readiness check")
+	}
+	if err := controllerMgr.AddReadyzCheck("ratelimiter", health.RateLimiterChecker(rateLimiter)); err != nil {
+		return errors.Wrap(err, "Couldn't add rate limiter readiness check")
+	}
+
+	ingress.UseIngressClasses(controllerMgr.GetClient(), ControllerName)
+
+	if WebhookPort > 0 {
+		controllerMgr.GetWebhookServer().Register(webhook.IngressValidationPath, webhook.NewIngressValidator(logger))
+	}
+
+	ociIngressManager := ingressmanager.New(ociClient, confHolder, controllerMgr, recorder, dummyCp, logger)
+	reconciler, err := NewReconciler(controllerMgr, ociIngressManager, recorder, reconcileTracker, logger)
 	if err != nil {
 		return errors.Wrap(err, "Couldn't build reconciler")
 	}
 
-	c, err := controller.New(ControllerName, controllerMgr, controller.Options{Reconciler: reconciler})
+	c, err := controller.New(ControllerName, controllerMgr, controller.Options{Reconciler: reconciler, MaxConcurrentReconciles: MaxConcurrentReconciles})
 	if err != nil {
 		return errors.Wrap(err, "Couldn't build controller")
 	}
 
-	if err := setupEventListeners(c, controllerMgr.GetCache(), logger); err != nil {
+	if err := setupEventListeners(c, controllerMgr.GetCache(), controllerMgr.GetRESTMapper(), logger); err != nil {
 		if err != nil {
 			return errors.Wrap(err, "Couldn't setup event listeners")
 		}
 	}
 
+	if GatewayAPI {
+		if err := setupGatewayControllers(controllerMgr, ociIngressManager, recorder, reconcileTracker, logger); err != nil {
+			return errors.Wrap(err, "Couldn't setup gateway controllers")
+		}
+	}
+
+	if ingressmanager.OrphanGCInterval > 0 {
+		collector := ingressmanager.NewOrphanCollector(ociClient, confHolder, controllerMgr.GetClient(), logger)
+		if err := controllerMgr.Add(collector); err != nil {
+			return errors.Wrap(err, "Couldn't add orphaned loadbalancer collector")
+		}
+	}
+
+	if conf.Metrics != nil {
+		if err := metrics.ValidateMonitoringConfig(*conf.Metrics); err != nil {
+			return err
+		}
+		publisher, err := metrics.NewOCIMonitoringPublisher(cp, "", logger)
+		if err != nil {
+			return errors.Wrap(err, "Couldn't construct OCI Monitoring publisher")
+		}
+		pusher := metrics.NewMonitoringPusher(publisher, *conf.Metrics, confHolder.GetCompartmentId(), logger)
+		if err := controllerMgr.Add(pusher); err != nil {
+			return errors.Wrap(err, "Couldn't add OCI Monitoring metrics pusher")
+		}
+	}
+
 	if err := controllerMgr.Start(signals.SetupSignalHandler()); err != nil {
 		return errors.Wrap(err, "Couldn't start controller listeners")
 	}
 	return nil
 }
 
-func setupEventListeners(c controller.Controller, cache cache.Cache, logger *zap.Logger) error {
+func setupEventListeners(c controller.Controller, cache cache.Cache, restMapper meta.RESTMapper, logger *zap.Logger) error {
 	// Watch Ingress objects for changes (Create, Update, Delete)
 	if err := c.Watch(&source.Kind{Type: &networking.Ingress{}}, handlers.NewIngressEventHandler(cache, logger)); err != nil {
 		return err
@@ -82,5 +211,63 @@ func setupEventListeners(c controller.Controller, cache cache.Cache, logger *zap
 	if err := c.Watch(&source.Kind{Type: &corev1.Secret{}}, handlers.NewSecretEventHandler(cache, logger)); err != nil {
 		return err
 	}
+
+	// Watch Service objects for changes of the backend sets ingresses route to
+	if err := c.Watch(&source.Kind{Type: &corev1.Service{}}, handlers.NewServiceEventHandler(cache, logger)); err != nil {
+		return err
+	}
+
+	// Watch EndpointSlice objects for changes of the pods ingresses with pod backends route to
+	if err := c.Watch(&source.Kind{Type: &discoveryv1.EndpointSlice{}}, handlers.NewEndpointSliceEventHandler(cache, logger)); err != nil {
+		return err
+	}
+
+	// Watch IngressClass objects and the parameters they refer to
+	if err := c.Watch(&source.Kind{Type: &networking.IngressClass{}}, handlers.NewIngressClassEventHandler(cache, logger)); err != nil {
+		return err
+	}
+	if _, err := restMapper.RESTMapping(schema.GroupKind{Group: v1alpha1.GroupVersion.Group, Kind: v1alpha1.IngressClassParametersKind}); err != nil {
+		logger.Sugar().With(zap.Error(err)).Warnf("%s CRD is not installed. Changes to it won't be watched", v1alpha1.IngressClassParametersKind)
+		return nil
+	}
+	if err := c.Watch(&source.Kind{Type: &v1alpha1.IngressClassParameters{}}, handlers.NewIngressClassEventHandler(cache, logger)); err != nil {
+		return err
+	}
 	return nil
 }
+
+// setupGatewayControllers adds the controllers of gateways and gateway classes. Gateway API CRDs must be installed.
+func setupGatewayControllers(controllerMgr manager.Manager, ingressMgr ingressmanager.Manager, recorder record.EventRecorder, tracker *health.ReconcileTracker, logger *zap.Logger) error {
+	for _, kind := range []string{"GatewayClass", "Gateway", "HTTPRoute"} {
+		if _, err := controllerMgr.GetRESTMapper().RESTMapping(schema.GroupKind{Group: gatewayv1beta1.GroupName, Kind: kind}); err != nil {
+			return errors.Wrapf(err, "%s CRD is not installed", kind)
+		}
+	}
+	cache := controllerMgr.GetCache()
+
+	classController, err := controller.New("gatewayclass", controllerMgr, controller.Options{Reconciler: NewGatewayClassReconciler(controllerMgr, logger)})
+	if err != nil {
+		return err
+	}
+	if err := classController.Watch(&source.Kind{Type: &gatewayv1beta1.GatewayClass{}}, &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+
+	gatewayController, err := controller.New("gateway", controllerMgr, controller.Options{
+		Reconciler:              NewGatewayReconciler(controllerMgr, ingressMgr, recorder, tracker, logger),
+		MaxConcurrentReconciles: MaxConcurrentReconciles,
+	})
+	if err != nil {
+		return err
+	}
+	eventHandler := handlers.NewGatewayEventHandler(cache, logger)
+	for _, obj := range []k8sclient.Object{&gatewayv1beta1.Gateway{}, &gatewayv1beta1.HTTPRoute{}, &gatewayv1beta1.GatewayClass{}, &corev1.Node{}, &corev1.Secret{}} {
+		if err := gatewayController.Watch(&source.Kind{Type: obj}, eventHandler); err != nil {
+			return err
+		}
+	}
+	if _, err := controllerMgr.GetRESTMapper().RESTMapping(schema.GroupKind{Group: v1alpha1.GroupVersion.Group, Kind: v1alpha1.IngressClassParametersKind}); err != nil {
+		return nil
+	}
+	return gatewayController.Watch(&source.Kind{Type: &v1alpha1.IngressClassParameters{}}, eventHandler)
+}
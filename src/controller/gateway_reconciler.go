@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/nom3ad/oci-lb-ingress-controller/src/health"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/ingress"
+	ingressmanager "github.com/nom3ad/oci-lb-ingress-controller/src/manager"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// gatewayReconciler reconciles a single gateway
+type gatewayReconciler struct {
+	k8sClient      client.Client
+	cache          cache.Cache
+	ingressManager ingressmanager.Manager
+	recorder       record.EventRecorder
+	tracker        *health.ReconcileTracker
+	logger         *zap.Logger
+}
+
+func NewGatewayReconciler(controllerMgr manager.Manager, ingressMgr ingressmanager.Manager, recorder record.EventRecorder, tracker *health.ReconcileTracker, logger *zap.Logger) reconcile.Reconciler {
+	return &gatewayReconciler{
+		k8sClient:      controllerMgr.GetClient(),
+		cache:          controllerMgr.GetCache(),
+		ingressManager: ingressMgr,
+		recorder:       recorder,
+		tracker:        tracker,
+		logger:         logger,
+	}
+}
+
+func (r *gatewayReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	defer r.tracker.Begin("gateway/" + request.String())()
+	logger := r.logger.Sugar().With("gateway", request.NamespacedName)
+	gw := &gatewayv1beta1.Gateway{}
+	if err := r.cache.Get(ctx, request.NamespacedName, gw); err != nil {
+		// Gateways of ours never go away before their finalizer is removed
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	ours, err := r.isOurs(ctx, gw)
+	if errors.Is(err, errGatewayClassNotFound) && gw.DeletionTimestamp != nil {
+		// Gateway carries our finalizer, so its load balancer is ours to delete
+		ours, err = true, nil
+	}
+	if err != nil {
+		logger.Errorf("Reconcile failed: %s", err)
+		return reconcile.Result{}, err
+	}
+	if gw.DeletionTimestamp != nil || !ours {
+		if !controllerutil.ContainsFinalizer(gw, ingress.GatewayFinalizer) {
+			logger.Debug("Reconcile: nothing to cleanup")
+			return reconcile.Result{}, nil
+		}
+		logger.Infof("DeleteGateway() | Deleting=%t Class=%q", gw.DeletionTimestamp != nil, gw.Spec.GatewayClassName)
+		if err := r.ingressManager.DeleteGateway(gw); errors.Is(err, ingressmanager.ErrPlanOnly) {
+			logger.Info("Reconcile: plan mode, keeping the load balancer and the finalizer")
+			return reconcile.Result{}, nil
+		} else if err != nil {
+			r.recorder.Eventf(gw, corev1.EventTypeWarning, ingressmanager.ReasonSyncFailed, "Failed to delete load balancer: %s", err)
+			logger.Errorf("Reconcile failed: Retryable=%t | %s", isRetriableError(err), err)
+			return reconcile.Result{}, ignoreNonRetriableError(err)
+		}
+		patch := client.MergeFrom(gw.DeepCopy())
+		controllerutil.RemoveFinalizer(gw, ingress.GatewayFinalizer)
+		return reconcile.Result{}, client.IgnoreNotFound(r.k8sClient.Patch(ctx, gw, patch))
+	}
+	if !controllerutil.ContainsFinalizer(gw, ingress.GatewayFinalizer) {
+		patch := client.MergeFrom(gw.DeepCopy())
+		controllerutil.AddFinalizer(gw, ingress.GatewayFinalizer)
+		if err := r.k8sClient.Patch(ctx, gw, patch); err != nil {
+			logger.Errorf("Reconcile failed: %s", err)
+			return reconcile.Result{}, err
+		}
+	}
+	logger.Info("UpdateOrCreateGateway()")
+	requeueAfter, err := r.ingressManager.UpdateOrCreateGateway(gw)
+	if err != nil {
+		r.recorder.Eventf(gw, corev1.EventTypeWarning, ingressmanager.ReasonSyncFailed, "Failed to sync load balancer: %s", err)
+		logger.Errorf("Reconcile failed: Retryable=%t | %s", isRetriableError(err), err)
+		return reconcile.Result{}, ignoreNonRetriableError(err)
+	}
+	logger.Debug("Reconcile succeeded")
+	return reconcile.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// errGatewayClassNotFound is returned by isOurs() for a gateway carrying our finalizer while its class is missing
+var errGatewayClassNotFound = errors.New("gateway class not found")
+
+// isOurs tells whether the GatewayClass of the gateway is handled by this controller. A missing class is not ours, unless
+// the gateway carries our finalizer. The class may then be recreated (eg: renamed, re-applied), so the load balancer is
+// kept and errGatewayClassNotFound is returned for the gateway to be requeued.
+func (r *gatewayReconciler) isOurs(ctx context.Context, gw *gatewayv1beta1.Gateway) (bool, error) {
+	class := &gatewayv1beta1.GatewayClass{}
+	if err := r.cache.Get(ctx, types.NamespacedName{Name: string(gw.Spec.GatewayClassName)}, class); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return false, err
+		}
+		if controllerutil.ContainsFinalizer(gw, ingress.GatewayFinalizer) {
+			return false, pkgerrors.Wrapf(errGatewayClassNotFound, "%q", gw.Spec.GatewayClassName)
+		}
+		return false, nil
+	}
+	return ingress.IsOCIGatewayClass(class), nil
+}
+
+// gatewayClassReconciler accepts GatewayClasses handled by this controller, once their parameters are valid
+type gatewayClassReconciler struct {
+	k8sClient client.Client
+	cache     cache.Cache
+	logger    *zap.Logger
+}
+
+func NewGatewayClassReconciler(controllerMgr manager.Manager, logger *zap.Logger) reconcile.Reconciler {
+	return &gatewayClassReconciler{
+		k8sClient: controllerMgr.GetClient(),
+		cache:     controllerMgr.GetCache(),
+		logger:    logger,
+	}
+}
+
+func (r *gatewayClassReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	logger := r.logger.Sugar().With("gatewayClass", request.Name)
+	class := &gatewayv1beta1.GatewayClass{}
+	if err := r.cache.Get(ctx, request.NamespacedName, class); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	if !ingress.IsOCIGatewayClass(class) {
+		return reconcile.Result{}, nil
+	}
+	accepted := metav1.Condition{
+		Type:               string(gatewayv1beta1.GatewayClassConditionStatusAccepted),
+		Status:             metav1.ConditionTrue,
+		Reason:             string(gatewayv1beta1.GatewayClassReasonAccepted),
+		ObservedGeneration: class.Generation,
+	}
+	if _, err := ingress.GetGatewayClassParameters(ctx, r.cache, class); err != nil {
+		// Errors reading existing parameters are retried. Others are about the reference itself.
+		if _, isAPIError := pkgerrors.Cause(err).(apierrors.APIStatus); isAPIError && !apierrors.IsNotFound(pkgerrors.Cause(err)) {
+			return reconcile.Result{}, err
+		}
+		accepted.Status, accepted.Reason, accepted.Message = metav1.ConditionFalse, string(gatewayv1beta1.GatewayClassReasonInvalidParameters), err.Error()
+	}
+	if existing := meta.FindStatusCondition(class.Status.Conditions, accepted.Type); existing != nil &&
+		existing.Status == accepted.Status && existing.Reason == accepted.Reason && existing.Message == accepted.Message && existing.ObservedGeneration == accepted.ObservedGeneration {
+		return reconcile.Result{}, nil
+	}
+	meta.SetStatusCondition(&class.Status.Conditions, accepted)
+	logger.With("status", accepted.Status, "reason", accepted.Reason).Info("Updating gateway class status")
+	return reconcile.Result{}, client.IgnoreNotFound(r.k8sClient.Status().Update(ctx, class))
+}
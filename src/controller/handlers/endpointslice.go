@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/helpers"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/ingress"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/utils"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// When endpoints of a service change, update the backends of ingresses routing to the pods of the service,
+// or to the nodes running them if the service has externalTrafficPolicy=Local
+
+func NewEndpointSliceEventHandler(cache cache.Cache, logger *zap.Logger) handler.EventHandler {
+	return &endpointSliceEventHandler{
+		cache:  cache,
+		logger: *logger,
+	}
+}
+
+type endpointSliceEventHandler struct {
+	cache  cache.Cache
+	logger zap.Logger
+}
+
+func (h *endpointSliceEventHandler) Create(evt event.CreateEvent, queue workqueue.RateLimitingInterface) {
+	slice := evt.Object.(*discoveryv1.EndpointSlice)
+	h.enqueueImpactedIngresses(queue, slice, fmt.Sprintf("EndpointSliceCreate %s", client.ObjectKeyFromObject(slice)))
+}
+
+func (h *endpointSliceEventHandler) Delete(evt event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+	slice, ok := evt.Object.(*discoveryv1.EndpointSlice)
+	if !ok {
+		return
+	}
+	h.enqueueImpactedIngresses(queue, slice, fmt.Sprintf("EndpointSliceDelete %s", client.ObjectKeyFromObject(slice)))
+}
+
+func (h *endpointSliceEventHandler) Update(evt event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+	oldSlice, newSlice := evt.ObjectOld.(*discoveryv1.EndpointSlice), evt.ObjectNew.(*discoveryv1.EndpointSlice)
+	if equality.Semantic.DeepEqual(oldSlice.Endpoints, newSlice.Endpoints) && equality.Semantic.DeepEqual(oldSlice.Ports, newSlice.Ports) {
+		return
+	}
+	h.enqueueImpactedIngresses(queue, newSlice, fmt.Sprintf("EndpointSliceUpdate %s", client.ObjectKeyFromObject(newSlice)))
+}
+
+func (h *endpointSliceEventHandler) Generic(event.GenericEvent, workqueue.RateLimitingInterface) {
+}
+
+func (h *endpointSliceEventHandler) enqueueImpactedIngresses(queue workqueue.RateLimitingInterface, slice *discoveryv1.EndpointSlice, cause string) {
+	svcName := slice.Labels[discoveryv1.LabelServiceName]
+	if svcName == "" {
+		return
+	}
+	ctx := context.Background()
+	svc := &corev1.Service{}
+	localTraffic := h.cache.Get(ctx, types.NamespacedName{Namespace: slice.Namespace, Name: svcName}, svc) == nil && helpers.RequestsOnlyLocalTraffic(svc)
+	ingressList := &networking.IngressList{}
+	if err := h.cache.List(ctx, ingressList, client.InNamespace(slice.Namespace)); err != nil {
+		return
+	}
+	var ociIngressNames []string
+	for i := range ingressList.Items {
+		ing := &ingressList.Items[i]
+		if !ingress.IsOCILoadbalancerIngress(ing, &h.logger) {
+			continue
+		}
+		if target, _ := ingress.GetBackendTarget(ing); target != oci.BackendTargetPod && !localTraffic || !ingress.RefersToService(ing, svcName) {
+			continue
+		}
+		nName := utils.GetNamespacedName(ing.ObjectMeta)
+		ociIngressNames = append(ociIngressNames, nName.String())
+		queue.Add(reconcile.Request{NamespacedName: nName})
+	}
+	if len(ociIngressNames) != 0 {
+		h.logger.Sugar().Debugf("Enqueue to reconcile %d ingresses: %s | Cause: %s", len(ociIngressNames), strings.Join(ociIngressNames, ","), cause)
+	}
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nom3ad/oci-lb-ingress-controller/src/apis/v1alpha1"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/ingress"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/utils"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// Maps changes of gateways, and of the objects their load balancers derive from, to gateways to reconcile:
+// HTTPRoutes to their parent gateways, GatewayClasses (and their parameters) to gateways of the class,
+// Secrets to gateways with listeners referring to them, and added, removed or changed Nodes to all gateways.
+
+func NewGatewayEventHandler(cache cache.Cache, logger *zap.Logger) handler.EventHandler {
+	return &gatewayEventHandler{
+		cache:  cache,
+		logger: *logger,
+	}
+}
+
+type gatewayEventHandler struct {
+	cache  cache.Cache
+	logger zap.Logger
+}
+
+func (h *gatewayEventHandler) Create(evt event.CreateEvent, queue workqueue.RateLimitingInterface) {
+	h.enqueue(queue, evt.Object, fmt.Sprintf("Create %s", client.ObjectKeyFromObject(evt.Object)))
+}
+
+func (h *gatewayEventHandler) Delete(evt event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+	if _, ok := evt.Object.(*corev1.Secret); ok {
+		return
+	}
+	h.enqueue(queue, evt.Object, fmt.Sprintf("Delete %s", client.ObjectKeyFromObject(evt.Object)))
+}
+
+func (h *gatewayEventHandler) Update(evt event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+	if newNode, ok := evt.ObjectNew.(*corev1.Node); ok {
+		if hasNodeBackendChanged(evt.ObjectOld.(*corev1.Node), newNode) {
+			h.enqueue(queue, newNode, fmt.Sprintf("Update %s", newNode.Name))
+		}
+		return
+	}
+	// Old object matters too. eg: parents a route was detached from
+	h.enqueue(queue, evt.ObjectOld, fmt.Sprintf("Update %s", client.ObjectKeyFromObject(evt.ObjectOld)))
+	h.enqueue(queue, evt.ObjectNew, fmt.Sprintf("Update %s", client.ObjectKeyFromObject(evt.ObjectNew)))
+}
+
+func (h *gatewayEventHandler) Generic(event.GenericEvent, workqueue.RateLimitingInterface) {
+}
+
+func (h *gatewayEventHandler) enqueue(queue workqueue.RateLimitingInterface, obj client.Object, cause string) {
+	switch o := obj.(type) {
+	case *gatewayv1beta1.Gateway:
+		queue.Add(reconcile.Request{NamespacedName: utils.GetNamespacedName(o.ObjectMeta)})
+	case *gatewayv1beta1.HTTPRoute:
+		parents := map[types.NamespacedName]bool{}
+		for _, parent := range ingress.GetRouteGateways(o) {
+			parents[parent] = true
+		}
+		h.enqueueGateways(queue, func(gw *gatewayv1beta1.Gateway) bool {
+			return parents[types.NamespacedName{Namespace: gw.Namespace, Name: gw.Name}]
+		}, "HTTPRoute"+cause)
+	case *gatewayv1beta1.GatewayClass:
+		h.enqueueGateways(queue, func(gw *gatewayv1beta1.Gateway) bool { return string(gw.Spec.GatewayClassName) == o.Name }, "GatewayClass"+cause)
+	case *v1alpha1.IngressClassParameters:
+		classList := &gatewayv1beta1.GatewayClassList{}
+		if err := h.cache.List(context.Background(), classList); err != nil {
+			return
+		}
+		classNames := sets.NewString()
+		for _, class := range classList.Items {
+			ref := class.Spec.ParametersRef
+			if ref != nil && string(ref.Kind) == v1alpha1.IngressClassParametersKind && ref.Name == o.Name {
+				classNames.Insert(class.Name)
+			}
+		}
+		h.enqueueGateways(queue, func(gw *gatewayv1beta1.Gateway) bool { return classNames.Has(string(gw.Spec.GatewayClassName)) }, "IngressClassParameters"+cause)
+	case *corev1.Node:
+		h.enqueueGateways(queue, func(gw *gatewayv1beta1.Gateway) bool { return true }, "Node"+cause)
+	case *corev1.Secret:
+		h.enqueueGateways(queue, func(gw *gatewayv1beta1.Gateway) bool { return gatewayRefersToSecret(gw, o) }, "Secret"+cause)
+	}
+}
+
+func gatewayRefersToSecret(gw *gatewayv1beta1.Gateway, secret *corev1.Secret) bool {
+	for _, listener := range gw.Spec.Listeners {
+		if listener.TLS == nil {
+			continue
+		}
+		for _, ref := range listener.TLS.CertificateRefs {
+			namespace := gw.Namespace
+			if ref.Namespace != nil {
+				namespace = string(*ref.Namespace)
+			}
+			if namespace == secret.Namespace && string(ref.Name) == secret.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (h *gatewayEventHandler) enqueueGateways(queue workqueue.RateLimitingInterface, match func(*gatewayv1beta1.Gateway) bool, cause string) {
+	gatewayList := &gatewayv1beta1.GatewayList{}
+	if err := h.cache.List(context.Background(), gatewayList); err != nil {
+		return
+	}
+	var gatewayNames []string
+	for i := range gatewayList.Items {
+		gw := &gatewayList.Items[i]
+		if !match(gw) {
+			continue
+		}
+		nName := utils.GetNamespacedName(gw.ObjectMeta)
+		gatewayNames = append(gatewayNames, nName.String())
+		queue.Add(reconcile.Request{NamespacedName: nName})
+	}
+	if len(gatewayNames) != 0 {
+		h.logger.Sugar().Debugf("Enqueue to reconcile %d gateways: %s | Cause: %s", len(gatewayNames), strings.Join(gatewayNames, ","), cause)
+	}
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"context"
+
 	"github.com/nom3ad/oci-lb-ingress-controller/src/ingress"
 	"go.uber.org/zap"
 	networking "k8s.io/api/networking/v1"
@@ -28,6 +30,7 @@ type ingressEventHandler struct {
 func (h *ingressEventHandler) Create(evt event.CreateEvent, queue workqueue.RateLimitingInterface) {
 	if ing, ok := evt.Object.(*networking.Ingress); ok && ing != nil {
 		h.enqueueIfIngressClassMatched(ing, queue, "IngressCreateEvent")
+		h.enqueueGroupMembers(ing, queue, "IngressCreateEvent")
 	} else {
 		h.logger.Sugar().Warn("CreateEvent received with no ingress object", evt)
 	}
@@ -35,7 +38,7 @@ func (h *ingressEventHandler) Create(evt event.CreateEvent, queue workqueue.Rate
 
 // Update is a handler called when an ingress object is updated
 func (h *ingressEventHandler) Update(evt event.UpdateEvent, queue workqueue.RateLimitingInterface) {
-	if oldIng, ok := evt.ObjectNew.(*networking.Ingress); ok && oldIng != nil {
+	if oldIng, ok := evt.ObjectOld.(*networking.Ingress); ok && oldIng != nil {
 		h.enqueueIfIngressClassMatched(oldIng, queue, "IngressUpdateEvent")
 	} else {
 		h.logger.Sugar().Warn("UpdateEvent received with no old ingress object", evt)
@@ -46,12 +49,23 @@ func (h *ingressEventHandler) Update(evt event.UpdateEvent, queue workqueue.Rate
 	} else {
 		h.logger.Sugar().Warn("UpdateEvent received with no new ingress object", evt)
 	}
+
+	// Status updates are left out, as every member gets one after a sync
+	if oldIng, ok := evt.ObjectOld.(*networking.Ingress); ok && oldIng != nil {
+		if newIng, ok := evt.ObjectNew.(*networking.Ingress); ok && newIng != nil {
+			if oldIng.Generation != newIng.Generation || ingress.GetLoadBalancerGroup(oldIng) != ingress.GetLoadBalancerGroup(newIng) || !newIng.DeletionTimestamp.Equal(oldIng.DeletionTimestamp) {
+				h.enqueueGroupMembers(oldIng, queue, "IngressUpdateEvent")
+				h.enqueueGroupMembers(newIng, queue, "IngressUpdateEvent")
+			}
+		}
+	}
 }
 
 // Delete is a handler called when an ingress object is deleted
 func (h *ingressEventHandler) Delete(evt event.DeleteEvent, queue workqueue.RateLimitingInterface) {
 	if ing, ok := evt.Object.(*networking.Ingress); ok && ing != nil {
 		h.enqueueIfIngressClassMatched(ing, queue, "IngressDeleteEvent")
+		h.enqueueGroupMembers(ing, queue, "IngressDeleteEvent")
 	} else {
 		h.logger.Sugar().Warn("DeleteEvent received with no ingress object", evt)
 	}
@@ -68,10 +82,33 @@ func (h *ingressEventHandler) Generic(evt event.GenericEvent, queue workqueue.Ra
 
 func (h *ingressEventHandler) enqueueIfIngressClassMatched(ing *networking.Ingress, queue workqueue.RateLimitingInterface, cause string) {
 	nName := types.NamespacedName{Namespace: ing.Namespace, Name: ing.Name}
-	if !ingress.IsOCILoadbalancerIngress(ing) {
-		h.logger.Sugar().Debugf("Won't reconcile ingress %s class: %s", ingress.GetIngressClassName(ing))
+	// An ingress carrying our finalizer must be reconciled even if it no longer has the OCI ingress class,
+	// so that the load balancer gets cleaned up.
+	if !ingress.IsOCILoadbalancerIngress(ing, h.logger) && !ingress.HasIngressFinalizer(ing) {
+		h.logger.Sugar().Debugf("Won't reconcile ingress %s class: %s", nName, ingress.GetIngressClassName(ing))
 		return
 	}
 	h.logger.Sugar().Debugf("Enqueue to reconcile ingress %s | Cause: %s", nName, cause)
 	queue.Add(reconcile.Request{NamespacedName: nName})
 }
+
+// enqueueGroupMembers enqueues other ingresses sharing the load balancer group of the ingress. A change in one member may resolve
+// or cause conflicts in others.
+func (h *ingressEventHandler) enqueueGroupMembers(ing *networking.Ingress, queue workqueue.RateLimitingInterface, cause string) {
+	group := ingress.GetLoadBalancerGroup(ing)
+	if group == "" {
+		return
+	}
+	ingressList := &networking.IngressList{}
+	if err := h.cache.List(context.Background(), ingressList); err != nil {
+		h.logger.Sugar().With(zap.Error(err)).Errorf("Couldn't list members of load balancer group %q", group)
+		return
+	}
+	for i := range ingressList.Items {
+		member := &ingressList.Items[i]
+		if ingress.GetLoadBalancerGroup(member) != group || (member.Namespace == ing.Namespace && member.Name == ing.Name) {
+			continue
+		}
+		h.enqueueIfIngressClassMatched(member, queue, cause+" of group member "+ing.Namespace+"/"+ing.Name)
+	}
+}
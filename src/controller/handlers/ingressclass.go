@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nom3ad/oci-lb-ingress-controller/src/apis/v1alpha1"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/ingress"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/utils"
+	"go.uber.org/zap"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// When an IngressClass or IngressClassParameters changes, reconcile ingresses of the affected classes.
+// Ingresses whose class moved to another controller are reconciled too, to release their load balancers.
+
+func NewIngressClassEventHandler(cache cache.Cache, logger *zap.Logger) handler.EventHandler {
+	return &ingressClassEventHandler{
+		cache:  cache,
+		logger: *logger,
+	}
+}
+
+type ingressClassEventHandler struct {
+	cache  cache.Cache
+	logger zap.Logger
+}
+
+func (h *ingressClassEventHandler) Create(evt event.CreateEvent, queue workqueue.RateLimitingInterface) {
+	h.enqueue(queue, evt.Object, fmt.Sprintf("Create %s", evt.Object.GetName()))
+}
+
+func (h *ingressClassEventHandler) Delete(evt event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+	h.enqueue(queue, evt.Object, fmt.Sprintf("Delete %s", evt.Object.GetName()))
+}
+
+func (h *ingressClassEventHandler) Update(evt event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+	// Old object matters too. eg: class that was the default one
+	h.enqueue(queue, evt.ObjectOld, fmt.Sprintf("Update %s", evt.ObjectOld.GetName()))
+	h.enqueue(queue, evt.ObjectNew, fmt.Sprintf("Update %s", evt.ObjectNew.GetName()))
+}
+
+func (h *ingressClassEventHandler) Generic(event.GenericEvent, workqueue.RateLimitingInterface) {
+}
+
+func (h *ingressClassEventHandler) enqueue(queue workqueue.RateLimitingInterface, obj client.Object, cause string) {
+	switch o := obj.(type) {
+	case *networking.IngressClass:
+		h.enqueueIngressesOfClasses(queue, []*networking.IngressClass{o}, "IngressClass"+cause)
+	case *v1alpha1.IngressClassParameters:
+		classList := &networking.IngressClassList{}
+		if err := h.cache.List(context.Background(), classList); err != nil {
+			return
+		}
+		var classes []*networking.IngressClass
+		for i := range classList.Items {
+			ref := classList.Items[i].Spec.Parameters
+			if ref != nil && ref.Kind == v1alpha1.IngressClassParametersKind && ref.Name == o.Name {
+				classes = append(classes, &classList.Items[i])
+			}
+		}
+		h.enqueueIngressesOfClasses(queue, classes, "IngressClassParameters"+cause)
+	}
+}
+
+func (h *ingressClassEventHandler) enqueueIngressesOfClasses(queue workqueue.RateLimitingInterface, classes []*networking.IngressClass, cause string) {
+	if len(classes) == 0 {
+		return
+	}
+	classNames := sets.NewString()
+	includeClassless := false
+	for _, class := range classes {
+		classNames.Insert(class.Name)
+		if class.Annotations[ingress.IngressClassDefaultAnnotation] == "true" {
+			includeClassless = true
+		}
+	}
+	ingressList := &networking.IngressList{}
+	if err := h.cache.List(context.Background(), ingressList); err != nil {
+		return
+	}
+	var ingressNames []string
+	for _, ing := range ingressList.Items {
+		className := ingress.GetIngressClassName(&ing)
+		if !classNames.Has(className) && !(className == "" && includeClassless) {
+			continue
+		}
+		nName := utils.GetNamespacedName(ing.ObjectMeta)
+		ingressNames = append(ingressNames, nName.String())
+		queue.Add(reconcile.Request{NamespacedName: nName})
+	}
+	if len(ingressNames) != 0 {
+		h.logger.Sugar().Debugf("Enqueue to reconcile %d ingresses: %s | Cause: %s", len(ingressNames), strings.Join(ingressNames, ","), cause)
+	}
+}
@@ -5,10 +5,13 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/helpers"
 	ingress "github.com/nom3ad/oci-lb-ingress-controller/src/ingress"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
@@ -17,7 +20,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
-// When nodes change (added or removed from cluster) update the state of the load balancers for all ingress objects
+// When nodes change (added or removed from cluster, or changed in a way deciding whether or how they are backends)
+// update the state of the load balancers for all ingress objects
 
 func NewNodeEventHandler(cache cache.Cache, logger *zap.Logger) handler.EventHandler {
 	return &nodeEventHandler{
@@ -40,7 +44,20 @@ func (h *nodeEventHandler) Delete(evt event.DeleteEvent, queue workqueue.RateLim
 }
 
 func (h *nodeEventHandler) Update(evt event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+	oldNode, newNode := evt.ObjectOld.(*corev1.Node), evt.ObjectNew.(*corev1.Node)
+	if !hasNodeBackendChanged(oldNode, newNode) {
+		return
+	}
+	h.enqueueImpactedIngresses(queue, fmt.Sprintf("NodeUpdateEvent %s", newNode.Name))
+}
 
+// hasNodeBackendChanged tells whether the change of the node matters to backend sets: its eligibility, drain state,
+// labels (node selectors) or internal IP
+func hasNodeBackendChanged(oldNode, newNode *corev1.Node) bool {
+	return oci.IsNodeEligibleBackend(oldNode) != oci.IsNodeEligibleBackend(newNode) ||
+		oci.IsNodeDraining(oldNode) != oci.IsNodeDraining(newNode) ||
+		!equality.Semantic.DeepEqual(oldNode.Labels, newNode.Labels) ||
+		helpers.NodeInternalIP(oldNode) != helpers.NodeInternalIP(newNode)
 }
 
 func (h *nodeEventHandler) Generic(event.GenericEvent, workqueue.RateLimitingInterface) {
@@ -53,7 +70,7 @@ func (h *nodeEventHandler) enqueueImpactedIngresses(queue workqueue.RateLimiting
 	}
 	var ociIngressNames []string
 	for _, ing := range ingressList.Items {
-		if !ingress.IsOCILoadbalancerIngress(&ing) {
+		if !ingress.IsOCILoadbalancerIngress(&ing, &h.logger) {
 			continue
 		}
 		nName := types.NamespacedName{Namespace: ing.Namespace, Name: ing.Name}
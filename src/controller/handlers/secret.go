@@ -55,7 +55,7 @@ func (h *secretEventHandler) enqueueImpactedIngresses(queue workqueue.RateLimiti
 	secretNsNameString := utils.GetNamespacedNameStr(secret.ObjectMeta)
 	var ociIngressNames []string
 	for _, ing := range ingressList.Items {
-		if !ingress.IsOCILoadbalancerIngress(&ing) {
+		if !ingress.IsOCILoadbalancerIngress(&ing, &h.logger) {
 			continue
 		}
 		ingressHasSecret := false
@@ -65,6 +65,9 @@ func (h *secretEventHandler) enqueueImpactedIngresses(queue workqueue.RateLimiti
 				break
 			}
 		}
+		if !ingressHasSecret && ing.Namespace == secret.Namespace {
+			ingressHasSecret = ingress.BackendTLSSecrets(context.Background(), h.cache, &ing).Has(secret.Name)
+		}
 		if !ingressHasSecret {
 			continue
 		}
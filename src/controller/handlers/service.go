@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nom3ad/oci-lb-ingress-controller/src/ingress"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/utils"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// When a service is added, removed, or its ports or annotations (eg: health checks) change, update the backend sets
+// of ingresses routing to it
+
+func NewServiceEventHandler(cache cache.Cache, logger *zap.Logger) handler.EventHandler {
+	return &serviceEventHandler{
+		cache:  cache,
+		logger: *logger,
+	}
+}
+
+type serviceEventHandler struct {
+	cache  cache.Cache
+	logger zap.Logger
+}
+
+func (h *serviceEventHandler) Create(evt event.CreateEvent, queue workqueue.RateLimitingInterface) {
+	svc := evt.Object.(*corev1.Service)
+	h.enqueueImpactedIngresses(queue, svc, fmt.Sprintf("ServiceCreate %s", client.ObjectKeyFromObject(svc)))
+}
+
+func (h *serviceEventHandler) Delete(evt event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+	svc, ok := evt.Object.(*corev1.Service)
+	if !ok {
+		return
+	}
+	h.enqueueImpactedIngresses(queue, svc, fmt.Sprintf("ServiceDelete %s", client.ObjectKeyFromObject(svc)))
+}
+
+func (h *serviceEventHandler) Update(evt event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+	oldSvc, newSvc := evt.ObjectOld.(*corev1.Service), evt.ObjectNew.(*corev1.Service)
+	if equality.Semantic.DeepEqual(oldSvc.Annotations, newSvc.Annotations) && equality.Semantic.DeepEqual(oldSvc.Spec, newSvc.Spec) {
+		return
+	}
+	h.enqueueImpactedIngresses(queue, newSvc, fmt.Sprintf("ServiceUpdate %s", client.ObjectKeyFromObject(newSvc)))
+}
+
+func (h *serviceEventHandler) Generic(event.GenericEvent, workqueue.RateLimitingInterface) {
+}
+
+func (h *serviceEventHandler) enqueueImpactedIngresses(queue workqueue.RateLimitingInterface, svc *corev1.Service, cause string) {
+	ingressList := &networking.IngressList{}
+	if err := h.cache.List(context.Background(), ingressList, client.InNamespace(svc.Namespace)); err != nil {
+		return
+	}
+	var ociIngressNames []string
+	for i := range ingressList.Items {
+		ing := &ingressList.Items[i]
+		if !ingress.IsOCILoadbalancerIngress(ing, &h.logger) || !ingress.RefersToService(ing, svc.Name) {
+			continue
+		}
+		nName := utils.GetNamespacedName(ing.ObjectMeta)
+		ociIngressNames = append(ociIngressNames, nName.String())
+		queue.Add(reconcile.Request{NamespacedName: nName})
+	}
+	if len(ociIngressNames) != 0 {
+		h.logger.Sugar().Debugf("Enqueue to reconcile %d ingresses: %s | Cause: %s", len(ociIngressNames), strings.Join(ociIngressNames, ","), cause)
+	}
+}
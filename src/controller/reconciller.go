@@ -2,65 +2,231 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
 
 	"go.uber.org/zap"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	corev1 "k8s.io/api/core/v1"
 	networking "k8s.io/api/networking/v1"
+	"k8s.io/client-go/tools/record"
 
+	"github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/health"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/ingress"
 	ingressmanager "github.com/nom3ad/oci-lb-ingress-controller/src/manager"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // reconciler reconciles a single ingress
 type reconciler struct {
+	counterMu sync.Mutex // reconciles of different ingresses run concurrently
 	counter   map[string]int
 	k8sClient client.Client
 	cache     cache.Cache
 	//store         store.Store
 	ingressManager ingressmanager.Manager
+	recorder       record.EventRecorder
+	tracker        *health.ReconcileTracker
 	logger         *zap.Logger
 }
 
-func NewReconciler(controllerMgr manager.Manager, ingressMgr ingressmanager.Manager, logger *zap.Logger) (reconcile.Reconciler, error) {
+func NewReconciler(controllerMgr manager.Manager, ingressMgr ingressmanager.Manager, recorder record.EventRecorder, tracker *health.ReconcileTracker, logger *zap.Logger) (reconcile.Reconciler, error) {
 
 	return &reconciler{
 		k8sClient:      controllerMgr.GetClient(),
 		cache:          controllerMgr.GetCache(),
 		ingressManager: ingressMgr,
+		recorder:       recorder,
+		tracker:        tracker,
 		logger:         logger,
 		counter:        map[string]int{},
 	}, nil
 }
 
 func (r *reconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
-	i := r.counter[request.String()] + 1
-	r.counter[request.String()] = i
+	startTime := time.Now()
+	defer r.tracker.Begin(request.String())()
+	i := r.nextAttempt(request.String())
 	logger := r.logger.Sugar().With("ingress", request.NamespacedName)
 	logger.Debugf("Reconcile #%d called", i)
-	ingress := &networking.Ingress{}
-	if err := r.cache.Get(ctx, request.NamespacedName, ingress); err != nil {
+	ing := &networking.Ingress{}
+	if err := r.cache.Get(ctx, request.NamespacedName, ing); err != nil {
 		if !apierrors.IsNotFound(err) {
 			logger.Errorf("Reconcile #%d failed: Retryable=%t | %s", i, isRetriableError(err), err)
 			return reconcile.Result{}, ignoreNonRetriableError(err)
 		}
+		// Ingresses having our finalizer never reach here before their load balancer is deleted.
+		// This is only for ingresses created before the finalizer was introduced.
 		logger.Info("DeleteIngress()")
-		if err := r.ingressManager.DeleteIngress(request.NamespacedName); err != nil {
+		ing.ObjectMeta = metav1.ObjectMeta{Namespace: request.Namespace, Name: request.Name}
+		if err := r.ingressManager.DeleteIngress(ing); err != nil {
 			logger.Errorf("Reconcile #%d failed: Retryable=%t | %s", i, isRetriableError(err), err)
 			return reconcile.Result{}, ignoreNonRetriableError(err)
 		}
-		delete(r.counter, request.String())
+		r.forgetAttempts(request.String())
+		metrics.ForgetIngress(request.NamespacedName)
+	} else if _, ours, err := ingress.GetIngressClass(ctx, ing); err != nil {
+		// Not knowing the class must not be taken as the ingress having moved away from our class
+		logger.Errorf("Reconcile #%d failed: %s", i, err)
+		return reconcile.Result{}, err
+	} else if ing.DeletionTimestamp != nil || !ours {
+		if !ingress.HasIngressFinalizer(ing) {
+			logger.Debugf("Reconcile #%d: nothing to cleanup", i)
+			return reconcile.Result{}, nil
+		}
+		logger.Infof("DeleteIngress() | Deleting=%t Class=%q", ing.DeletionTimestamp != nil, ingress.GetIngressClassName(ing))
+		if err := r.ingressManager.DeleteIngress(ing); errors.Is(err, ingressmanager.ErrPlanOnly) {
+			logger.Infof("Reconcile #%d: plan mode, keeping the load balancer and the finalizer", i)
+			return reconcile.Result{}, nil
+		} else if err != nil {
+			r.recorder.Eventf(ing, corev1.EventTypeWarning, ingressmanager.ReasonSyncFailed, "Failed to delete load balancer: %s", err)
+			logger.Errorf("Reconcile #%d failed: Retryable=%t | %s", i, isRetriableError(err), err)
+			return reconcile.Result{}, ignoreNonRetriableError(err)
+		}
+		if ing.DeletionTimestamp == nil {
+			// Ingress has moved away from our ingress class. Load balancer IP is no longer valid.
+			if err := r.clearIngressStatus(ctx, ing); err != nil {
+				logger.Errorf("Reconcile #%d failed: %s", i, err)
+				return reconcile.Result{}, err
+			}
+		}
+		if err := r.removeFinalizer(ctx, ing); err != nil {
+			logger.Errorf("Reconcile #%d failed: %s", i, err)
+			return reconcile.Result{}, err
+		}
+		r.forgetAttempts(request.String())
+		metrics.ForgetIngress(request.NamespacedName)
 	} else {
+		if err := r.addFinalizer(ctx, ing); err != nil {
+			logger.Errorf("Reconcile #%d failed: %s", i, err)
+			return reconcile.Result{}, err
+		}
 		logger.Info("UpdateOrCreateIngress()")
-		if err := r.ingressManager.UpdateOrCreateIngress(ingress); err != nil {
+		requeueAfter, err := r.ingressManager.UpdateOrCreateIngress(ing)
+		metrics.ObserveReconcile(request.NamespacedName, startTime, err)
+		if err != nil {
+			r.recorder.Eventf(ing, corev1.EventTypeWarning, ingressmanager.ReasonSyncFailed, "Failed to sync load balancer: %s", err)
+		}
+		if err := r.recordSyncResult(ctx, ing, err); err != nil {
+			logger.Warnf("Reconcile #%d: couldn't record sync result: %s", i, err)
+		}
+		if err != nil {
 			logger.Errorf("Reconcile #%d failed: Retryable=%t | %s", i, isRetriableError(err), err)
 			return reconcile.Result{}, ignoreNonRetriableError(err)
 		}
+		if requeueAfter > 0 {
+			logger.Debugf("Reconcile #%d succeeded, backends are draining. Requeue after %s", i, requeueAfter)
+			return reconcile.Result{RequeueAfter: requeueAfter}, nil
+		}
 	}
 	logger.Debugf("Reconcile #%d succeeded", i)
 	return reconcile.Result{}, nil
 }
+
+func (r *reconciler) nextAttempt(key string) int {
+	r.counterMu.Lock()
+	defer r.counterMu.Unlock()
+	r.counter[key]++
+	return r.counter[key]
+}
+
+func (r *reconciler) forgetAttempts(key string) {
+	r.counterMu.Lock()
+	defer r.counterMu.Unlock()
+	delete(r.counter, key)
+}
+
+func (r *reconciler) addFinalizer(ctx context.Context, ing *networking.Ingress) error {
+	if ingress.HasIngressFinalizer(ing) {
+		return nil
+	}
+	patch := client.MergeFrom(ing.DeepCopy())
+	controllerutil.AddFinalizer(ing, ingress.IngressFinalizer)
+	return r.k8sClient.Patch(ctx, ing, patch)
+}
+
+func (r *reconciler) removeFinalizer(ctx context.Context, ing *networking.Ingress) error {
+	patch := client.MergeFrom(ing.DeepCopy())
+	controllerutil.RemoveFinalizer(ing, ingress.IngressFinalizer)
+	if ing.DeletionTimestamp == nil {
+		// Ingress has moved away from our ingress class. Drop the annotations maintained by the controller.
+		for _, name := range oci.ControllerIngressAnnotations {
+			delete(ing.Annotations, oci.IngressAnnotationPrefix+name)
+		}
+	}
+	return client.IgnoreNotFound(r.k8sClient.Patch(ctx, ing, patch))
+}
+
+func (r *reconciler) clearIngressStatus(ctx context.Context, ing *networking.Ingress) error {
+	if len(ing.Status.LoadBalancer.Ingress) == 0 {
+		return nil
+	}
+	ing.Status.LoadBalancer.Ingress = nil
+	return client.IgnoreNotFound(r.k8sClient.Status().Update(ctx, ing))
+}
+
+// maxSyncErrorLength limits the error message recorded in the sync error annotation
+const maxSyncErrorLength = 1024
+
+// recordSyncResult saves a machine-readable summary of the last sync in ingress annotations.
+// Every patch triggers another reconcile. So a failure is recorded only once per generation, as error messages may
+// differ between attempts (eg: OCI request IDs) and would cause a reconcile loop otherwise.
+func (r *reconciler) recordSyncResult(ctx context.Context, ing *networking.Ingress, syncErr error) error {
+	patch := client.MergeFrom(ing.DeepCopy())
+	if ing.Annotations == nil {
+		ing.Annotations = map[string]string{}
+	}
+	prefix := oci.IngressAnnotationPrefix
+	generation := strconv.FormatInt(ing.Generation, 10)
+	changed := false
+	set := func(name, value string) {
+		if old, ok := ing.Annotations[prefix+name]; !ok || old != value {
+			ing.Annotations[prefix+name] = value
+			changed = true
+		}
+	}
+	if syncErr == nil && ingressmanager.IsPlanOnly(ing) {
+		// Nothing is applied in plan mode. Last synced generation is left as is.
+		set(oci.AnnotationSyncStatus, "Planned")
+		if _, ok := ing.Annotations[prefix+oci.AnnotationSyncError]; ok {
+			delete(ing.Annotations, prefix+oci.AnnotationSyncError)
+			changed = true
+		}
+	} else if syncErr == nil {
+		if ing.Annotations[prefix+oci.AnnotationLastSyncedGeneration] != generation {
+			r.recorder.Eventf(ing, corev1.EventTypeNormal, ingressmanager.ReasonSynced, "Load balancer synced for generation %s", generation)
+		}
+		set(oci.AnnotationSyncStatus, "Synced")
+		set(oci.AnnotationLastSyncedGeneration, generation)
+		if _, ok := ing.Annotations[prefix+oci.AnnotationSyncError]; ok {
+			delete(ing.Annotations, prefix+oci.AnnotationSyncError)
+			changed = true
+		}
+	} else {
+		if ing.Annotations[prefix+oci.AnnotationSyncStatus] == "Failed" && ing.Annotations[prefix+oci.AnnotationSyncGeneration] == generation {
+			return nil
+		}
+		msg := syncErr.Error()
+		if len(msg) > maxSyncErrorLength {
+			msg = msg[:maxSyncErrorLength]
+		}
+		set(oci.AnnotationSyncStatus, "Failed")
+		set(oci.AnnotationSyncError, msg)
+	}
+	set(oci.AnnotationSyncGeneration, generation)
+	if !changed {
+		return nil
+	}
+	return client.IgnoreNotFound(r.k8sClient.Patch(ctx, ing, patch))
+}
@@ -0,0 +1,106 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	ociclient "github.com/nom3ad/oci-lb-ingress-controller/pkg/oci/client"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+var (
+	// OCIPingInterval is how long a result of the OCI connectivity check is reused. Probes are usually more frequent than that.
+	OCIPingInterval = 30 * time.Second
+	// RateLimiterSaturationThreshold is how long the OCI rate limiter may reject every request before the controller is reported not ready
+	RateLimiterSaturationThreshold = 1 * time.Minute
+	// ReconcileDeadline is how long a single reconcile may run before the controller is reported not alive. Zero disables the check.
+	ReconcileDeadline = 30 * time.Minute
+)
+
+// OCIConnectivityChecker returns a readiness check that requires a successful authenticated round-trip to OCI
+func OCIConnectivityChecker(ociClient ociclient.Interface, compartmentID string) healthz.Checker {
+	var (
+		mu        sync.Mutex
+		lastCheck time.Time
+		lastErr   error
+	)
+	return func(req *http.Request) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if !lastCheck.IsZero() && time.Since(lastCheck) < OCIPingInterval {
+			return lastErr
+		}
+		ctx, cancel := context.WithTimeout(req.Context(), 10*time.Second)
+		defer cancel()
+		lastErr = ociClient.Ping(ctx, compartmentID)
+		if lastErr != nil {
+			lastErr = errors.Wrap(lastErr, "OCI API is not reachable")
+		}
+		lastCheck = time.Now()
+		return lastErr
+	}
+}
+
+// CacheSyncChecker returns a readiness check that requires informer caches to be synced
+func CacheSyncChecker(c cache.Cache) healthz.Checker {
+	return func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), time.Second)
+		defer cancel()
+		if !c.WaitForCacheSync(ctx) {
+			return errors.New("informer caches are not synced yet")
+		}
+		return nil
+	}
+}
+
+// RateLimiterChecker returns a readiness check that fails if the OCI rate limiter is rejecting every request for too long
+func RateLimiterChecker(rateLimiter ociclient.RateLimiter) healthz.Checker {
+	return func(req *http.Request) error {
+		if d := rateLimiter.SaturatedFor(); d > RateLimiterSaturationThreshold {
+			return fmt.Errorf("OCI rate limiter is saturated for %s", d.Round(time.Second))
+		}
+		return nil
+	}
+}
+
+// ReconcileTracker keeps track of in-flight reconciles, to detect the ones stuck
+type ReconcileTracker struct {
+	mu       sync.Mutex
+	inFlight map[string]time.Time
+}
+
+func NewReconcileTracker() *ReconcileTracker {
+	return &ReconcileTracker{inFlight: map[string]time.Time{}}
+}
+
+// Begin marks a reconcile of key as started. Returned function must be called when it has finished.
+func (t *ReconcileTracker) Begin(key string) func() {
+	t.mu.Lock()
+	t.inFlight[key] = time.Now()
+	t.mu.Unlock()
+	return func() {
+		t.mu.Lock()
+		delete(t.inFlight, key)
+		t.mu.Unlock()
+	}
+}
+
+// Check is a liveness check that fails if a reconcile is running longer than ReconcileDeadline
+func (t *ReconcileTracker) Check(req *http.Request) error {
+	if ReconcileDeadline <= 0 {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key, started := range t.inFlight {
+		if d := time.Since(started); d > ReconcileDeadline {
+			return fmt.Errorf("reconcile of %s is running for %s", key, d.Round(time.Second))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package ingress
+
+import (
+	"context"
+
+	"github.com/nom3ad/oci-lb-ingress-controller/src/apis/v1alpha1"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	networking "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// IngressClassDefaultAnnotation marks the IngressClass used for ingresses without a class
+const IngressClassDefaultAnnotation = "ingressclass.kubernetes.io/is-default-class"
+
+// classResolver matches ingresses against IngressClass objects. Nil until UseIngressClasses() is called, in which
+// case ingresses are matched by OCILoadbalancerIngressClass only.
+var classResolver *ingressClassResolver
+
+type ingressClassResolver struct {
+	reader         k8sclient.Reader
+	controllerName string
+}
+
+// UseIngressClasses makes ingresses to be matched by IngressClass objects having controllerName as spec.controller.
+// Ingresses with the class name OCILoadbalancerIngressClass are still matched if no IngressClass of that name exists.
+func UseIngressClasses(reader k8sclient.Reader, controllerName string) {
+	classResolver = &ingressClassResolver{reader: reader, controllerName: controllerName}
+}
+
+// GetIngressClass returns the IngressClass of this controller the ingress belongs to. Returned class is nil if the
+// ingress is matched by the legacy class name, or doesn't belong to this controller. See IsOCILoadbalancerIngress().
+func GetIngressClass(ctx context.Context, ing *networking.Ingress) (class *networking.IngressClass, ours bool, err error) {
+	className := GetIngressClassName(ing)
+	if classResolver == nil {
+		return nil, className == OCILoadbalancerIngressClass, nil
+	}
+	r := classResolver
+	if className == "" {
+		classes := &networking.IngressClassList{}
+		if err := r.reader.List(ctx, classes); err != nil {
+			return nil, false, errors.Wrap(err, "list ingress classes")
+		}
+		for i := range classes.Items {
+			if classes.Items[i].Annotations[IngressClassDefaultAnnotation] == "true" {
+				// there can be a single default class in cluster. It may belong to another controller.
+				if classes.Items[i].Spec.Controller == r.controllerName {
+					return &classes.Items[i], true, nil
+				}
+				return nil, false, nil
+			}
+		}
+		return nil, false, nil
+	}
+	class = &networking.IngressClass{}
+	if err := r.reader.Get(ctx, types.NamespacedName{Name: className}, class); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, className == OCILoadbalancerIngressClass, nil
+		}
+		return nil, false, errors.Wrapf(err, "get ingress class %q", className)
+	}
+	if class.Spec.Controller != r.controllerName {
+		return nil, false, nil
+	}
+	return class, true, nil
+}
+
+// GetIngressClassParameters returns the IngressClassParameters referenced by the IngressClass of the ingress, or nil if there is none
+func GetIngressClassParameters(ctx context.Context, ing *networking.Ingress) (*v1alpha1.IngressClassParametersSpec, error) {
+	class, _, err := GetIngressClass(ctx, ing)
+	if err != nil || class == nil || class.Spec.Parameters == nil {
+		return nil, err
+	}
+	ref := class.Spec.Parameters
+	if ref.APIGroup == nil || *ref.APIGroup != v1alpha1.GroupVersion.Group || ref.Kind != v1alpha1.IngressClassParametersKind {
+		return nil, errors.Errorf("ingress class %q refers to unsupported parameters %s %q", class.Name, ref.Kind, ref.Name)
+	}
+	if ref.Scope != nil && *ref.Scope != networking.IngressClassParametersReferenceScopeCluster {
+		return nil, errors.Errorf("ingress class %q refers to parameters of scope %q. %s is cluster scoped", class.Name, *ref.Scope, ref.Kind)
+	}
+	params := &v1alpha1.IngressClassParameters{}
+	if err := classResolver.reader.Get(ctx, types.NamespacedName{Name: ref.Name}, params); err != nil {
+		return nil, errors.Wrapf(err, "get %s %q of ingress class %q", ref.Kind, ref.Name, class.Name)
+	}
+	return &params.Spec, nil
+}
+
+// IsOCILoadbalancerIngress returns true if an ingress object has the OCI ingress class. The ingress is matched by class
+// name if its IngressClass can't be resolved, which is logged.
+func IsOCILoadbalancerIngress(ingress *networking.Ingress, logger *zap.Logger) bool {
+	_, ours, err := GetIngressClass(context.Background(), ingress)
+	if err != nil {
+		logger.Sugar().With("ingress", ingress.Namespace+"/"+ingress.Name, zap.Error(err)).Warn("Couldn't resolve ingress class. Matching by name")
+		return GetIngressClassName(ingress) == OCILoadbalancerIngressClass
+	}
+	return ours
+}
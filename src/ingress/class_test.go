@@ -0,0 +1,111 @@
+package ingress
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nom3ad/oci-lb-ingress-controller/src/apis/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetIngressClass(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	apiGroup := v1alpha1.GroupVersion.Group
+	flexMax := 100
+	objects := []runtime.Object{
+		&networking.IngressClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "oci-public", Annotations: map[string]string{IngressClassDefaultAnnotation: "true"}},
+			Spec: networking.IngressClassSpec{
+				Controller: "ingress.beta.kubernetes.io/oci",
+				Parameters: &networking.IngressClassParametersReference{APIGroup: &apiGroup, Kind: v1alpha1.IngressClassParametersKind, Name: "public"},
+			},
+		},
+		&networking.IngressClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx"},
+			Spec:       networking.IngressClassSpec{Controller: "k8s.io/ingress-nginx"},
+		},
+		&v1alpha1.IngressClassParameters{
+			ObjectMeta: metav1.ObjectMeta{Name: "public"},
+			Spec:       v1alpha1.IngressClassParametersSpec{Shape: "flexible", FlexMaxMbps: &flexMax},
+		},
+	}
+	UseIngressClasses(fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objects...).Build(), "ingress.beta.kubernetes.io/oci")
+	defer func() { classResolver = nil }()
+
+	withClass := func(className string) *networking.Ingress {
+		ing := &networking.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"}}
+		if className != "" {
+			ing.Spec.IngressClassName = &className
+		}
+		return ing
+	}
+
+	testCases := []struct {
+		className string
+		ours      bool
+		class     string
+	}{
+		{className: "oci-public", ours: true, class: "oci-public"},
+		{className: "", ours: true, class: "oci-public"}, // default class
+		{className: "nginx", ours: false},
+		{className: OCILoadbalancerIngressClass, ours: true}, // legacy name, without IngressClass object
+		{className: "unknown", ours: false},
+	}
+	for _, tc := range testCases {
+		class, ours, err := GetIngressClass(context.Background(), withClass(tc.className))
+		require.NoError(t, err)
+		assert.Equal(t, tc.ours, ours, "class %q", tc.className)
+		if tc.class == "" {
+			assert.Nil(t, class, "class %q", tc.className)
+		} else if assert.NotNil(t, class, "class %q", tc.className) {
+			assert.Equal(t, tc.class, class.Name)
+		}
+	}
+
+	params, err := GetIngressClassParameters(context.Background(), withClass(""))
+	require.NoError(t, err)
+	require.NotNil(t, params)
+	assert.Equal(t, "flexible", params.Shape)
+	assert.Equal(t, 100, *params.FlexMaxMbps)
+
+	params, err = GetIngressClassParameters(context.Background(), withClass(OCILoadbalancerIngressClass))
+	require.NoError(t, err)
+	assert.Nil(t, params)
+}
+
+// unreachableReader fails every Get, as when the API server can't be reached
+type unreachableReader struct {
+	k8sclient.Reader
+}
+
+func (unreachableReader) Get(ctx context.Context, key types.NamespacedName, obj k8sclient.Object) error {
+	return errors.New("connection refused")
+}
+
+func TestIsOCILoadbalancerIngressFallsBackToClassName(t *testing.T) {
+	UseIngressClasses(unreachableReader{}, "ingress.beta.kubernetes.io/oci")
+	defer func() { classResolver = nil }()
+	core, logs := observer.New(zap.WarnLevel)
+	logger := zap.New(core)
+
+	className := OCILoadbalancerIngressClass
+	ing := &networking.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"}, Spec: networking.IngressSpec{IngressClassName: &className}}
+	assert.True(t, IsOCILoadbalancerIngress(ing, logger))
+	className = "nginx"
+	assert.False(t, IsOCILoadbalancerIngress(ing, logger))
+	require.Equal(t, 2, logs.Len())
+	assert.Equal(t, "default/web", logs.All()[0].ContextMap()["ingress"])
+}
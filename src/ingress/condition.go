@@ -0,0 +1,139 @@
+package ingress
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// AST of the OCI routing policy condition language.
+// https://docs.oracle.com/en-us/iaas/Content/Balance/Concepts/routing_policy_conditions.htm
+//
+//	condition := ["not"] (("all" | "any") "(" condition {"," condition} ")" | predicate)
+//	predicate := attribute ["[" literal "]"] ["not"] matcher (literal | "(" literal {"," literal} ")")
+//	matcher   := "eq" | "sw" | "ew" | "cw" | "in"
+//	literal   := string | "(" "i" string ")"
+//
+// String() of nodes prints them in normal form, which parses back to the same AST.
+
+// Condition is a node of a condition AST: *LogicalCondition, *NotCondition or *Predicate
+type Condition interface {
+	String() string
+}
+
+// Logical operators
+const (
+	ConditionAll = "all"
+	ConditionAny = "any"
+)
+
+// Matchers of predicates
+const (
+	MatcherEqual      = "eq"
+	MatcherStartsWith = "sw"
+	MatcherEndsWith   = "ew"
+	MatcherContains   = "cw"
+	MatcherIn         = "in"
+)
+
+// LogicalCondition is met if all, or any, of its conditions are
+type LogicalCondition struct {
+	Operator   string // ConditionAll or ConditionAny
+	Conditions []Condition
+}
+
+// NotCondition is met if its condition is not. Negated predicates are Predicates.
+type NotCondition struct {
+	Condition *LogicalCondition
+}
+
+// Predicate matches an attribute of the request, eg: http.request.headers[(i 'Host')] eq (i 'example.com')
+type Predicate struct {
+	Attribute string   // eg: http.request.url.path
+	Key       *Literal // of map attributes, eg: headers
+	Negated   bool
+	Matcher   string
+	Values    []Literal // more than one for MatcherIn only
+}
+
+// Literal is a string, compared case-insensitively if it is (i '...')
+type Literal struct {
+	Value           string
+	CaseInsensitive bool
+}
+
+func (c *LogicalCondition) String() string {
+	conditions := make([]string, len(c.Conditions))
+	for i, condition := range c.Conditions {
+		conditions[i] = condition.String()
+	}
+	return c.Operator + "(" + strings.Join(conditions, ", ") + ")"
+}
+
+func (c *NotCondition) String() string {
+	return "not " + c.Condition.String()
+}
+
+func (p *Predicate) String() string {
+	var sb strings.Builder
+	sb.WriteString(p.Attribute)
+	if p.Key != nil {
+		sb.WriteString("[" + p.Key.String() + "]")
+	}
+	if p.Negated {
+		sb.WriteString(" not")
+	}
+	sb.WriteString(" " + p.Matcher + " ")
+	if p.Matcher == MatcherIn {
+		values := make([]string, len(p.Values))
+		for i, value := range p.Values {
+			values[i] = value.String()
+		}
+		sb.WriteString("(" + strings.Join(values, ", ") + ")")
+	} else {
+		sb.WriteString(p.Values[0].String())
+	}
+	return sb.String()
+}
+
+func (l Literal) String() string {
+	quoted := "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(l.Value) + "'"
+	if l.CaseInsensitive {
+		return "(i " + quoted + ")"
+	}
+	return quoted
+}
+
+// createKeyEqualCondition returns the condition met if the value at key of the map attribute, eg: headers, equals value
+func createKeyEqualCondition(attribute string, key Literal, value string) string {
+	return (&Predicate{Attribute: attribute, Key: &key, Matcher: MatcherEqual, Values: []Literal{{Value: value}}}).String()
+}
+
+// allOf returns the condition met if all the conditions are. Nested all() are flattened.
+func allOf(conditions ...Condition) Condition {
+	all := &LogicalCondition{Operator: ConditionAll}
+	for _, condition := range conditions {
+		if logical, ok := condition.(*LogicalCondition); ok && logical.Operator == ConditionAll {
+			all.Conditions = append(all.Conditions, logical.Conditions...)
+		} else {
+			all.Conditions = append(all.Conditions, condition)
+		}
+	}
+	if len(all.Conditions) == 1 {
+		return all.Conditions[0]
+	}
+	return all
+}
+
+// combineConditions parses the conditions and returns the one met if all of them are, in normal form
+func combineConditions(conditions ...string) (string, error) {
+	var parsed []Condition
+	for _, condition := range conditions {
+		c, err := ParseCondition(condition)
+		if err != nil {
+			return "", errors.Wrapf(err, "Invalid condition %q", condition)
+		}
+		parsed = append(parsed, c)
+	}
+	return allOf(parsed...).String(), nil
+}
@@ -0,0 +1,271 @@
+package ingress
+
+import (
+	"fmt"
+	"strings"
+)
+
+type conditionTokenKind int
+
+const (
+	tokenEOF conditionTokenKind = iota
+	tokenIdent
+	tokenString
+	tokenLParen
+	tokenRParen
+	tokenLBracket
+	tokenRBracket
+	tokenComma
+)
+
+func (k conditionTokenKind) String() string {
+	return [...]string{"end of condition", "identifier", "string", "'('", "')'", "'['", "']'", "','"}[k]
+}
+
+type conditionToken struct {
+	kind  conditionTokenKind
+	value string
+	pos   int // 1-based
+}
+
+func (t conditionToken) String() string {
+	switch t.kind {
+	case tokenIdent:
+		return fmt.Sprintf("%q", t.value)
+	case tokenString:
+		return fmt.Sprintf("string %q", t.value)
+	}
+	return t.kind.String()
+}
+
+// ConditionSyntaxError is an error of ParseCondition at a position (1-based) of the condition
+type ConditionSyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *ConditionSyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Pos, e.Msg)
+}
+
+func isIdentChar(c byte, first bool) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || !first && (c >= '0' && c <= '9' || c == '.' || c == '-')
+}
+
+// lexCondition splits the condition into tokens
+func lexCondition(condition string) ([]conditionToken, error) {
+	var tokens []conditionToken
+	for i := 0; i < len(condition); {
+		c := condition[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.IndexByte("()[],", c) >= 0:
+			kind := map[byte]conditionTokenKind{'(': tokenLParen, ')': tokenRParen, '[': tokenLBracket, ']': tokenRBracket, ',': tokenComma}[c]
+			tokens = append(tokens, conditionToken{kind: kind, value: string(c), pos: i + 1})
+			i++
+		case c == '\'':
+			start := i
+			var value strings.Builder
+			for i++; ; i++ {
+				if i >= len(condition) {
+					return nil, &ConditionSyntaxError{Pos: start + 1, Msg: "unterminated string"}
+				}
+				if condition[i] == '\\' && i+1 < len(condition) {
+					i++
+				} else if condition[i] == '\'' {
+					break
+				}
+				value.WriteByte(condition[i])
+			}
+			i++
+			tokens = append(tokens, conditionToken{kind: tokenString, value: value.String(), pos: start + 1})
+		case isIdentChar(c, true):
+			start := i
+			for i < len(condition) && isIdentChar(condition[i], false) {
+				i++
+			}
+			tokens = append(tokens, conditionToken{kind: tokenIdent, value: condition[start:i], pos: start + 1})
+		default:
+			return nil, &ConditionSyntaxError{Pos: i + 1, Msg: fmt.Sprintf("unexpected character %q", c)}
+		}
+	}
+	return append(tokens, conditionToken{kind: tokenEOF, pos: len(condition) + 1}), nil
+}
+
+type conditionParser struct {
+	tokens []conditionToken
+	next   int
+}
+
+// ParseCondition parses a routing policy condition. Errors are *ConditionSyntaxError.
+func ParseCondition(condition string) (Condition, error) {
+	tokens, err := lexCondition(condition)
+	if err != nil {
+		return nil, err
+	}
+	p := &conditionParser{tokens: tokens}
+	c, err := p.parseCondition()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(tokenEOF); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (p *conditionParser) peek(offset int) conditionToken {
+	if p.next+offset >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.next+offset]
+}
+
+func (p *conditionParser) consume() conditionToken {
+	token := p.peek(0)
+	if token.kind != tokenEOF {
+		p.next++
+	}
+	return token
+}
+
+func (p *conditionParser) errorf(token conditionToken, format string, args ...interface{}) error {
+	return &ConditionSyntaxError{Pos: token.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *conditionParser) expect(kind conditionTokenKind) (conditionToken, error) {
+	token := p.consume()
+	if token.kind != kind {
+		return token, p.errorf(token, "expected %s, found %s", kind, token)
+	}
+	return token, nil
+}
+
+func (p *conditionParser) isKeyword(offset int, keyword string) bool {
+	token := p.peek(offset)
+	return token.kind == tokenIdent && token.value == keyword
+}
+
+func (p *conditionParser) parseCondition() (Condition, error) {
+	negated := p.isKeyword(0, "not")
+	if negated {
+		p.consume()
+	}
+	if (p.isKeyword(0, ConditionAll) || p.isKeyword(0, ConditionAny)) && p.peek(1).kind == tokenLParen {
+		logical, err := p.parseLogicalCondition()
+		if err != nil {
+			return nil, err
+		}
+		if negated {
+			return &NotCondition{Condition: logical}, nil
+		}
+		return logical, nil
+	}
+	predicate, err := p.parsePredicate()
+	if err != nil {
+		return nil, err
+	}
+	predicate.Negated = predicate.Negated != negated
+	return predicate, nil
+}
+
+func (p *conditionParser) parseLogicalCondition() (*LogicalCondition, error) {
+	logical := &LogicalCondition{Operator: p.consume().value}
+	p.consume() // (
+	for {
+		c, err := p.parseCondition()
+		if err != nil {
+			return nil, err
+		}
+		logical.Conditions = append(logical.Conditions, c)
+		token := p.consume()
+		if token.kind == tokenRParen {
+			return logical, nil
+		}
+		if token.kind != tokenComma {
+			return nil, p.errorf(token, "expected ',' or ')', found %s", token)
+		}
+	}
+}
+
+func (p *conditionParser) parsePredicate() (*Predicate, error) {
+	attribute, err := p.expect(tokenIdent)
+	if err != nil {
+		return nil, p.errorf(attribute, "expected a condition, found %s", attribute)
+	}
+	predicate := &Predicate{Attribute: attribute.value}
+	if p.peek(0).kind == tokenLBracket {
+		p.consume()
+		key, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		predicate.Key = &key
+		if _, err := p.expect(tokenRBracket); err != nil {
+			return nil, err
+		}
+	}
+	if p.isKeyword(0, "not") {
+		p.consume()
+		predicate.Negated = true
+	}
+	matcher := p.consume()
+	switch matcher.value {
+	case MatcherEqual, MatcherStartsWith, MatcherEndsWith, MatcherContains:
+		if matcher.kind != tokenIdent {
+			break
+		}
+		predicate.Matcher = matcher.value
+		value, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		predicate.Values = []Literal{value}
+		return predicate, nil
+	case MatcherIn:
+		if matcher.kind != tokenIdent {
+			break
+		}
+		predicate.Matcher = matcher.value
+		if _, err := p.expect(tokenLParen); err != nil {
+			return nil, err
+		}
+		for {
+			value, err := p.parseLiteral()
+			if err != nil {
+				return nil, err
+			}
+			predicate.Values = append(predicate.Values, value)
+			token := p.consume()
+			if token.kind == tokenRParen {
+				return predicate, nil
+			}
+			if token.kind != tokenComma {
+				return nil, p.errorf(token, "expected ',' or ')', found %s", token)
+			}
+		}
+	}
+	return nil, p.errorf(matcher, "expected a matcher (eq, sw, ew, cw or in), found %s", matcher)
+}
+
+func (p *conditionParser) parseLiteral() (Literal, error) {
+	token := p.consume()
+	switch token.kind {
+	case tokenString:
+		return Literal{Value: token.value}, nil
+	case tokenLParen:
+		if i := p.consume(); i.kind != tokenIdent || i.value != "i" {
+			return Literal{}, p.errorf(i, "expected \"i\", found %s", i)
+		}
+		value, err := p.expect(tokenString)
+		if err != nil {
+			return Literal{}, err
+		}
+		if _, err := p.expect(tokenRParen); err != nil {
+			return Literal{}, err
+		}
+		return Literal{Value: value.value, CaseInsensitive: true}, nil
+	}
+	return Literal{}, p.errorf(token, "expected a string, found %s", token)
+}
@@ -0,0 +1,66 @@
+package ingress
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCondition(t *testing.T) {
+	expectations := []struct {
+		condition  string
+		normalized string
+	}{
+		{condition: "http.request.url.path sw '/'", normalized: "http.request.url.path sw '/'"},
+		{condition: "all(http.request.url.path sw '/a',http.request.url.path ew 'b')", normalized: "all(http.request.url.path sw '/a', http.request.url.path ew 'b')"},
+		{condition: "any( all(http.request.url.path sw '/a'), not any(http.request.url.query['x'] eq 'a,b', http.request.cookies['c'] not cw 'z') )", normalized: "any(all(http.request.url.path sw '/a'), not any(http.request.url.query['x'] eq 'a,b', http.request.cookies['c'] not cw 'z'))"},
+		{condition: "not http.request.headers[(i 'Host')] eq (i 'example.com')", normalized: "http.request.headers[(i 'Host')] not eq (i 'example.com')"},
+		{condition: "http.request.url.path in ('/a',(i '/B'))", normalized: "http.request.url.path in ('/a', (i '/B'))"},
+		{condition: `http.request.url.path eq 'it\'s'`, normalized: `http.request.url.path eq 'it\'s'`},
+	}
+	for _, it := range expectations {
+		c, err := ParseCondition(it.condition)
+		if assert.NoError(t, err, "for condition: %s", it.condition) {
+			assert.Equal(t, it.normalized, c.String(), "for condition: %s", it.condition)
+			reparsed, err := ParseCondition(c.String())
+			require.NoError(t, err)
+			assert.Equal(t, c, reparsed, "normal form does not parse back for condition: %s", it.condition)
+		}
+	}
+}
+
+func TestParseConditionErrors(t *testing.T) {
+	expectations := map[string]string{
+		"":                                   "syntax error at position 1: expected a condition, found end of condition",
+		"http.request.url.path":              "syntax error at position 22: expected a matcher (eq, sw, ew, cw or in), found end of condition",
+		"http.request.url.path is '/'":       `syntax error at position 23: expected a matcher (eq, sw, ew, cw or in), found "is"`,
+		"all(http.request.url.path sw '/'":   "syntax error at position 33: expected ',' or ')', found end of condition",
+		"http.request.url.path eq '/":        "syntax error at position 26: unterminated string",
+		"http.request.url.path eq (x '/')":   `syntax error at position 27: expected "i", found "x"`,
+		"http.request.url.path eq '/' extra": `syntax error at position 30: expected end of condition, found "extra"`,
+		"http.request.url.path eq '/' && x":  `syntax error at position 30: unexpected character '&'`,
+		"http.request.url.path in '/'":       "syntax error at position 26: expected '(', found string \"/\"",
+		"http.request.cookies['c' eq 'v'":    `syntax error at position 26: expected ']', found "eq"`,
+	}
+	for condition, message := range expectations {
+		_, err := ParseCondition(condition)
+		if assert.Error(t, err, "for condition: %s", condition) {
+			assert.IsType(t, &ConditionSyntaxError{}, err)
+			assert.Equal(t, message, err.Error(), "for condition: %s", condition)
+		}
+	}
+}
+
+func TestCombineConditions(t *testing.T) {
+	condition, err := combineConditions(createHostnameCondition("example.com"), "all(http.request.url.path sw '/a', http.request.url.path ew 'z')", "any(http.request.url.path eq '/b')")
+	require.NoError(t, err)
+	assert.Equal(t, "all("+createHostnameCondition("example.com")+", http.request.url.path sw '/a', http.request.url.path ew 'z', any(http.request.url.path eq '/b'))", condition)
+
+	condition, err = combineConditions("all(http.request.url.path sw '/a')")
+	require.NoError(t, err)
+	assert.Equal(t, "http.request.url.path sw '/a'", condition)
+
+	_, err = combineConditions("http.request.url.path sw '/a'", "any(")
+	assert.Error(t, err)
+}
@@ -0,0 +1,896 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	. "github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci"
+	ociclient "github.com/nom3ad/oci-lb-ingress-controller/pkg/oci/client"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/apis/v1alpha1"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/configholder"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/utils"
+	"github.com/oracle/oci-go-sdk/v46/loadbalancer"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// GatewayControllerName is the controller name of GatewayClasses handled by this controller. Route status is reported under it.
+var GatewayControllerName = "ingress.beta.kubernetes.io/oci"
+
+// GatewayFinalizer is added to every gateway managed by this controller. See IngressFinalizer.
+const GatewayFinalizer = "ingress.beta.kubernetes.io/oci-gateway-cleanup"
+
+const (
+	gatewayKind   = "Gateway"
+	httpRouteKind = "HTTPRoute"
+	serviceKind   = "Service"
+	secretKind    = "Secret"
+)
+
+// maxBackendWeight is the largest weight of an OCI backend. Weights of backendRefs are scaled down to it.
+const maxBackendWeight = 100
+
+// GatewayLBSpec is the load balancer spec of a gateway, along with the status of its listeners and of the routes attached to them
+type GatewayLBSpec struct {
+	*IngressLBSpec
+
+	ListenerStatuses []gatewayv1beta1.ListenerStatus
+	// RouteParents holds the status of every route for each of its parent references to the gateway
+	RouteParents map[types.NamespacedName][]gatewayv1beta1.RouteParentStatus
+}
+
+// GetGatewayLoadBalancerName gets the name of the load balancer of a gateway
+func GetGatewayLoadBalancerName(namespace, name string) string {
+	// Names of load balancers of ingresses have "_", which gateway names can't have. Namespaces can't have ".".
+	return getLoadBalancerNamePrefix() + "gateway--" + namespace + "." + name
+}
+
+// IsOCIGatewayClass returns true if the GatewayClass is handled by this controller
+func IsOCIGatewayClass(class *gatewayv1beta1.GatewayClass) bool {
+	return string(class.Spec.ControllerName) == GatewayControllerName
+}
+
+// GetGatewayClassParameters returns the IngressClassParameters referenced by the GatewayClass, or nil if there is none
+func GetGatewayClassParameters(ctx context.Context, reader k8sclient.Reader, class *gatewayv1beta1.GatewayClass) (*v1alpha1.IngressClassParametersSpec, error) {
+	ref := class.Spec.ParametersRef
+	if ref == nil {
+		return nil, nil
+	}
+	if string(ref.Group) != v1alpha1.GroupVersion.Group || string(ref.Kind) != v1alpha1.IngressClassParametersKind {
+		return nil, errors.Errorf("gateway class %q refers to unsupported parameters %s %q", class.Name, ref.Kind, ref.Name)
+	}
+	if ref.Namespace != nil {
+		return nil, errors.Errorf("gateway class %q refers to parameters in namespace %q. %s is cluster scoped", class.Name, *ref.Namespace, ref.Kind)
+	}
+	params := &v1alpha1.IngressClassParameters{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: ref.Name}, params); err != nil {
+		return nil, errors.Wrapf(err, "get %s %q of gateway class %q", ref.Kind, ref.Name, class.Name)
+	}
+	return &params.Spec, nil
+}
+
+// GetRouteGateways returns the gateways the route refers to as parents
+func GetRouteGateways(route *gatewayv1beta1.HTTPRoute) []types.NamespacedName {
+	var gateways []types.NamespacedName
+	for _, ref := range route.Spec.ParentRefs {
+		if ref.Group != nil && *ref.Group != gatewayv1beta1.GroupName || ref.Kind != nil && *ref.Kind != gatewayKind {
+			continue
+		}
+		namespace := route.Namespace
+		if ref.Namespace != nil {
+			namespace = string(*ref.Namespace)
+		}
+		gateways = append(gateways, types.NamespacedName{Namespace: namespace, Name: string(ref.Name)})
+	}
+	return gateways
+}
+
+// RefersToGateway tells whether the parent reference of a route in the namespace refers to the gateway
+func RefersToGateway(ref gatewayv1beta1.ParentReference, namespace string, gw *gatewayv1beta1.Gateway) bool {
+	route := &gatewayv1beta1.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Namespace: namespace}}
+	route.Spec.ParentRefs = []gatewayv1beta1.ParentReference{ref}
+	gateways := GetRouteGateways(route)
+	return len(gateways) == 1 && gateways[0] == types.NamespacedName{Namespace: gw.Namespace, Name: gw.Name}
+}
+
+// NewGatewayLBSpec derives the load balancer spec of a gateway, from its listeners and the HTTPRoutes attached to them.
+// Every gateway listener maps to an OCI listener with a routing policy of its own. Routes which can't be attached, or
+// use features OCI load balancers lack, are left out and reported in route status. Only the gateway itself fails the build.
+func NewGatewayLBSpec(config configholder.ConfigHolder, gw *gatewayv1beta1.Gateway, routes []gatewayv1beta1.HTTPRoute, ociClient ociclient.Interface, k8sClient k8sclient.Client, logger *zap.Logger) (*GatewayLBSpec, error) {
+	internal, err := IsInternalLB(gw)
+	if err != nil {
+		return nil, err
+	}
+	shape, flexShapeMinMbps, flexShapeMaxMbps, err := getLBShape(config, gw)
+	if err != nil {
+		return nil, err
+	}
+	loadbalancerIP, err := getGatewayLoadBalancerIP(gw)
+	if err != nil {
+		return nil, err
+	}
+	loadbalancerPolicy, err := getLoadBalancerPolicy(gw)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	backendNodes, err := listBackendNodes(ctx, k8sClient, gw)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &gatewayTranslator{
+		ctx:              ctx,
+		gw:               gw,
+		k8sClient:        k8sClient,
+		logger:           logger,
+		policy:           loadbalancerPolicy,
+		backendSetOwners: map[string]string{},
+		namespaces:       map[string]*corev1.Namespace{},
+		spec: &GatewayLBSpec{
+			IngressLBSpec: &IngressLBSpec{
+				Gateway:         gw,
+				Services:        map[string]*corev1.Service{},
+				RoutingPolicies: map[string]loadbalancer.RoutingPolicy{},
+				RuleSets:        map[string]loadbalancer.RuleSetDetails{},
+				HostnameDetails: map[string]loadbalancer.HostnameDetails{},
+				Certificates:    map[string]loadbalancer.CertificateDetails{},
+			},
+			RouteParents: map[types.NamespacedName][]gatewayv1beta1.RouteParentStatus{},
+		},
+	}
+	for i := range backendNodes {
+		t.nodes = append(t.nodes, &backendNodes[i])
+	}
+	t.spec.Listeners = map[string]loadbalancer.ListenerDetails{}
+	t.spec.BackendSets = map[string]loadbalancer.BackendSetDetails{DummyBackendSetName: createDummyBackendSetDetails(loadbalancerPolicy)}
+
+	for _, listener := range gw.Spec.Listeners {
+		t.addListener(listener)
+	}
+	sorted := make([]*gatewayv1beta1.HTTPRoute, len(routes))
+	for i := range routes {
+		sorted[i] = &routes[i]
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return routePrecedes(sorted[i], sorted[j]) })
+	for _, route := range sorted {
+		if err := t.attachRoute(route); err != nil {
+			return nil, errors.Wrapf(err, "attach route %s/%s", route.Namespace, route.Name)
+		}
+	}
+	t.buildListeners()
+
+	subnetIds, err := getLoadBalancerSubnetIds(config, gw, ociClient, logger)
+	if err != nil {
+		return nil, err
+	}
+	t.spec.Name = GetGatewayLoadBalancerName(gw.Namespace, gw.Name)
+	t.spec.Subnets = subnetIds
+	t.spec.Shape = shape
+	t.spec.FlexMin = flexShapeMinMbps
+	t.spec.FlexMax = flexShapeMaxMbps
+	t.spec.Internal = internal
+	t.spec.LoadBalancerIP = loadbalancerIP
+	t.spec.NetworkSecurityGroupIds = config.GetNetworkSecurityGroupIds()
+	t.spec.SecurityListManager = NewSecurityListManagerNOOP() // TODO
+	return t.spec, nil
+}
+
+// getGatewayLoadBalancerIP returns the reserved IP requested in gateway addresses, or "" if there is none
+func getGatewayLoadBalancerIP(gw *gatewayv1beta1.Gateway) (string, error) {
+	if len(gw.Spec.Addresses) > 1 {
+		return "", errors.Errorf("a single address is supported. Got %d", len(gw.Spec.Addresses))
+	}
+	for _, address := range gw.Spec.Addresses {
+		if address.Type != nil && *address.Type != gatewayv1beta1.IPAddressType {
+			return "", errors.Errorf("address type %s is not supported", *address.Type)
+		}
+		if net.ParseIP(address.Value) == nil {
+			return "", errors.Errorf("invalid IP address %q", address.Value)
+		}
+		return address.Value, nil
+	}
+	return "", nil
+}
+
+// routePrecedes orders routes by age, oldest first. Older routes win ties between rules.
+func routePrecedes(route, other *gatewayv1beta1.HTTPRoute) bool {
+	if t, o := route.CreationTimestamp, other.CreationTimestamp; !t.Equal(&o) {
+		return t.Before(&o)
+	}
+	return route.Namespace+"/"+route.Name < other.Namespace+"/"+other.Name
+}
+
+type gatewayTranslator struct {
+	ctx       context.Context
+	gw        *gatewayv1beta1.Gateway
+	k8sClient k8sclient.Client
+	logger    *zap.Logger
+	nodes     []*corev1.Node
+	policy    string
+
+	spec             *GatewayLBSpec
+	listeners        []*gatewayListener
+	backendSetOwners map[string]string // backend set name -> "<namespace>/<name>" of the service
+	namespaces       map[string]*corev1.Namespace
+}
+
+// gatewayListener is a listener of the gateway, along with the rules of the routes attached to it
+type gatewayListener struct {
+	listener  gatewayv1beta1.Listener
+	hostname  string // "" matches any host
+	ssl       *loadbalancer.SslConfigurationDetails
+	ready     bool
+	status    int // index in ListenerStatuses
+	rules     []gatewayRule
+	ruleNames sets.String
+	routes    sets.String
+}
+
+// gatewayRule is a routing rule, or a redirect rule, derived from a match of an HTTPRoute rule. Fields other than
+// routing and redirect are what rules are ordered by. See precedes().
+type gatewayRule struct {
+	routing  *loadbalancer.RoutingRule
+	redirect *loadbalancer.RedirectRule
+
+	hostConditioned bool
+	exactPath       bool
+	pathLength      int
+	headers         int
+	queryParams     int
+	route           *gatewayv1beta1.HTTPRoute
+	rule, match     int
+}
+
+// precedes orders rules as the Gateway API requires. Rules are evaluated in order by OCI, and the first match wins.
+func (r *gatewayRule) precedes(o *gatewayRule) bool {
+	if r.hostConditioned != o.hostConditioned {
+		return r.hostConditioned
+	}
+	if r.exactPath != o.exactPath {
+		return r.exactPath
+	}
+	if r.pathLength != o.pathLength {
+		return r.pathLength > o.pathLength
+	}
+	if r.headers != o.headers {
+		return r.headers > o.headers
+	}
+	if r.queryParams != o.queryParams {
+		return r.queryParams > o.queryParams
+	}
+	if r.route != o.route {
+		return routePrecedes(r.route, o.route)
+	}
+	if r.rule != o.rule {
+		return r.rule < o.rule
+	}
+	return r.match < o.match
+}
+
+func (t *gatewayTranslator) listenerCondition(conditionType gatewayv1beta1.ListenerConditionType, status bool, reason gatewayv1beta1.ListenerConditionReason, message string) metav1.Condition {
+	return metav1.Condition{Type: string(conditionType), Status: conditionStatus(status), Reason: string(reason), Message: message, ObservedGeneration: t.gw.Generation}
+}
+
+func routeCondition(route *gatewayv1beta1.HTTPRoute, conditionType gatewayv1beta1.RouteConditionType, status bool, reason gatewayv1beta1.RouteConditionReason, message string) metav1.Condition {
+	return metav1.Condition{Type: string(conditionType), Status: conditionStatus(status), Reason: string(reason), Message: message, ObservedGeneration: route.Generation}
+}
+
+func conditionStatus(status bool) metav1.ConditionStatus {
+	if status {
+		return metav1.ConditionTrue
+	}
+	return metav1.ConditionFalse
+}
+
+// addListener validates the listener and records its status. Only ready listeners get routes attached.
+func (t *gatewayTranslator) addListener(listener gatewayv1beta1.Listener) {
+	gl := &gatewayListener{listener: listener, status: len(t.spec.ListenerStatuses), ruleNames: sets.NewString(), routes: sets.NewString()}
+	if listener.Hostname != nil {
+		gl.hostname = string(*listener.Hostname)
+	}
+	status := gatewayv1beta1.ListenerStatus{Name: listener.Name, SupportedKinds: []gatewayv1beta1.RouteGroupKind{}}
+	if acceptsHTTPRoutes(listener) {
+		group := gatewayv1beta1.Group(gatewayv1beta1.GroupName)
+		status.SupportedKinds = append(status.SupportedKinds, gatewayv1beta1.RouteGroupKind{Group: &group, Kind: httpRouteKind})
+	}
+
+	detached := t.listenerCondition(gatewayv1beta1.ListenerConditionDetached, false, gatewayv1beta1.ListenerReasonAttached, "")
+	conflicted := t.listenerCondition(gatewayv1beta1.ListenerConditionConflicted, false, gatewayv1beta1.ListenerReasonNoConflicts, "")
+	resolvedRefs := t.listenerCondition(gatewayv1beta1.ListenerConditionResolvedRefs, true, gatewayv1beta1.ListenerReasonResolvedRefs, "")
+	if reason, err := t.checkListenerProtocol(gl); err != nil {
+		detached = t.listenerCondition(gatewayv1beta1.ListenerConditionDetached, true, reason, err.Error())
+	} else if reason, err := t.checkListenerConflicts(gl); err != nil {
+		conflicted = t.listenerCondition(gatewayv1beta1.ListenerConditionConflicted, true, reason, err.Error())
+	} else if len(status.SupportedKinds) == 0 {
+		resolvedRefs = t.listenerCondition(gatewayv1beta1.ListenerConditionResolvedRefs, false, gatewayv1beta1.ListenerReasonInvalidRouteKinds, "only HTTPRoute is supported")
+	} else if reason, err := t.resolveListenerCertificate(gl); err != nil {
+		resolvedRefs = t.listenerCondition(gatewayv1beta1.ListenerConditionResolvedRefs, false, reason, err.Error())
+	} else {
+		gl.ready = true
+	}
+	ready := t.listenerCondition(gatewayv1beta1.ListenerConditionReady, true, gatewayv1beta1.ListenerReasonReady, "")
+	if !gl.ready {
+		ready = t.listenerCondition(gatewayv1beta1.ListenerConditionReady, false, gatewayv1beta1.ListenerReasonInvalid, "see other conditions")
+	}
+	status.Conditions = []metav1.Condition{detached, conflicted, resolvedRefs, ready}
+	t.spec.ListenerStatuses = append(t.spec.ListenerStatuses, status)
+	t.listeners = append(t.listeners, gl)
+}
+
+func acceptsHTTPRoutes(listener gatewayv1beta1.Listener) bool {
+	if listener.AllowedRoutes == nil || len(listener.AllowedRoutes.Kinds) == 0 {
+		return true
+	}
+	for _, kind := range listener.AllowedRoutes.Kinds {
+		if kind.Kind == httpRouteKind && (kind.Group == nil || *kind.Group == gatewayv1beta1.GroupName) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *gatewayTranslator) checkListenerProtocol(gl *gatewayListener) (gatewayv1beta1.ListenerConditionReason, error) {
+	switch gl.listener.Protocol {
+	case gatewayv1beta1.HTTPProtocolType:
+		return "", nil
+	case gatewayv1beta1.HTTPSProtocolType:
+		if tls := gl.listener.TLS; tls != nil && tls.Mode != nil && *tls.Mode != gatewayv1beta1.TLSModeTerminate {
+			return gatewayv1beta1.ListenerReasonUnsupportedProtocol, errors.Errorf("TLS mode %s is not supported", *tls.Mode)
+		}
+		return "", nil
+	}
+	return gatewayv1beta1.ListenerReasonUnsupportedProtocol, errors.Errorf("protocol %s is not supported", gl.listener.Protocol)
+}
+
+// checkListenerConflicts checks the listener against the ready ones before it. OCI listeners of a port share the protocol.
+// A single one may serve a hostname on a port, or any hostname.
+func (t *gatewayTranslator) checkListenerConflicts(gl *gatewayListener) (gatewayv1beta1.ListenerConditionReason, error) {
+	for _, other := range t.listeners {
+		if !other.ready || other.listener.Port != gl.listener.Port {
+			continue
+		}
+		if other.listener.Protocol != gl.listener.Protocol {
+			return gatewayv1beta1.ListenerReasonProtocolConflict, errors.Errorf("port %d is used with protocol %s by listener %q", gl.listener.Port, other.listener.Protocol, other.listener.Name)
+		}
+		if other.hostname == gl.hostname {
+			return gatewayv1beta1.ListenerReasonHostnameConflict, errors.Errorf("hostname %q on port %d is served by listener %q", gl.hostname, gl.listener.Port, other.listener.Name)
+		}
+	}
+	return "", nil
+}
+
+// resolveListenerCertificate loads the certificate of an HTTPS listener. Only the first certificate reference is used.
+func (t *gatewayTranslator) resolveListenerCertificate(gl *gatewayListener) (gatewayv1beta1.ListenerConditionReason, error) {
+	if gl.listener.Protocol != gatewayv1beta1.HTTPSProtocolType {
+		return "", nil
+	}
+	if gl.listener.TLS == nil || len(gl.listener.TLS.CertificateRefs) == 0 {
+		return gatewayv1beta1.ListenerReasonInvalidCertificateRef, errors.New("HTTPS listener requires a certificate reference")
+	}
+	ref := gl.listener.TLS.CertificateRefs[0]
+	if ref.Group != nil && *ref.Group != "" || ref.Kind != nil && *ref.Kind != secretKind {
+		return gatewayv1beta1.ListenerReasonInvalidCertificateRef, errors.Errorf("certificate reference of kind %v is not supported", ref.Kind)
+	}
+	if ref.Namespace != nil && string(*ref.Namespace) != t.gw.Namespace {
+		return gatewayv1beta1.ListenerReasonRefNotPermitted, errors.Errorf("secret %s/%s is in another namespace. ReferenceGrants are not supported", *ref.Namespace, ref.Name)
+	}
+	ssl, err := createSSLConfigDetails(t.ctx, t.gw.Namespace, string(ref.Name), t.spec.Certificates, t.k8sClient, t.logger)
+	if err != nil {
+		return gatewayv1beta1.ListenerReasonInvalidCertificateRef, err
+	}
+	gl.ssl = ssl
+	return "", nil
+}
+
+// attachRoute attaches the route to the listeners its parent references to the gateway select, and records the outcome
+func (t *gatewayTranslator) attachRoute(route *gatewayv1beta1.HTTPRoute) error {
+	namespacedName := types.NamespacedName{Namespace: route.Namespace, Name: route.Name}
+	for _, ref := range route.Spec.ParentRefs {
+		if !RefersToGateway(ref, route.Namespace, t.gw) {
+			continue
+		}
+		conditions, err := t.attachRouteToParent(route, ref)
+		if err != nil {
+			return err
+		}
+		t.spec.RouteParents[namespacedName] = append(t.spec.RouteParents[namespacedName], gatewayv1beta1.RouteParentStatus{
+			ParentRef:      ref,
+			ControllerName: gatewayv1beta1.GatewayController(GatewayControllerName),
+			Conditions:     conditions,
+		})
+	}
+	return nil
+}
+
+func (t *gatewayTranslator) attachRouteToParent(route *gatewayv1beta1.HTTPRoute, ref gatewayv1beta1.ParentReference) ([]metav1.Condition, error) {
+	backendSets, resolvedRefs, reason, err := t.resolveRouteBackends(route)
+	notAccepted := func(reason gatewayv1beta1.RouteConditionReason, message string) []metav1.Condition {
+		return []metav1.Condition{routeCondition(route, gatewayv1beta1.RouteConditionAccepted, false, reason, message), resolvedRefs}
+	}
+	if err != nil && reason != "" {
+		return notAccepted(reason, err.Error()), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var listeners []*gatewayListener
+	var listenerRules [][]gatewayRule
+	reason, message := gatewayv1beta1.RouteReasonNotAllowedByListeners, "no ready listener of the gateway matches the parent reference"
+	for _, gl := range t.listeners {
+		if ref.SectionName != nil && *ref.SectionName != gl.listener.Name || ref.Port != nil && *ref.Port != gl.listener.Port || !gl.ready {
+			continue
+		}
+		if allowed, err := t.allowsRoute(gl, route); err != nil || !allowed {
+			if err != nil {
+				message = err.Error()
+			} else {
+				message = fmt.Sprintf("listener %q does not allow routes from namespace %q", gl.listener.Name, route.Namespace)
+			}
+			continue
+		}
+		hosts := intersectHostnames(gl.hostname, route.Spec.Hostnames)
+		if len(hosts) == 0 {
+			reason, message = gatewayv1beta1.RouteReasonNoMatchingListenerHostname, fmt.Sprintf("no hostname matches hostname %q of listener %q", gl.hostname, gl.listener.Name)
+			continue
+		}
+		rules, err := t.translateRouteRules(route, gl, hosts, backendSets)
+		if err != nil {
+			return notAccepted(gatewayv1beta1.RouteReasonUnsupportedValue, err.Error()), nil
+		}
+		listeners = append(listeners, gl)
+		listenerRules = append(listenerRules, rules)
+	}
+	if len(listeners) == 0 {
+		return notAccepted(reason, message), nil
+	}
+	for i, gl := range listeners {
+		gl.routes.Insert(route.Namespace + "/" + route.Name)
+		for _, rule := range listenerRules[i] {
+			name := ruleName(rule)
+			if !gl.ruleNames.Has(name) {
+				gl.ruleNames.Insert(name)
+				gl.rules = append(gl.rules, rule)
+			}
+		}
+	}
+	return []metav1.Condition{routeCondition(route, gatewayv1beta1.RouteConditionAccepted, true, gatewayv1beta1.RouteReasonAccepted, ""), resolvedRefs}, nil
+}
+
+func ruleName(rule gatewayRule) string {
+	if rule.routing != nil {
+		return *rule.routing.Name
+	}
+	return fmt.Sprintf("redirect/%s/%s/%d/%d", rule.route.Namespace, rule.route.Name, rule.rule, rule.match)
+}
+
+func (t *gatewayTranslator) allowsRoute(gl *gatewayListener, route *gatewayv1beta1.HTTPRoute) (bool, error) {
+	from := gatewayv1beta1.NamespacesFromSame
+	var selector *metav1.LabelSelector
+	if allowed := gl.listener.AllowedRoutes; allowed != nil && allowed.Namespaces != nil {
+		if allowed.Namespaces.From != nil {
+			from = *allowed.Namespaces.From
+		}
+		selector = allowed.Namespaces.Selector
+	}
+	switch from {
+	case gatewayv1beta1.NamespacesFromAll:
+		return true, nil
+	case gatewayv1beta1.NamespacesFromSame:
+		return route.Namespace == t.gw.Namespace, nil
+	case gatewayv1beta1.NamespacesFromSelector:
+		if selector == nil {
+			return false, errors.Errorf("listener %q allows routes by namespace selector, but has none", gl.listener.Name)
+		}
+		s, err := metav1.LabelSelectorAsSelector(selector)
+		if err != nil {
+			return false, errors.Wrapf(err, "invalid namespace selector of listener %q", gl.listener.Name)
+		}
+		ns, found := t.namespaces[route.Namespace]
+		if !found {
+			ns = &corev1.Namespace{}
+			if err := t.k8sClient.Get(t.ctx, types.NamespacedName{Name: route.Namespace}, ns); err != nil {
+				return false, errors.Wrapf(err, "get namespace %q", route.Namespace)
+			}
+			t.namespaces[route.Namespace] = ns
+		}
+		return s.Matches(labels.Set(ns.Labels)), nil
+	}
+	return false, errors.Errorf("listener %q allows routes from unknown namespaces %q", gl.listener.Name, from)
+}
+
+// intersectHostnames returns the hostnames requests of which both the listener and the route match. Empty result means none.
+// "" stands for any hostname.
+func intersectHostnames(listenerHostname string, routeHostnames []gatewayv1beta1.Hostname) []string {
+	if len(routeHostnames) == 0 {
+		return []string{listenerHostname}
+	}
+	hosts := sets.NewString()
+	for _, h := range routeHostnames {
+		host := string(h)
+		switch {
+		case listenerHostname == "" || host == listenerHostname || matchesWildcardHostname(listenerHostname, host):
+			hosts.Insert(host)
+		case matchesWildcardHostname(host, listenerHostname):
+			hosts.Insert(listenerHostname)
+		}
+	}
+	return hosts.List()
+}
+
+func matchesWildcardHostname(wildcard, hostname string) bool {
+	return strings.HasPrefix(wildcard, "*.") && strings.HasSuffix(hostname, wildcard[1:]) && len(hostname) > len(wildcard)-1
+}
+
+// createHostsCondition creates the condition matching requests for any of the hosts on the listener. It is empty if the
+// listener matches them already.
+func createHostsCondition(hosts []string, listenerHostname string) (string, error) {
+	var conditions []string
+	for _, host := range hosts {
+		if host == "" || host == listenerHostname {
+			return "", nil
+		}
+		if strings.HasPrefix(host, "*.") {
+			// See createHostnameCondition()
+			return "", errors.Errorf("wildcard hostname %q can't be matched unless a listener has it", host)
+		}
+		if err := checkConditionValue(host); err != nil {
+			return "", err
+		}
+		conditions = append(conditions, createHostnameCondition(host))
+	}
+	if len(conditions) == 1 {
+		return conditions[0], nil
+	}
+	return fmt.Sprintf("any(%s)", strings.Join(conditions, ", ")), nil
+}
+
+// checkConditionValue checks that the value can be put in a string literal of a routing policy condition
+func checkConditionValue(value string) error {
+	if strings.ContainsAny(value, "'\\") {
+		return errors.Errorf("value %q can't be matched. It has quotes or backslashes", value)
+	}
+	return nil
+}
+
+// weightedBackendSet is a backend set requests are forwarded to, in proportion to weight
+type weightedBackendSet struct {
+	Name   string
+	Weight int32
+}
+
+// resolveRouteBackends resolves the backend references of every rule of the route into a backend set each. References
+// which can't be resolved are left out, and reported in the returned ResolvedRefs condition. Requests routed to none of
+// the backends fail. Only errors other than unresolved references are returned. The reason is set along with an error
+// if the route can't be accepted, eg: for backends which can't be weighted together.
+func (t *gatewayTranslator) resolveRouteBackends(route *gatewayv1beta1.HTTPRoute) ([]string, metav1.Condition, gatewayv1beta1.RouteConditionReason, error) {
+	resolvedRefs := routeCondition(route, gatewayv1beta1.RouteConditionResolvedRefs, true, gatewayv1beta1.RouteReasonResolvedRefs, "")
+	backendSets := make([]string, len(route.Spec.Rules))
+	for i, rule := range route.Spec.Rules {
+		var backends []weightedBackendSet
+		for _, ref := range rule.BackendRefs {
+			weight := int32(1)
+			if ref.Weight != nil {
+				weight = *ref.Weight
+			}
+			if weight == 0 {
+				continue
+			}
+			name, reason, err := t.resolveBackendRef(route.Namespace, ref.BackendObjectReference)
+			if err != nil && reason == "" {
+				return nil, resolvedRefs, "", err
+			}
+			if err != nil {
+				if resolvedRefs.Status == metav1.ConditionTrue {
+					resolvedRefs = routeCondition(route, gatewayv1beta1.RouteConditionResolvedRefs, false, reason, err.Error())
+				}
+				continue
+			}
+			backends = append(backends, weightedBackendSet{Name: name, Weight: weight})
+		}
+		name, err := t.getOrCreateWeightedBackendSet(backends)
+		if err != nil {
+			return nil, resolvedRefs, gatewayv1beta1.RouteReasonUnsupportedValue, errors.Wrapf(err, "rule %d", i)
+		}
+		backendSets[i] = name
+	}
+	return backendSets, resolvedRefs, "", nil
+}
+
+// resolveBackendRef returns the name of the backend set of the service port the reference points to. The reason is
+// empty along with an error if the error is not about the reference.
+func (t *gatewayTranslator) resolveBackendRef(namespace string, ref gatewayv1beta1.BackendObjectReference) (string, gatewayv1beta1.RouteConditionReason, error) {
+	if ref.Group != nil && *ref.Group != "" || ref.Kind != nil && *ref.Kind != serviceKind {
+		return "", gatewayv1beta1.RouteReasonInvalidKind, errors.Errorf("backend %q of kind %v is not supported", ref.Name, ref.Kind)
+	}
+	if ref.Namespace != nil && string(*ref.Namespace) != namespace {
+		return "", gatewayv1beta1.RouteReasonRefNotPermitted, errors.Errorf("service %s/%s is in another namespace. ReferenceGrants are not supported", *ref.Namespace, ref.Name)
+	}
+	key := namespace + "/" + string(ref.Name)
+	if ref.Port == nil {
+		return "", gatewayv1beta1.RouteReasonBackendNotFound, errors.Errorf("port of service %s is required", key)
+	}
+	svc, found := t.spec.Services[key]
+	if !found {
+		svc = &corev1.Service{}
+		if err := t.k8sClient.Get(t.ctx, types.NamespacedName{Namespace: namespace, Name: string(ref.Name)}, svc); err != nil {
+			if apierrors.IsNotFound(err) {
+				return "", gatewayv1beta1.RouteReasonBackendNotFound, errors.Errorf("service %s not found", key)
+			}
+			return "", "", errors.Wrapf(err, "get service %s", key)
+		}
+		backendSets, err := GetBackendSets(t.logger.Sugar(), svc, t.nodes, nil, t.policy)
+		if err != nil {
+			return "", gatewayv1beta1.RouteReasonBackendNotFound, errors.Wrapf(err, "service %s", key)
+		}
+		if err := setupHealthChecks(t.ctx, svc, backendSets, nil, t.spec.Certificates, t.k8sClient, t.logger); err != nil {
+			return "", gatewayv1beta1.RouteReasonBackendNotFound, errors.Wrapf(err, "service %s", key)
+		}
+		for name := range backendSets {
+			if owner, taken := t.backendSetOwners[name]; taken && owner != key {
+				return "", gatewayv1beta1.RouteReasonBackendNotFound, errors.Errorf("backend set %q of service %s is already used by service %s", name, key, owner)
+			}
+		}
+		for name, backendSet := range backendSets {
+			t.spec.BackendSets[name] = backendSet
+			t.backendSetOwners[name] = key
+		}
+		t.spec.Services[key] = svc
+	}
+	for _, servicePort := range svc.Spec.Ports {
+		if servicePort.Protocol != corev1.ProtocolTCP || servicePort.Port != int32(*ref.Port) {
+			continue
+		}
+		if servicePort.NodePort <= 0 {
+			return "", gatewayv1beta1.RouteReasonBackendNotFound, errors.Errorf("port %d of service %s has no NodePort", *ref.Port, key)
+		}
+		return GetBackendSetName(svc.Name, string(corev1.ProtocolTCP), int(servicePort.Port)), "", nil
+	}
+	return "", gatewayv1beta1.RouteReasonBackendNotFound, errors.Errorf("service %s has no TCP port %d", key, *ref.Port)
+}
+
+// getOrCreateWeightedBackendSet returns the backend set forwarding to the backend sets in proportion to their weights.
+// See createWeightedBackendSetDetails.
+func (t *gatewayTranslator) getOrCreateWeightedBackendSet(backends []weightedBackendSet) (string, error) {
+	weights := map[string]int32{}
+	var merged []weightedBackendSet
+	for _, backend := range backends {
+		if _, found := weights[backend.Name]; !found {
+			merged = append(merged, weightedBackendSet{Name: backend.Name})
+		}
+		weights[backend.Name] += backend.Weight
+	}
+	switch len(merged) {
+	case 0:
+		return DummyBackendSetName, nil
+	case 1:
+		return merged[0].Name, nil
+	}
+	for i := range merged {
+		merged[i].Weight = weights[merged[i].Name]
+	}
+	name := "weighted_" + utils.ObjectHash(merged, 23) // max 32
+	if _, found := t.spec.BackendSets[name]; found {
+		return name, nil
+	}
+	weighted, err := createWeightedBackendSetDetails(t.spec.BackendSets, merged)
+	if err != nil {
+		return "", err
+	}
+	t.spec.BackendSets[name] = weighted
+	return name, nil
+}
+
+// translateRouteRules translates the rules of the route into rules of the listener, for requests to the hosts
+func (t *gatewayTranslator) translateRouteRules(route *gatewayv1beta1.HTTPRoute, gl *gatewayListener, hosts []string, backendSets []string) ([]gatewayRule, error) {
+	hostCondition, err := createHostsCondition(hosts, gl.hostname)
+	if err != nil {
+		return nil, err
+	}
+	var rules []gatewayRule
+	for i, rule := range route.Spec.Rules {
+		var redirect *gatewayv1beta1.HTTPRequestRedirectFilter
+		for _, filter := range rule.Filters {
+			if filter.Type != gatewayv1beta1.HTTPRouteFilterRequestRedirect || filter.RequestRedirect == nil {
+				return nil, errors.Errorf("rule %d: filter %s is not supported", i, filter.Type)
+			}
+			redirect = filter.RequestRedirect
+		}
+		for _, ref := range rule.BackendRefs {
+			if len(ref.Filters) > 0 {
+				return nil, errors.Errorf("rule %d: filters of backend references are not supported", i)
+			}
+		}
+		matches := rule.Matches
+		if len(matches) == 0 {
+			matches = []gatewayv1beta1.HTTPRouteMatch{{}}
+		}
+		for j, match := range matches {
+			r := gatewayRule{route: route, rule: i, match: j, hostConditioned: hostCondition != "", headers: len(match.Headers), queryParams: len(match.QueryParams)}
+			path, conditions, err := translateMatch(match)
+			if err != nil {
+				return nil, errors.Wrapf(err, "rule %d", i)
+			}
+			r.exactPath = match.Path != nil && match.Path.Type != nil && *match.Path.Type == gatewayv1beta1.PathMatchExact
+			r.pathLength = len(path)
+			if redirect != nil {
+				if hostCondition != "" {
+					return nil, errors.Errorf("rule %d: redirects apply to all hostnames of a listener. Route hostnames must not narrow them", i)
+				}
+				if len(match.Headers) > 0 || len(match.QueryParams) > 0 {
+					return nil, errors.Errorf("rule %d: redirects can only match paths", i)
+				}
+				if r.redirect, err = createRedirectRule(path, r.exactPath, redirect); err != nil {
+					return nil, errors.Wrapf(err, "rule %d", i)
+				}
+			} else {
+				if hostCondition != "" {
+					conditions = append([]string{hostCondition}, conditions...)
+				}
+				condition, err := combineConditions(conditions...)
+				if err != nil {
+					return nil, errors.Wrapf(err, "rule %d", i)
+				}
+				name := utils.ObjectHash(struct {
+					Namespace, Name string
+					Rule, Match     int
+				}{route.Namespace, route.Name, i, j}, 22) // max 32 , ^[a-zA-Z_][a-zA-Z_0-9]*$
+				r.routing = &loadbalancer.RoutingRule{
+					Name:      &name,
+					Condition: &condition,
+					Actions:   []loadbalancer.Action{loadbalancer.ForwardToBackendSet{BackendSetName: utils.PtrToString(backendSets[i])}},
+				}
+			}
+			rules = append(rules, r)
+		}
+	}
+	return rules, nil
+}
+
+// translateMatch translates the match into routing policy conditions. Returned path is the one matched.
+func translateMatch(match gatewayv1beta1.HTTPRouteMatch) (string, []string, error) {
+	path, pathType := "/", gatewayv1beta1.PathMatchPathPrefix
+	if match.Path != nil {
+		if match.Path.Value != nil {
+			path = *match.Path.Value
+		}
+		if match.Path.Type != nil {
+			pathType = *match.Path.Type
+		}
+	}
+	if err := checkConditionValue(path); err != nil {
+		return "", nil, err
+	}
+	var conditions []string
+	switch pathType {
+	case gatewayv1beta1.PathMatchExact:
+		conditions = append(conditions, createExactPathCondition(path))
+	case gatewayv1beta1.PathMatchPathPrefix:
+		conditions = append(conditions, createPrefixPathCondition(path))
+	default:
+		return "", nil, errors.Errorf("path match type %s is not supported", pathType)
+	}
+	for _, header := range match.Headers {
+		if header.Type != nil && *header.Type != gatewayv1beta1.HeaderMatchExact {
+			return "", nil, errors.Errorf("header match type %s is not supported", *header.Type)
+		}
+		if err := checkConditionValue(string(header.Name) + header.Value); err != nil {
+			return "", nil, err
+		}
+		conditions = append(conditions, createKeyEqualCondition("http.request.headers", Literal{Value: string(header.Name), CaseInsensitive: true}, header.Value))
+	}
+	for _, param := range match.QueryParams {
+		if param.Type != nil && *param.Type != gatewayv1beta1.QueryParamMatchExact {
+			return "", nil, errors.Errorf("query param match type %s is not supported", *param.Type)
+		}
+		if err := checkConditionValue(param.Name + param.Value); err != nil {
+			return "", nil, err
+		}
+		conditions = append(conditions, createKeyEqualCondition("http.request.url.query", Literal{Value: param.Name}, param.Value))
+	}
+	if match.Method != nil {
+		return "", nil, errors.Errorf("matching method %s is not supported. OCI routing policies can't match request methods", *match.Method)
+	}
+	return path, conditions, nil
+}
+
+// createRedirectRule creates the rule set item redirecting requests for the path
+func createRedirectRule(path string, exact bool, redirect *gatewayv1beta1.HTTPRequestRedirectFilter) (*loadbalancer.RedirectRule, error) {
+	operator := loadbalancer.PathMatchConditionOperatorPrefixMatch
+	if exact {
+		operator = loadbalancer.PathMatchConditionOperatorExactMatch
+	}
+	uri := &loadbalancer.RedirectUri{
+		Protocol: utils.PtrToString("{protocol}"),
+		Host:     utils.PtrToString("{host}"),
+		Path:     utils.PtrToString("{path}"),
+		Query:    utils.PtrToString("?{query}"),
+	}
+	if redirect.Scheme != nil {
+		uri.Protocol = utils.PtrToString(strings.ToUpper(*redirect.Scheme))
+		// Port derives from the scheme unless set
+		if strings.EqualFold(*redirect.Scheme, "https") {
+			uri.Port = utils.PtrToInt(443)
+		} else {
+			uri.Port = utils.PtrToInt(80)
+		}
+	}
+	if redirect.Hostname != nil {
+		uri.Host = utils.PtrToString(string(*redirect.Hostname))
+	}
+	if redirect.Port != nil {
+		uri.Port = utils.PtrToInt(int(*redirect.Port))
+	}
+	if redirect.Path != nil {
+		if redirect.Path.Type != gatewayv1beta1.FullPathHTTPPathModifier || redirect.Path.ReplaceFullPath == nil {
+			return nil, errors.Errorf("path modifier %s is not supported", redirect.Path.Type)
+		}
+		uri.Path = utils.PtrToString(*redirect.Path.ReplaceFullPath)
+	}
+	responseCode := 302
+	if redirect.StatusCode != nil {
+		responseCode = *redirect.StatusCode
+	}
+	return &loadbalancer.RedirectRule{
+		Conditions:   []loadbalancer.RuleCondition{loadbalancer.PathMatchCondition{AttributeValue: utils.PtrToString(path), Operator: operator}},
+		RedirectUri:  uri,
+		ResponseCode: &responseCode,
+	}, nil
+}
+
+// buildListeners creates an OCI listener for every ready gateway listener, with a routing policy and a rule set of
+// redirects of its own
+func (t *gatewayTranslator) buildListeners() {
+	for _, gl := range t.listeners {
+		t.spec.ListenerStatuses[gl.status].AttachedRoutes = int32(gl.routes.Len())
+		if !gl.ready {
+			continue
+		}
+		var hostnameDetails *loadbalancer.HostnameDetails
+		if gl.hostname != "" {
+			details := loadbalancer.HostnameDetails{Name: utils.PtrToString(getHostnameName(gl.hostname)), Hostname: utils.PtrToString(gl.hostname)}
+			t.spec.HostnameDetails[*details.Name] = details
+			hostnameDetails = &details
+		}
+		_, listener := createListenerDetails(nil, hostnameDetails, gl.ssl)
+		listener.Port = utils.PtrToInt(int(gl.listener.Port))
+
+		sort.SliceStable(gl.rules, func(i, j int) bool { return gl.rules[i].precedes(&gl.rules[j]) })
+		var routingRules []loadbalancer.RoutingRule
+		var redirectRules []loadbalancer.Rule
+		for _, rule := range gl.rules {
+			if rule.routing != nil {
+				routingRules = append(routingRules, *rule.routing)
+			} else {
+				redirectRules = append(redirectRules, *rule.redirect)
+			}
+		}
+		if len(routingRules) > 0 {
+			policyName := getRoutingPolicyName(string(gl.listener.Name))
+			t.spec.RoutingPolicies[policyName] = loadbalancer.RoutingPolicy{
+				Name:                     utils.PtrToString(policyName),
+				ConditionLanguageVersion: loadbalancer.RoutingPolicyConditionLanguageVersionV1,
+				Rules:                    routingRules,
+			}
+			listener.RoutingPolicyName = &policyName
+		}
+		if len(redirectRules) > 0 {
+			ruleSetName := "redirects_" + utils.ByteAlphaNumericDigest([]byte(gl.listener.Name), 22) // ^[a-zA-Z_][a-zA-Z_0-9]*$
+			t.spec.RuleSets[ruleSetName] = loadbalancer.RuleSetDetails{Items: redirectRules}
+			listener.RuleSetNames = []string{ruleSetName}
+		}
+		t.spec.Listeners[GetListenerName(string(gl.listener.Name))] = listener
+	}
+}
@@ -0,0 +1,165 @@
+package ingress
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oracle/oci-go-sdk/v46/loadbalancer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+func TestIntersectHostnames(t *testing.T) {
+	testCases := []struct {
+		listener string
+		route    []gatewayv1beta1.Hostname
+		expected []string
+	}{
+		{listener: "", route: nil, expected: []string{""}},
+		{listener: "", route: []gatewayv1beta1.Hostname{"a.example.com", "*.example.com"}, expected: []string{"*.example.com", "a.example.com"}},
+		{listener: "*.example.com", route: []gatewayv1beta1.Hostname{"a.example.com", "example.com", "a.example.org"}, expected: []string{"a.example.com"}},
+		{listener: "a.example.com", route: []gatewayv1beta1.Hostname{"*.example.com"}, expected: []string{"a.example.com"}},
+		{listener: "a.example.com", route: []gatewayv1beta1.Hostname{"b.example.com"}, expected: []string{}},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, intersectHostnames(tc.listener, tc.route), "listener=%q route=%v", tc.listener, tc.route)
+	}
+}
+
+func TestTranslateGateway(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	service := func(name string, nodePort int32) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Protocol: corev1.ProtocolTCP, Port: 80, NodePort: nodePort}}},
+		}
+	}
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+		Status:     corev1.NodeStatus{Addresses: []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "10.0.0.1"}}},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(service("web", 30080), service("canary", 30081), node).Build()
+
+	hostname := gatewayv1beta1.Hostname("*.example.com")
+	gw := &gatewayv1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "gw"},
+		Spec: gatewayv1beta1.GatewaySpec{Listeners: []gatewayv1beta1.Listener{
+			{Name: "http", Port: 80, Protocol: gatewayv1beta1.HTTPProtocolType, Hostname: &hostname},
+			{Name: "tcp", Port: 81, Protocol: gatewayv1beta1.TCPProtocolType},
+			{Name: "https", Port: 80, Protocol: gatewayv1beta1.HTTPSProtocolType},
+		}},
+	}
+	exact, prefix := gatewayv1beta1.PathMatchExact, gatewayv1beta1.PathMatchPathPrefix
+	port := gatewayv1beta1.PortNumber(80)
+	backend := func(name string, weight int32) gatewayv1beta1.HTTPBackendRef {
+		return gatewayv1beta1.HTTPBackendRef{BackendRef: gatewayv1beta1.BackendRef{
+			BackendObjectReference: gatewayv1beta1.BackendObjectReference{Name: gatewayv1beta1.ObjectName(name), Port: &port},
+			Weight:                 &weight,
+		}}
+	}
+	route := func(namespace, name string, hostnames []gatewayv1beta1.Hostname, rules ...gatewayv1beta1.HTTPRouteRule) gatewayv1beta1.HTTPRoute {
+		r := gatewayv1beta1.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+		r.Spec.ParentRefs = []gatewayv1beta1.ParentReference{{Name: "gw", Namespace: (*gatewayv1beta1.Namespace)(&gw.Namespace)}}
+		r.Spec.Hostnames = hostnames
+		r.Spec.Rules = rules
+		return r
+	}
+	get := gatewayv1beta1.HTTPMethodGet
+	routes := []gatewayv1beta1.HTTPRoute{
+		route("default", "split", nil, gatewayv1beta1.HTTPRouteRule{
+			Matches:     []gatewayv1beta1.HTTPRouteMatch{{Path: &gatewayv1beta1.HTTPPathMatch{Type: &prefix, Value: strPtr("/")}}},
+			BackendRefs: []gatewayv1beta1.HTTPBackendRef{backend("web", 90), backend("canary", 10)},
+		}),
+		route("default", "login", []gatewayv1beta1.Hostname{"a.example.com"}, gatewayv1beta1.HTTPRouteRule{
+			Matches:     []gatewayv1beta1.HTTPRouteMatch{{Path: &gatewayv1beta1.HTTPPathMatch{Type: &exact, Value: strPtr("/login")}}},
+			BackendRefs: []gatewayv1beta1.HTTPBackendRef{backend("web", 1)},
+		}),
+		route("default", "method", nil, gatewayv1beta1.HTTPRouteRule{
+			Matches:     []gatewayv1beta1.HTTPRouteMatch{{Method: &get}},
+			BackendRefs: []gatewayv1beta1.HTTPBackendRef{backend("web", 1)},
+		}),
+		route("other", "foreign", nil, gatewayv1beta1.HTTPRouteRule{BackendRefs: []gatewayv1beta1.HTTPBackendRef{backend("web", 1)}}),
+		route("default", "missing", []gatewayv1beta1.Hostname{"b.example.com"}, gatewayv1beta1.HTTPRouteRule{BackendRefs: []gatewayv1beta1.HTTPBackendRef{backend("nope", 1)}}),
+	}
+
+	tr := &gatewayTranslator{
+		ctx:              context.Background(),
+		gw:               gw,
+		k8sClient:        k8sClient,
+		logger:           zap.NewNop(),
+		nodes:            []*corev1.Node{node},
+		policy:           "ROUND_ROBIN",
+		backendSetOwners: map[string]string{},
+		namespaces:       map[string]*corev1.Namespace{},
+		spec: &GatewayLBSpec{
+			IngressLBSpec: &IngressLBSpec{
+				Gateway:         gw,
+				Services:        map[string]*corev1.Service{},
+				RoutingPolicies: map[string]loadbalancer.RoutingPolicy{},
+				RuleSets:        map[string]loadbalancer.RuleSetDetails{},
+				HostnameDetails: map[string]loadbalancer.HostnameDetails{},
+				Certificates:    map[string]loadbalancer.CertificateDetails{},
+			},
+			RouteParents: map[types.NamespacedName][]gatewayv1beta1.RouteParentStatus{},
+		},
+	}
+	tr.spec.Listeners = map[string]loadbalancer.ListenerDetails{}
+	tr.spec.BackendSets = map[string]loadbalancer.BackendSetDetails{DummyBackendSetName: createDummyBackendSetDetails("ROUND_ROBIN")}
+	for _, listener := range gw.Spec.Listeners {
+		tr.addListener(listener)
+	}
+	for i := range routes {
+		require.NoError(t, tr.attachRoute(&routes[i]))
+	}
+	tr.buildListeners()
+	spec := tr.spec
+
+	// Listeners
+	require.Len(t, spec.ListenerStatuses, 3)
+	assert.True(t, meta.IsStatusConditionTrue(spec.ListenerStatuses[0].Conditions, string(gatewayv1beta1.ListenerConditionReady)))
+	assert.Equal(t, int32(3), spec.ListenerStatuses[0].AttachedRoutes) // split, login, missing
+	assert.True(t, meta.IsStatusConditionTrue(spec.ListenerStatuses[1].Conditions, string(gatewayv1beta1.ListenerConditionDetached)))
+	assert.True(t, meta.IsStatusConditionFalse(spec.ListenerStatuses[2].Conditions, string(gatewayv1beta1.ListenerConditionReady)))
+	assert.Len(t, spec.Listeners, 1)
+
+	// Routes
+	accepted := func(namespace, name string) *metav1.Condition {
+		parents := spec.RouteParents[types.NamespacedName{Namespace: namespace, Name: name}]
+		require.Len(t, parents, 1, "%s/%s", namespace, name)
+		return meta.FindStatusCondition(parents[0].Conditions, string(gatewayv1beta1.RouteConditionAccepted))
+	}
+	assert.Equal(t, metav1.ConditionTrue, accepted("default", "split").Status)
+	assert.Equal(t, metav1.ConditionTrue, accepted("default", "login").Status)
+	assert.Equal(t, string(gatewayv1beta1.RouteReasonUnsupportedValue), accepted("default", "method").Reason)
+	assert.Equal(t, string(gatewayv1beta1.RouteReasonNotAllowedByListeners), accepted("other", "foreign").Reason)
+	missing := spec.RouteParents[types.NamespacedName{Namespace: "default", Name: "missing"}][0]
+	assert.Equal(t, string(gatewayv1beta1.RouteReasonBackendNotFound), meta.FindStatusCondition(missing.Conditions, string(gatewayv1beta1.RouteConditionResolvedRefs)).Reason)
+
+	// Rules are ordered host conditioned first, exact paths before prefixes
+	require.Len(t, spec.RoutingPolicies, 1)
+	for _, policy := range spec.RoutingPolicies {
+		require.Len(t, policy.Rules, 3)
+		assert.Equal(t, "all("+createHostnameCondition("a.example.com")+", http.request.url.path eq '/login')", *policy.Rules[0].Condition)
+		assert.Equal(t, DummyBackendSetName, *policy.Rules[1].Actions[0].(loadbalancer.ForwardToBackendSet).BackendSetName) // missing
+		assert.Equal(t, "http.request.url.path sw '/'", *policy.Rules[2].Condition)
+
+		weighted := spec.BackendSets[*policy.Rules[2].Actions[0].(loadbalancer.ForwardToBackendSet).BackendSetName]
+		require.Len(t, weighted.Backends, 2)
+		assert.Equal(t, 100, *weighted.Backends[0].Weight)
+		assert.Equal(t, 11, *weighted.Backends[1].Weight)
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,255 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	. "github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/utils"
+	"github.com/oracle/oci-go-sdk/v46/loadbalancer"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// groupLoadBalancerAnnotations apply to the whole load balancer. Members of a group must agree on them.
+var groupLoadBalancerAnnotations = []string{
+	AnnotationLoadBalancerInternal,
+	AnnotationLoadBalancerShape,
+	AnnotationLoadBalancerShapeFlexMin,
+	AnnotationLoadBalancerShapeFlexMax,
+	AnnotationLoadBalancerSubnet1,
+	AnnotationLoadBalancerSubnet2,
+	AnnotationLoadBalancerReservedIP,
+	AnnotationBackendDrainPeriod,
+}
+
+// GetLoadBalancerGroup returns the load balancer group the ingress joins, or "" if it has a load balancer of its own
+func GetLoadBalancerGroup(ing *networking.Ingress) string {
+	return GetAnnotation(ing, AnnotationLoadBalancerGroup)
+}
+
+// GetLoadBalancerNameForIngress gets the name of the load balancer serving the ingress, shared or not
+func GetLoadBalancerNameForIngress(ing *networking.Ingress) string {
+	if group := GetLoadBalancerGroup(ing); group != "" {
+		return GetGroupLoadBalancerName(group)
+	}
+	return GetLoadBalancerName(ing.Namespace, ing.Name)
+}
+
+// IsLoadBalancerGroupAllowed tells whether ingresses of the namespace may join the group
+func IsLoadBalancerGroupAllowed(ns *corev1.Namespace, group string) bool {
+	for _, allowed := range strings.Split(GetAnnotation(ns, AnnotationAllowedLoadBalancerGroups), ",") {
+		if allowed = strings.TrimSpace(allowed); allowed == "*" || allowed == group {
+			return true
+		}
+	}
+	return false
+}
+
+// SortGroupMembers orders members of a group by age, oldest first. Older members win conflicts.
+func SortGroupMembers(members []*networking.Ingress) {
+	sort.SliceStable(members, func(i, j int) bool {
+		if ti, tj := members[i].CreationTimestamp, members[j].CreationTimestamp; !ti.Equal(&tj) {
+			return ti.Before(&tj)
+		}
+		return members[i].Namespace+"/"+members[i].Name < members[j].Namespace+"/"+members[j].Name
+	})
+}
+
+// FindGroupConflicts returns the members which can't join the group, along with the reason. Members are checked in order
+// against the ones accepted before them. A member conflicts when it
+//   - routes a host and path already routed by another member
+//   - serves a host with a TLS secret other than the one used by another member (or without TLS while another one has it)
+//   - has a default backend while another member has it too
+//   - differs from the first member in IngressClass or in load balancer wide annotations (shape, subnets, etc.)
+func FindGroupConflicts(ctx context.Context, members []*networking.Ingress) (map[types.NamespacedName]error, error) {
+	conflicts := map[types.NamespacedName]error{}
+	var first *networking.Ingress
+	var firstClass string
+	pathOwners := map[string]types.NamespacedName{}
+	hostTLS := map[string]string{} // host -> "<namespace>/<secretName>", or "" without TLS
+	hostOwners := map[string]types.NamespacedName{}
+	var defaultBackendOwner *types.NamespacedName
+
+	for _, ing := range members {
+		nName := types.NamespacedName{Namespace: ing.Namespace, Name: ing.Name}
+		class, _, err := GetIngressClass(ctx, ing)
+		if err != nil {
+			return nil, err
+		}
+		className := GetIngressClassName(ing)
+		if class != nil {
+			className = class.Name
+		}
+		if first == nil {
+			first, firstClass = ing, className
+		}
+		tls, paths := getHostTLSSecrets(ing), getPathKeys(ing)
+		conflict := func() error {
+			if className != firstClass {
+				return errors.Errorf("ingress class %q differs from %q of %s/%s", className, firstClass, first.Namespace, first.Name)
+			}
+			for _, name := range groupLoadBalancerAnnotations {
+				if value, firstValue := GetAnnotation(ing, name), GetAnnotation(first, name); value != firstValue {
+					return errors.Errorf("annotation %s=%q differs from %q of %s/%s", IngressAnnotationPrefix+name, value, firstValue, first.Namespace, first.Name)
+				}
+			}
+			if ing.Spec.DefaultBackend != nil && defaultBackendOwner != nil {
+				return errors.Errorf("default backend is already set by %s", defaultBackendOwner)
+			}
+			for host, secret := range tls {
+				if owner, found := hostOwners[host]; found && hostTLS[host] != secret {
+					return errors.Errorf("host %q is served by %s with TLS secret %q, not %q", host, owner, hostTLS[host], secret)
+				}
+			}
+			for key := range paths {
+				if owner, found := pathOwners[key]; found {
+					return errors.Errorf("%s is already routed by %s", key, owner)
+				}
+			}
+			return nil
+		}()
+		if conflict != nil {
+			conflicts[nName] = conflict
+			continue
+		}
+		for host, secret := range tls {
+			if _, found := hostOwners[host]; !found {
+				hostOwners[host] = nName
+				hostTLS[host] = secret
+			}
+		}
+		for key := range paths {
+			pathOwners[key] = nName
+		}
+		if ing.Spec.DefaultBackend != nil {
+			defaultBackendOwner = &nName
+		}
+	}
+	return conflicts, nil
+}
+
+// getHostTLSSecrets maps hosts of the ingress rules to "<namespace>/<secretName>" of their TLS secret, or "" if they have none
+func getHostTLSSecrets(ing *networking.Ingress) map[string]string {
+	secrets := map[string]string{}
+	for _, rule := range ing.Spec.Rules {
+		if rule.Host != "" {
+			secrets[rule.Host] = ""
+		}
+	}
+	for _, tls := range ing.Spec.TLS {
+		for _, host := range tls.Hosts {
+			if _, found := secrets[host]; found {
+				secrets[host] = ing.Namespace + "/" + tls.SecretName
+			}
+		}
+	}
+	return secrets
+}
+
+func getPathKeys(ing *networking.Ingress) map[string]bool {
+	keys := map[string]bool{}
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			pathType := networking.PathTypeImplementationSpecific
+			if path.PathType != nil {
+				pathType = *path.PathType
+			}
+			keys[fmt.Sprintf("host %q path %s %q", rule.Host, pathType, path.Path)] = true
+		}
+	}
+	return keys
+}
+
+// MergeGroupSpecs merges the specs of group members (as ordered by SortGroupMembers) into the spec of the shared load balancer.
+// Load balancer wide settings are taken from the first member, which is the group leader. Events of the merged spec go to ing.
+// Backend sets are named after the namespace of their service, see getBackendSetName. Members of a namespace routing to the
+// same service port share its backend set, as set up by the oldest of them.
+func MergeGroupSpecs(group string, ing *networking.Ingress, members []*IngressLBSpec) *IngressLBSpec {
+	if len(members) == 0 {
+		return nil
+	}
+	spec := &IngressLBSpec{
+		LBSpec:          members[0].LBSpec,
+		Ingress:         ing,
+		GroupLeader:     members[0].Ingress,
+		Services:        map[string]*corev1.Service{},
+		RoutingPolicies: map[string]loadbalancer.RoutingPolicy{},
+		RuleSets:        map[string]loadbalancer.RuleSetDetails{},
+		HostnameDetails: map[string]loadbalancer.HostnameDetails{},
+		Certificates:    map[string]loadbalancer.CertificateDetails{},
+	}
+	spec.Name = GetGroupLoadBalancerName(group)
+	spec.Listeners = map[string]loadbalancer.ListenerDetails{}
+	spec.BackendSets = map[string]loadbalancer.BackendSetDetails{}
+
+	var defaultBackendRule *loadbalancer.RoutingRule
+	for _, member := range members {
+		for name, backendSet := range member.BackendSets {
+			if _, found := spec.BackendSets[name]; !found {
+				spec.BackendSets[name] = backendSet
+			}
+		}
+		for name, svc := range member.Services {
+			spec.Services[member.Ingress.Namespace+"/"+name] = svc
+		}
+		for name, details := range member.HostnameDetails {
+			spec.HostnameDetails[name] = details
+		}
+		for name, details := range member.Certificates {
+			spec.Certificates[name] = details
+		}
+		for name, ruleSet := range member.RuleSets {
+			spec.RuleSets[name] = ruleSet
+		}
+		for name, listener := range member.Listeners {
+			existing, found := spec.Listeners[name]
+			if found && name == httpsRedirectorListenerName {
+				existing.HostnameNames = sets.NewString(append(existing.HostnameNames, listener.HostnameNames...)...).List()
+				spec.Listeners[name] = existing
+			} else if !found {
+				spec.Listeners[name] = listener
+			}
+			if name == defaultBackendListenerName {
+				defaultBackendRule, _ = createDefaultBackendRoutingRule(*listener.DefaultBackendSetName)
+			}
+		}
+		for name, policy := range member.RoutingPolicies {
+			merged, found := spec.RoutingPolicies[name]
+			if !found {
+				merged = policy
+				merged.Rules = nil
+			}
+			for _, rule := range policy.Rules {
+				// Default backend rule is appended to every policy after merging, so that it stays the last one
+				if *rule.Name == defaultBackendRoutingRuleName {
+					continue
+				}
+				if !utils.ContainsMatching(merged.Rules, func(r loadbalancer.RoutingRule) bool { return *r.Name == *rule.Name }) {
+					merged.Rules = append(merged.Rules, rule)
+				}
+			}
+			sortRoutingRules(merged.Rules)
+			spec.RoutingPolicies[name] = merged
+		}
+	}
+
+	if defaultBackendRule != nil {
+		// Both handle requests not matching any host on port 80
+		delete(spec.Listeners, sansVirtualHostListenerName)
+		for name, policy := range spec.RoutingPolicies {
+			policy.Rules = append(policy.Rules, *defaultBackendRule)
+			spec.RoutingPolicies[name] = policy
+		}
+	}
+	prioritizePreciseHosts(spec.Listeners, spec.RoutingPolicies, spec.HostnameDetails)
+	return spec
+}
@@ -0,0 +1,111 @@
+package ingress
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/utils"
+	"github.com/oracle/oci-go-sdk/v46/loadbalancer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newGroupTestIngress(namespace, name string, age time.Duration, host, path string) *networking.Ingress {
+	ing := newValidationTestIngress(map[string]string{AnnotationLoadBalancerGroup: "shared"}, networking.PathTypePrefix, path)
+	ing.Namespace, ing.Name = namespace, name
+	ing.CreationTimestamp = metav1.NewTime(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC).Add(-age))
+	ing.Spec.IngressClassName = &OCILoadbalancerIngressClass
+	ing.Spec.Rules[0].Host = host
+	return ing
+}
+
+func TestFindGroupConflicts(t *testing.T) {
+	oldest := newGroupTestIngress("team-a", "web", 3*time.Hour, "www.example.com", "/")
+	otherPath := newGroupTestIngress("team-b", "api", 2*time.Hour, "www.example.com", "/api")
+	samePath := newGroupTestIngress("team-b", "web", time.Hour, "www.example.com", "/")
+	otherShape := newGroupTestIngress("team-c", "web", time.Hour, "shop.example.com", "/")
+	otherShape.Annotations[IngressAnnotationPrefix+AnnotationLoadBalancerShape] = "400Mbps"
+	withTLS := newGroupTestIngress("team-c", "secure", time.Hour, "www.example.com", "/secure")
+	withTLS.Spec.TLS = []networking.IngressTLS{{Hosts: []string{"www.example.com"}, SecretName: "tls"}}
+
+	members := []*networking.Ingress{samePath, withTLS, otherShape, otherPath, oldest}
+	SortGroupMembers(members)
+	assert.Equal(t, []*networking.Ingress{oldest, otherPath, samePath, withTLS, otherShape}, members)
+
+	conflicts, err := FindGroupConflicts(context.Background(), members)
+	require.NoError(t, err)
+	var conflicting []types.NamespacedName
+	for nName := range conflicts {
+		conflicting = append(conflicting, nName)
+	}
+	assert.ElementsMatch(t, []types.NamespacedName{{Namespace: "team-b", Name: "web"}, {Namespace: "team-c", Name: "web"}, {Namespace: "team-c", Name: "secure"}}, conflicting)
+}
+
+func TestMergeGroupSpecs(t *testing.T) {
+	newSpec := func(namespace, host string, defaultBackend bool, backendPort int) *IngressLBSpec {
+		backendSetName := GetNamespacedBackendSetName(namespace, "web", "TCP", 80)
+		rule, err := createRoutingRule(networking.HTTPIngressPath{Path: "/" + namespace, PathType: func() *networking.PathType { p := networking.PathTypePrefix; return &p }()}, backendSetName, host)
+		require.NoError(t, err)
+		policy := loadbalancer.RoutingPolicy{Name: utils.PtrToString(getRoutingPolicyName(host)), Rules: []loadbalancer.RoutingRule{*rule}}
+		listenerName, listener := createListenerDetails(nil, &loadbalancer.HostnameDetails{Name: &host, Hostname: &host}, nil)
+		listener.RoutingPolicyName = policy.Name
+		listeners := map[string]loadbalancer.ListenerDetails{listenerName: listener}
+		if defaultBackend {
+			name, l := createDefaultBackendListenerDetails(backendSetName)
+			listeners[name] = l
+			defaultRule, _ := createDefaultBackendRoutingRule(backendSetName)
+			policy.Rules = append(policy.Rules, *defaultRule)
+		} else {
+			name, l := createSansVirtualHostListenerDetails()
+			listeners[name] = l
+		}
+		return &IngressLBSpec{
+			LBSpec: LBSpec{
+				Name:      GetLoadBalancerName(namespace, "web"),
+				Listeners: listeners,
+				BackendSets: map[string]loadbalancer.BackendSetDetails{
+					backendSetName: {Backends: []loadbalancer.BackendDetails{{IpAddress: utils.PtrToString("10.0.0.1"), Port: utils.PtrToInt(backendPort)}}},
+				},
+			},
+			Ingress:         &networking.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "web"}},
+			Services:        map[string]*corev1.Service{"web": {ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "web"}}},
+			RoutingPolicies: map[string]loadbalancer.RoutingPolicy{*policy.Name: policy},
+			HostnameDetails: map[string]loadbalancer.HostnameDetails{host: {Name: &host, Hostname: &host}},
+		}
+	}
+
+	first := newSpec("team-a", "www.example.com", true, 30080)
+	second := newSpec("team-a", "api.example.com", false, 30080)
+	// Service of the same name in another namespace
+	other := newSpec("team-b", "shop.example.com", false, 30081)
+	spec := MergeGroupSpecs("shared", other.Ingress, []*IngressLBSpec{first, second, other})
+
+	assert.Equal(t, GetGroupLoadBalancerName("shared"), spec.Name)
+	assert.Same(t, other.Ingress, spec.Ingress)
+	assert.Same(t, first.Ingress, spec.GroupLeader)
+	assert.ElementsMatch(t, []string{"wwwDOTexampleDOTcom", "apiDOTexampleDOTcom", "shopDOTexampleDOTcom", defaultBackendListenerName}, utils.StringKeys(spec.Listeners).List())
+	assert.ElementsMatch(t, []string{"www.example.com", "api.example.com", "shop.example.com"}, utils.StringKeys(spec.HostnameDetails).List())
+	assert.ElementsMatch(t, []string{GetNamespacedBackendSetName("team-a", "web", "TCP", 80), GetNamespacedBackendSetName("team-b", "web", "TCP", 80)}, utils.StringKeys(spec.BackendSets).List())
+	require.Len(t, spec.RoutingPolicies, 3)
+	for name, policy := range spec.RoutingPolicies {
+		// default backend of the first member applies to hosts of others, after their own rules
+		require.Len(t, policy.Rules, 2, name)
+		assert.Equal(t, defaultBackendRoutingRuleName, *policy.Rules[1].Name, name)
+	}
+}
+
+func TestIsLoadBalancerGroupAllowed(t *testing.T) {
+	ns := func(allowed string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Annotations: map[string]string{IngressAnnotationPrefix + AnnotationAllowedLoadBalancerGroups: allowed}}}
+	}
+	assert.True(t, IsLoadBalancerGroupAllowed(ns("internal, shared"), "shared"))
+	assert.True(t, IsLoadBalancerGroupAllowed(ns("*"), "shared"))
+	assert.False(t, IsLoadBalancerGroupAllowed(ns("internal"), "shared"))
+	assert.False(t, IsLoadBalancerGroupAllowed(&corev1.Namespace{}, "shared"))
+}
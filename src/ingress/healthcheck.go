@@ -0,0 +1,279 @@
+package ingress
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+
+	. "github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/utils"
+	"github.com/oracle/oci-go-sdk/v46/loadbalancer"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	healthCheckProtocolHTTP = "HTTP"
+	healthCheckProtocolTCP  = "TCP"
+)
+
+// HealthCheckConfig is the health check of a backend set as set by annotations. Zero values are not set. It holds the
+// backend TLS secret too, as OCI health checks use TLS exactly when the backend set does.
+type HealthCheckConfig struct {
+	Protocol          string `json:"protocol,omitempty"`
+	URLPath           string `json:"urlPath,omitempty"`
+	Port              int    `json:"port,omitempty"`
+	StatusCode        int    `json:"statusCode,omitempty"`
+	ResponseBodyRegex string `json:"responseBodyRegex,omitempty"`
+	BackendTLSSecret  string `json:"backendTLSSecret,omitempty"`
+}
+
+// healthCheckAnnotations maps fields of HealthCheckConfig, by JSON name, to the Service annotations setting them
+var healthCheckAnnotations = map[string]string{
+	"protocol":          AnnotationHealthCheckProtocol,
+	"urlPath":           AnnotationHealthCheckPath,
+	"port":              AnnotationHealthCheckPort,
+	"statusCode":        AnnotationHealthCheckStatusCode,
+	"responseBodyRegex": AnnotationHealthCheckResponseBodyRegex,
+	"backendTLSSecret":  AnnotationBackendTLSSecret,
+}
+
+// IsSet tells whether the config changes the default health check at all
+func (c HealthCheckConfig) IsSet() bool {
+	return c != HealthCheckConfig{}
+}
+
+// merge returns the config with fields set in the override replaced
+func (c HealthCheckConfig) merge(override HealthCheckConfig) HealthCheckConfig {
+	if override.Protocol != "" {
+		c.Protocol = override.Protocol
+	}
+	if override.URLPath != "" {
+		c.URLPath = override.URLPath
+	}
+	if override.Port != 0 {
+		c.Port = override.Port
+	}
+	if override.StatusCode != 0 {
+		c.StatusCode = override.StatusCode
+	}
+	if override.ResponseBodyRegex != "" {
+		c.ResponseBodyRegex = override.ResponseBodyRegex
+	}
+	if override.BackendTLSSecret != "" {
+		c.BackendTLSSecret = override.BackendTLSSecret
+	}
+	return c
+}
+
+// validate reports fields OCI would reject. fieldPath gives the path of a field by its JSON name.
+func (c HealthCheckConfig) validate(fieldPath func(name string) *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if c.Protocol != "" && c.Protocol != healthCheckProtocolHTTP && c.Protocol != healthCheckProtocolTCP {
+		errs = append(errs, field.NotSupported(fieldPath("protocol"), c.Protocol, []string{healthCheckProtocolHTTP, healthCheckProtocolTCP}))
+	}
+	if c.URLPath != "" && !strings.HasPrefix(c.URLPath, "/") {
+		errs = append(errs, field.Invalid(fieldPath("urlPath"), c.URLPath, "must start with /"))
+	}
+	if c.Port < 0 || c.Port > 65535 {
+		errs = append(errs, field.Invalid(fieldPath("port"), c.Port, "must be a port number"))
+	}
+	if c.StatusCode != 0 && (c.StatusCode < 100 || c.StatusCode > 599) {
+		errs = append(errs, field.Invalid(fieldPath("statusCode"), c.StatusCode, "must be an HTTP status code"))
+	}
+	if c.ResponseBodyRegex != "" {
+		if _, err := regexp.Compile(c.ResponseBodyRegex); err != nil {
+			errs = append(errs, field.Invalid(fieldPath("responseBodyRegex"), c.ResponseBodyRegex, err.Error()))
+		}
+	}
+	if c.Protocol == healthCheckProtocolTCP {
+		if c.URLPath != "" {
+			errs = append(errs, field.Forbidden(fieldPath("urlPath"), "only HTTP health checks support it"))
+		}
+		if c.StatusCode != 0 {
+			errs = append(errs, field.Forbidden(fieldPath("statusCode"), "only HTTP health checks support it"))
+		}
+		if c.ResponseBodyRegex != "" {
+			errs = append(errs, field.Forbidden(fieldPath("responseBodyRegex"), "only HTTP health checks support it"))
+		}
+	}
+	if c.BackendTLSSecret != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(c.BackendTLSSecret) {
+			errs = append(errs, field.Invalid(fieldPath("backendTLSSecret"), c.BackendTLSSecret, msg))
+		}
+	}
+	return errs
+}
+
+// apply returns the health checker with the config applied. Retries, interval and timeout are kept.
+func (c HealthCheckConfig) apply(healthChecker *loadbalancer.HealthCheckerDetails) *loadbalancer.HealthCheckerDetails {
+	applied := *healthChecker
+	applied.Protocol = utils.PtrToString(healthCheckProtocolHTTP)
+	if c.Protocol != "" {
+		applied.Protocol = utils.PtrToString(c.Protocol)
+	}
+	applied.Port = utils.PtrToInt(c.Port) // 0 checks the port of each backend
+	applied.UrlPath, applied.ReturnCode, applied.ResponseBodyRegex = nil, nil, nil
+	if *applied.Protocol == healthCheckProtocolHTTP {
+		applied.UrlPath = utils.PtrToString("/")
+		if c.URLPath != "" {
+			applied.UrlPath = utils.PtrToString(c.URLPath)
+		}
+		if c.StatusCode != 0 {
+			applied.ReturnCode = utils.PtrToInt(c.StatusCode)
+		}
+		if c.ResponseBodyRegex != "" {
+			applied.ResponseBodyRegex = utils.PtrToString(c.ResponseBodyRegex)
+		}
+	}
+	return &applied
+}
+
+// getServiceHealthCheckConfig reads the health check annotations of the service
+func getServiceHealthCheckConfig(svc *corev1.Service) (HealthCheckConfig, error) {
+	var errs field.ErrorList
+	annotationPath := func(name string) *field.Path {
+		return field.NewPath("metadata", "annotations").Key(IngressAnnotationPrefix + healthCheckAnnotations[name])
+	}
+	atoi := func(name string) int {
+		value := GetAnnotation(svc, healthCheckAnnotations[name])
+		if value == "" {
+			return 0
+		}
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			errs = append(errs, field.Invalid(annotationPath(name), value, "must be an integer"))
+		}
+		return i
+	}
+	config := HealthCheckConfig{
+		Protocol:          strings.ToUpper(GetAnnotation(svc, AnnotationHealthCheckProtocol)),
+		URLPath:           GetAnnotation(svc, AnnotationHealthCheckPath),
+		Port:              atoi("port"),
+		StatusCode:        atoi("statusCode"),
+		ResponseBodyRegex: GetAnnotation(svc, AnnotationHealthCheckResponseBodyRegex),
+		BackendTLSSecret:  GetAnnotation(svc, AnnotationBackendTLSSecret),
+	}
+	if len(errs) == 0 {
+		errs = config.validate(annotationPath)
+	}
+	if len(errs) != 0 {
+		return HealthCheckConfig{}, errors.Wrapf(errs.ToAggregate(), "Invalid health check of service %s/%s", svc.Namespace, svc.Name)
+	}
+	return config, nil
+}
+
+// getPathHealthChecks reads the health checks of ingress paths, by path
+func getPathHealthChecks(ing *networking.Ingress) (map[string]HealthCheckConfig, error) {
+	healthChecks := map[string]HealthCheckConfig{}
+	value := GetAnnotation(ing, AnnotationPathHealthChecks)
+	if value == "" {
+		return healthChecks, nil
+	}
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&healthChecks); err != nil {
+		return nil, err
+	}
+	for path, config := range healthChecks {
+		config.Protocol = strings.ToUpper(config.Protocol)
+		healthChecks[path] = config
+	}
+	return healthChecks, nil
+}
+
+func validatePathHealthChecks(ing *networking.Ingress, path *field.Path) field.ErrorList {
+	healthChecks, err := getPathHealthChecks(ing)
+	if err != nil {
+		return field.ErrorList{field.Invalid(path, GetAnnotation(ing, AnnotationPathHealthChecks), "must be a JSON object of health checks by path: "+err.Error())}
+	}
+	paths := map[string]bool{}
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP != nil {
+			for _, ingPath := range rule.HTTP.Paths {
+				paths[ingPath.Path] = true
+			}
+		}
+	}
+	var errs field.ErrorList
+	for _, ingPath := range utils.StringKeys(healthChecks).List() {
+		if !paths[ingPath] {
+			errs = append(errs, field.NotFound(path.Key(ingPath), ingPath))
+			continue
+		}
+		pathPath := path.Key(ingPath)
+		errs = append(errs, healthChecks[ingPath].validate(func(name string) *field.Path { return pathPath.Child(name) })...)
+	}
+	return errs
+}
+
+// setupHealthChecks applies the health checks of the service, and the overrides by backend set name, to the backend
+// sets of the service. Backend sets with a backend TLS secret get an SSL configuration, so traffic and health checks to
+// their backends use TLS; their certificates are added to the certificates.
+func setupHealthChecks(ctx context.Context, svc *corev1.Service, backendSets map[string]loadbalancer.BackendSetDetails, overrides map[string]HealthCheckConfig, certificates map[string]loadbalancer.CertificateDetails, k8sClient k8sclient.Client, logger *zap.Logger) error {
+	serviceConfig, err := getServiceHealthCheckConfig(svc)
+	if err != nil {
+		return err
+	}
+	for name, backendSet := range backendSets {
+		config := serviceConfig.merge(overrides[name])
+		if !config.IsSet() {
+			continue
+		}
+		backendSet.HealthChecker = config.apply(backendSet.HealthChecker)
+		if config.BackendTLSSecret != "" {
+			ssl, err := createSSLConfigDetails(ctx, svc.Namespace, config.BackendTLSSecret, certificates, k8sClient, logger)
+			if err != nil {
+				return errors.Wrapf(err, "Could not set up backend TLS of backend set %s", name)
+			}
+			ssl.VerifyPeerCertificate = utils.PtrToBool(false)
+			backendSet.SslConfiguration = ssl
+		}
+		backendSets[name] = backendSet
+	}
+	return nil
+}
+
+// BackendTLSSecrets lists the backend TLS secrets of the ingress, set on its paths or on services it routes to
+func BackendTLSSecrets(ctx context.Context, reader k8sclient.Reader, ing *networking.Ingress) sets.String {
+	secrets := sets.NewString()
+	pathHealthChecks, _ := getPathHealthChecks(ing)
+	for _, config := range pathHealthChecks {
+		if config.BackendTLSSecret != "" {
+			secrets.Insert(config.BackendTLSSecret)
+		}
+	}
+	svcNames := sets.NewString()
+	if backend := ing.Spec.DefaultBackend; backend != nil && backend.Service != nil {
+		svcNames.Insert(backend.Service.Name)
+	}
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service != nil {
+				svcNames.Insert(path.Backend.Service.Name)
+			}
+		}
+	}
+	for _, svcName := range svcNames.List() {
+		svc := &corev1.Service{}
+		if err := reader.Get(ctx, types.NamespacedName{Namespace: ing.Namespace, Name: svcName}, svc); err != nil {
+			continue
+		}
+		if secret := GetAnnotation(svc, AnnotationBackendTLSSecret); secret != "" {
+			secrets.Insert(secret)
+		}
+	}
+	return secrets
+}
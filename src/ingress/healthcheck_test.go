@@ -0,0 +1,126 @@
+package ingress
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	. "github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/utils"
+	"github.com/oracle/oci-go-sdk/v46/loadbalancer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSetupHealthChecks(t *testing.T) {
+	defaultHealthChecker := func() *loadbalancer.HealthCheckerDetails {
+		return &loadbalancer.HealthCheckerDetails{
+			Protocol:         utils.PtrToString("HTTP"),
+			UrlPath:          utils.PtrToString("/healthz"),
+			Port:             utils.PtrToInt(10256),
+			Retries:          utils.PtrToInt(3),
+			IntervalInMillis: utils.PtrToInt(10000),
+			TimeoutInMillis:  utils.PtrToInt(3000),
+		}
+	}
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web", Annotations: map[string]string{
+		IngressAnnotationPrefix + AnnotationHealthCheckPath:       "/ready",
+		IngressAnnotationPrefix + AnnotationHealthCheckStatusCode: "204",
+	}}}
+	backendSets := map[string]loadbalancer.BackendSetDetails{
+		"web-80":  {HealthChecker: defaultHealthChecker()},
+		"web-443": {HealthChecker: defaultHealthChecker()},
+	}
+	overrides := map[string]HealthCheckConfig{"web-443": {Protocol: "TCP", Port: 8443}}
+
+	err := setupHealthChecks(context.Background(), svc, backendSets, overrides, nil, nil, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, &loadbalancer.HealthCheckerDetails{
+		Protocol:         utils.PtrToString("HTTP"),
+		UrlPath:          utils.PtrToString("/ready"),
+		Port:             utils.PtrToInt(0),
+		ReturnCode:       utils.PtrToInt(204),
+		Retries:          utils.PtrToInt(3),
+		IntervalInMillis: utils.PtrToInt(10000),
+		TimeoutInMillis:  utils.PtrToInt(3000),
+	}, backendSets["web-80"].HealthChecker)
+	// TCP health checks have no path nor status code, even if the service sets them
+	assert.Equal(t, &loadbalancer.HealthCheckerDetails{
+		Protocol:         utils.PtrToString("TCP"),
+		Port:             utils.PtrToInt(8443),
+		Retries:          utils.PtrToInt(3),
+		IntervalInMillis: utils.PtrToInt(10000),
+		TimeoutInMillis:  utils.PtrToInt(3000),
+	}, backendSets["web-443"].HealthChecker)
+
+	svc.Annotations[IngressAnnotationPrefix+AnnotationHealthCheckProtocol] = "UDP"
+	err = setupHealthChecks(context.Background(), svc, backendSets, nil, nil, nil, zap.NewNop())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "oci-health-check-protocol")
+}
+
+func TestSetupHealthChecksBackendTLS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "web.default.svc"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-backend-tls"},
+		Type:       corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+			corev1.TLSPrivateKeyKey: pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}),
+		},
+	}).Build()
+
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"}}
+	healthChecker := &loadbalancer.HealthCheckerDetails{Protocol: utils.PtrToString("HTTP"), UrlPath: utils.PtrToString("/healthz"), Port: utils.PtrToInt(10256)}
+	backendSets := map[string]loadbalancer.BackendSetDetails{
+		"web-80":  {HealthChecker: healthChecker},
+		"web-443": {HealthChecker: healthChecker},
+	}
+	overrides := map[string]HealthCheckConfig{"web-443": {BackendTLSSecret: "web-backend-tls"}}
+	certificates := map[string]loadbalancer.CertificateDetails{}
+
+	err = setupHealthChecks(context.Background(), svc, backendSets, overrides, certificates, k8sClient, zap.NewNop())
+	require.NoError(t, err)
+	assert.Nil(t, backendSets["web-80"].SslConfiguration)
+	assert.Equal(t, healthChecker, backendSets["web-80"].HealthChecker)
+
+	// Traffic and health checks of the backend set both use TLS, so backends are checked on their own port
+	ssl := backendSets["web-443"].SslConfiguration
+	require.NotNil(t, ssl)
+	require.Len(t, certificates, 1)
+	for name := range certificates {
+		assert.Equal(t, name, *ssl.CertificateName)
+		assert.Contains(t, name, "default_web-backend-tls_")
+	}
+	assert.Equal(t, false, *ssl.VerifyPeerCertificate)
+	assert.Equal(t, 0, *backendSets["web-443"].HealthChecker.Port)
+	assert.Equal(t, "/", *backendSets["web-443"].HealthChecker.UrlPath)
+
+	overrides["web-443"] = HealthCheckConfig{BackendTLSSecret: "missing"}
+	err = setupHealthChecks(context.Background(), svc, backendSets, overrides, certificates, k8sClient, zap.NewNop())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "backend TLS of backend set web-443")
+}
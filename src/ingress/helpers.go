@@ -13,6 +13,13 @@ import (
 
 const DummyBackendSetName = "dummy"
 
+// Names of listeners not derived from a host
+const (
+	httpsRedirectorListenerName = "http-to-https-redirector"
+	defaultBackendListenerName  = "DefaultBackend-http"
+	sansVirtualHostListenerName = "Sans-VirtualHost-HTTP"
+)
+
 func createListenerDetails(ing *networking.Ingress, hostnameDetails *loadbalancer.HostnameDetails, sslConfigDetails *loadbalancer.SslConfigurationDetails) (string, loadbalancer.ListenerDetails) {
 	var protocol string
 	var port int
@@ -72,7 +79,7 @@ func createListenerDetailsAndRulesetDetailsForHTTPSRedirect(hostnameNames []stri
 			},
 		},
 	}
-	listenerName = "http-to-https-redirector"
+	listenerName = httpsRedirectorListenerName
 	listener = loadbalancer.ListenerDetails{
 		// .DefaultBackendSetName must not be null
 		DefaultBackendSetName: utils.PtrToString(DummyBackendSetName),
@@ -85,7 +92,7 @@ func createListenerDetailsAndRulesetDetailsForHTTPSRedirect(hostnameNames []stri
 }
 
 func createDefaultBackendListenerDetails(targetBackendSetName string) (listenerName string, listener loadbalancer.ListenerDetails) {
-	listenerName = "DefaultBackend-http"
+	listenerName = defaultBackendListenerName
 	listener = loadbalancer.ListenerDetails{
 		// .defaultBackendSetName must not be null
 		DefaultBackendSetName: utils.PtrToString(targetBackendSetName),
@@ -99,7 +106,7 @@ func createDefaultBackendListenerDetails(targetBackendSetName string) (listenerN
 // createSansVirtualHostListenerDetails creates ListenerDetails for default listener
 // that will handle requests that does not match Host value
 func createSansVirtualHostListenerDetails() (listenerName string, listener loadbalancer.ListenerDetails) {
-	listenerName = "Sans-VirtualHost-HTTP"
+	listenerName = sansVirtualHostListenerName
 	listener = loadbalancer.ListenerDetails{
 		// .defaultBackendSetName must not be null
 		DefaultBackendSetName: utils.PtrToString(DummyBackendSetName),
@@ -115,12 +122,7 @@ const lbNamePrefixEnvVar = "LOAD_BALANCER_PREFIX"
 // GetLoadBalancerName gets the name of the load balancer based on the Ingress
 func GetLoadBalancerName(namespace string, ingressName string) string {
 	// namespace, ingressName will be valid DNS label (63 chars max)
-	prefix := os.Getenv(lbNamePrefixEnvVar)
-	if prefix != "" && !strings.HasSuffix(prefix, "_") {
-		// Add the trailing hyphen if it's missing
-		prefix += "_"
-	}
-	name := fmt.Sprintf("%s%s_%s", prefix, namespace, ingressName)
+	name := fmt.Sprintf("%s%s_%s", getLoadBalancerNamePrefix(), namespace, ingressName)
 
 	if len(name) > 1024 {
 		// 1024 is the max length for display name
@@ -131,6 +133,21 @@ func GetLoadBalancerName(namespace string, ingressName string) string {
 	return name
 }
 
+// GetGroupLoadBalancerName gets the name of the load balancer shared by ingresses of a group
+func GetGroupLoadBalancerName(group string) string {
+	// group is a DNS label. Having no "_", it can't collide with names of load balancers of single ingresses
+	return getLoadBalancerNamePrefix() + "group--" + group
+}
+
+func getLoadBalancerNamePrefix() string {
+	prefix := os.Getenv(lbNamePrefixEnvVar)
+	if prefix != "" && !strings.HasSuffix(prefix, "_") {
+		// Add the trailing hyphen if it's missing
+		prefix += "_"
+	}
+	return prefix
+}
+
 func getRoutingPolicyName(hostname string) string {
 	// name must match "^[a-zA-Z_][a-zA-Z_0-9]{0,31}$"; name size must be between 1 and 32
 	name := strings.ToLower(hostname)
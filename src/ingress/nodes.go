@@ -0,0 +1,49 @@
+package ingress
+
+import (
+	"context"
+
+	. "github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// BackendNodeSelector is the label selector nodes must match to be backends. Objects may narrow it with AnnotationBackendNodeSelector.
+var BackendNodeSelector = ""
+
+func getBackendNodeSelector(obj AnnotatedObject) (labels.Selector, error) {
+	selector, err := labels.Parse(GetAnnotation(obj, AnnotationBackendNodeSelector))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid node selector %q", GetAnnotation(obj, AnnotationBackendNodeSelector))
+	}
+	return selector, nil
+}
+
+// listBackendNodes lists the nodes backend sets of the object may point at: eligible ones (see IsNodeEligibleBackend)
+// matching both the global and the object node selectors. Draining nodes are listed too.
+func listBackendNodes(ctx context.Context, k8sClient k8sclient.Client, obj AnnotatedObject) ([]corev1.Node, error) {
+	globalSelector, err := labels.Parse(BackendNodeSelector)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid global node selector %q", BackendNodeSelector)
+	}
+	selector, err := getBackendNodeSelector(obj)
+	if err != nil {
+		return nil, err
+	}
+	nodeList := &corev1.NodeList{}
+	if err := k8sClient.List(ctx, nodeList); err != nil {
+		return nil, errors.Wrapf(err, "Couldn't list nodes")
+	}
+	var nodes []corev1.Node
+	for i := range nodeList.Items {
+		node := &nodeList.Items[i]
+		nodeLabels := labels.Set(node.Labels)
+		if !globalSelector.Matches(nodeLabels) || !selector.Matches(nodeLabels) || !IsNodeEligibleBackend(node) {
+			continue
+		}
+		nodes = append(nodes, *node)
+	}
+	return nodes, nil
+}
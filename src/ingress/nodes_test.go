@@ -0,0 +1,49 @@
+package ingress
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestListBackendNodes(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	node := func(name string, ready corev1.ConditionStatus, labels map[string]string, mutate func(*corev1.Node)) *corev1.Node {
+		n := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}}},
+		}
+		if mutate != nil {
+			mutate(n)
+		}
+		return n
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		node("web", corev1.ConditionTrue, map[string]string{"pool": "web"}, nil),
+		node("web-cordoned", corev1.ConditionTrue, map[string]string{"pool": "web"}, func(n *corev1.Node) { n.Spec.Unschedulable = true }),
+		node("web-notready", corev1.ConditionFalse, map[string]string{"pool": "web"}, nil),
+		node("web-excluded", corev1.ConditionTrue, map[string]string{"pool": "web", corev1.LabelNodeExcludeBalancers: ""}, nil),
+		node("control-plane", corev1.ConditionTrue, map[string]string{"pool": "web", "node-role.kubernetes.io/control-plane": ""}, nil),
+		node("batch", corev1.ConditionTrue, map[string]string{"pool": "batch"}, nil),
+	).Build()
+	ing := &networking.Ingress{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{IngressAnnotationPrefix + AnnotationBackendNodeSelector: "pool=web"}}}
+
+	nodes, err := listBackendNodes(context.Background(), k8sClient, ing)
+	require.NoError(t, err)
+	var names []string
+	for i := range nodes {
+		names = append(names, nodes[i].Name)
+		assert.Equal(t, nodes[i].Name == "web-cordoned", IsNodeDraining(&nodes[i]), nodes[i].Name)
+	}
+	assert.ElementsMatch(t, []string{"web", "web-cordoned"}, names)
+}
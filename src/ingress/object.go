@@ -2,17 +2,22 @@ package ingress
 
 import (
 	networking "k8s.io/api/networking/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 var OCILoadbalancerIngressClass = "oci"
 
 const (
 	KubernetesIngressClassAnnotation = "kubernetes.io/ingress.class"
+
+	// IngressFinalizer is added to every ingress managed by this controller. It is removed only after the associated
+	// OCI load balancer is deleted, so that the load balancer is not leaked if the controller misses the delete event.
+	IngressFinalizer = "ingress.beta.kubernetes.io/oci-load-balancer-cleanup"
 )
 
-// IsOCILoadbalancerIngress returns true if an ingress object has the OCI ingress class
-func IsOCILoadbalancerIngress(ingress *networking.Ingress) bool {
-	return GetIngressClassName(ingress) == OCILoadbalancerIngressClass
+// HasIngressFinalizer returns true if the ingress object is (or was) managed by this controller
+func HasIngressFinalizer(ingress *networking.Ingress) bool {
+	return controllerutil.ContainsFinalizer(ingress, IngressFinalizer)
 }
 
 func GetIngressClassName(ingress *networking.Ingress) string {
@@ -24,3 +29,28 @@ func GetIngressClassName(ingress *networking.Ingress) string {
 	}
 	return ""
 }
+
+// RefersToService returns true if a backend of the ingress is the service, in the namespace of the ingress. Services
+// traffic is split to with AnnotationPathBackendWeights are backends too.
+func RefersToService(ingress *networking.Ingress, svcName string) bool {
+	if backend := ingress.Spec.DefaultBackend; backend != nil && backend.Service != nil && backend.Service.Name == svcName {
+		return true
+	}
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service != nil && path.Backend.Service.Name == svcName {
+				return true
+			}
+		}
+	}
+	weights, _ := getPathBackendWeights(ingress)
+	for _, pathWeights := range weights {
+		if _, found := pathWeights[svcName]; found {
+			return true
+		}
+	}
+	return false
+}
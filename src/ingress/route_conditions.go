@@ -0,0 +1,126 @@
+package ingress
+
+import (
+	. "github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/utils"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+)
+
+// Types of RouteValueMatch
+const (
+	RouteMatchExact   = "Exact"
+	RouteMatchPrefix  = "Prefix"
+	RouteMatchPresent = "Present"
+)
+
+// RouteConditions are conditions requests of an ingress path must meet too, as set by AnnotationRouteConditions
+type RouteConditions struct {
+	// Service narrows the conditions to the path routing to the service, if the path routes to more than one
+	Service     string            `json:"service,omitempty"`
+	Headers     []RouteValueMatch `json:"headers,omitempty"`
+	QueryParams []RouteValueMatch `json:"queryParams,omitempty"`
+	Cookies     []RouteValueMatch `json:"cookies,omitempty"`
+	// SourceIPs can't be matched by OCI routing policies. They are rejected, rather than silently ignored.
+	SourceIPs []string `json:"sourceIPs,omitempty"`
+}
+
+// RouteValueMatch matches a header, query parameter or cookie by name. Header names are case-insensitive.
+type RouteValueMatch struct {
+	Name  string `json:"name"`
+	Type  string `json:"type,omitempty"` // Exact (default), Prefix or Present
+	Value string `json:"value,omitempty"`
+}
+
+// getRouteConditions reads the route conditions of ingress paths, by path
+func getRouteConditions(ing *networking.Ingress) (map[string]RouteConditions, error) {
+	routeConditions := map[string]RouteConditions{}
+	value := GetAnnotation(ing, AnnotationRouteConditions)
+	if value == "" {
+		return routeConditions, nil
+	}
+	if err := yaml.UnmarshalStrict([]byte(value), &routeConditions); err != nil {
+		return nil, err
+	}
+	return routeConditions, nil
+}
+
+// appliesTo tells whether the conditions apply to the path routing to the backend
+func (rc RouteConditions) appliesTo(backend networking.IngressBackend) bool {
+	return rc.Service == "" || backend.Service != nil && backend.Service.Name == rc.Service
+}
+
+// compile translates the route conditions into routing policy conditions
+func (rc RouteConditions) compile() []string {
+	var conditions []string
+	for _, header := range rc.Headers {
+		conditions = append(conditions, createKeyEqualCondition("http.request.headers", Literal{Value: header.Name, CaseInsensitive: true}, header.Value))
+	}
+	for _, param := range rc.QueryParams {
+		conditions = append(conditions, createKeyEqualCondition("http.request.url.query", Literal{Value: param.Name}, param.Value))
+	}
+	for _, cookie := range rc.Cookies {
+		conditions = append(conditions, createKeyEqualCondition("http.request.cookies", Literal{Value: cookie.Name}, cookie.Value))
+	}
+	return conditions
+}
+
+// validate reports route conditions that can't be compiled into routing policy conditions
+func (rc RouteConditions) validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for _, kind := range []struct {
+		name    string
+		matches []RouteValueMatch
+	}{{"headers", rc.Headers}, {"queryParams", rc.QueryParams}, {"cookies", rc.Cookies}} {
+		for i, match := range kind.matches {
+			matchPath := path.Child(kind.name).Index(i)
+			if match.Name == "" {
+				errs = append(errs, field.Required(matchPath.Child("name"), ""))
+			}
+			switch match.Type {
+			case "", RouteMatchExact:
+			case RouteMatchPrefix:
+				// Values of headers, query parameters and cookies are lists to OCI. It only compares them for equality.
+				errs = append(errs, field.Forbidden(matchPath.Child("type"), "OCI routing policies can't match prefixes of "+kind.name))
+			case RouteMatchPresent:
+				errs = append(errs, field.Forbidden(matchPath.Child("type"), "OCI routing policies can't match presence of "+kind.name))
+			default:
+				errs = append(errs, field.NotSupported(matchPath.Child("type"), match.Type, []string{RouteMatchExact}))
+			}
+			if err := checkConditionValue(match.Name + match.Value); err != nil {
+				errs = append(errs, field.Invalid(matchPath, match.Name+"="+match.Value, err.Error()))
+			}
+		}
+	}
+	if len(rc.SourceIPs) > 0 {
+		errs = append(errs, field.Forbidden(path.Child("sourceIPs"), "OCI routing policies can't match source IPs"))
+	}
+	return errs
+}
+
+func validateRouteConditions(ing *networking.Ingress, path *field.Path) field.ErrorList {
+	routeConditions, err := getRouteConditions(ing)
+	if err != nil {
+		return field.ErrorList{field.Invalid(path, GetAnnotation(ing, AnnotationRouteConditions), "must be YAML of route conditions by path: "+err.Error())}
+	}
+	var errs field.ErrorList
+	for _, ingPath := range utils.StringKeys(routeConditions).List() {
+		rc := routeConditions[ingPath]
+		conditionsPath := path.Key(ingPath)
+		matched := false
+		for _, rule := range ing.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, p := range rule.HTTP.Paths {
+				matched = matched || p.Path == ingPath && rc.appliesTo(p.Backend)
+			}
+		}
+		if !matched {
+			errs = append(errs, field.NotFound(conditionsPath, ingPath))
+		}
+		errs = append(errs, rc.validate(conditionsPath)...)
+	}
+	return errs
+}
@@ -0,0 +1,67 @@
+package ingress
+
+import (
+	"testing"
+
+	. "github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestRouteConditions(t *testing.T) {
+	ing := newValidationTestIngress(map[string]string{AnnotationRouteConditions: `
+/api:
+  headers:
+  - name: X-Canary
+    value: "true"
+  queryParams:
+  - name: version
+    value: v2
+  cookies:
+  - name: canary
+    value: always
+`}, networking.PathTypeExact, "/api")
+	assert.Empty(t, ValidateIngress(ing))
+
+	routeConditions, err := getRouteConditions(ing)
+	require.NoError(t, err)
+	rule, err := createRoutingRule(ing.Spec.Rules[0].HTTP.Paths[0], "web", "", routeConditions["/api"].compile()...)
+	require.NoError(t, err)
+	assert.Equal(t, "all(http.request.url.path eq '/api', "+
+		"http.request.headers[(i 'X-Canary')] eq 'true', "+
+		"http.request.url.query['version'] eq 'v2', "+
+		"http.request.cookies['canary'] eq 'always')", *rule.Condition)
+}
+
+func TestValidateRouteConditions(t *testing.T) {
+	ing := newValidationTestIngress(map[string]string{AnnotationRouteConditions: `
+/:
+  service: other
+/api:
+  headers:
+  - name: User-Agent
+    type: Prefix
+    value: Mozilla
+  - name: X-Quote
+    value: "it's"
+  queryParams:
+  - name: debug
+    type: Present
+  sourceIPs: [10.0.0.0/8]
+`}, networking.PathTypePrefix, "/api")
+	ing.Spec.Rules[0].HTTP.Paths = append(ing.Spec.Rules[0].HTTP.Paths, networking.HTTPIngressPath{Path: "/", PathType: ing.Spec.Rules[0].HTTP.Paths[0].PathType, Backend: ing.Spec.Rules[0].HTTP.Paths[0].Backend})
+
+	var fields []string
+	for _, err := range validateRouteConditions(ing, field.NewPath("conditions")) {
+		fields = append(fields, err.Field)
+	}
+	assert.Equal(t, []string{
+		"conditions[/]",
+		"conditions[/api].headers[0].type",
+		"conditions[/api].headers[1]",
+		"conditions[/api].queryParams[0].type",
+		"conditions[/api].sourceIPs",
+	}, fields)
+}
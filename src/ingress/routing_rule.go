@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/url"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/nom3ad/oci-lb-ingress-controller/src/utils"
@@ -12,8 +13,10 @@ import (
 	networking "k8s.io/api/networking/v1"
 )
 
+const defaultBackendRoutingRuleName = "default-backend-route"
+
 func createDefaultBackendRoutingRule(backendSetName string) (*loadbalancer.RoutingRule, error) {
-	ruleName := "default-backend-route"
+	ruleName := defaultBackendRoutingRuleName
 	condition := fmt.Sprintf("all(http.request.url.path sw '%s')", "/")
 	return &loadbalancer.RoutingRule{
 		Name:      &ruleName,
@@ -26,7 +29,9 @@ func createDefaultBackendRoutingRule(backendSetName string) (*loadbalancer.Routi
 	}, nil
 }
 
-func createRoutingRule(ingresPath networking.HTTPIngressPath, backendSetName string, host string) (*loadbalancer.RoutingRule, error) {
+// createRoutingRule creates the rule forwarding requests for the host and path to the backend set. Requests must meet
+// the extra conditions too.
+func createRoutingRule(ingresPath networking.HTTPIngressPath, backendSetName string, host string, extraConditions ...string) (*loadbalancer.RoutingRule, error) {
 	ruleName := utils.ObjectHash(ingresPath, 22) // max 32 , ^[a-zA-Z_][a-zA-Z_0-9]*$
 	path := ingresPath.Path
 	// https://docs.oracle.com/en-us/iaas/Content/Balance/Concepts/routing_policy_conditions.htm
@@ -38,37 +43,23 @@ func createRoutingRule(ingresPath networking.HTTPIngressPath, backendSetName str
 	}
 	switch *ingresPath.PathType {
 	case networking.PathTypeExact:
-		exactPathMatchCondition := fmt.Sprintf("http.request.url.path eq '%s'", path)
-		conditions = append(conditions, exactPathMatchCondition)
+		conditions = append(conditions, createExactPathCondition(path))
 	case networking.PathTypePrefix:
-		prefixPathMatchCondition := fmt.Sprintf("http.request.url.path sw '%s'", path)
-		conditions = append(conditions, prefixPathMatchCondition)
+		conditions = append(conditions, createPrefixPathCondition(path))
 	case networking.PathTypeImplementationSpecific:
 		customCondition, err := processImplementationSpecificPath(path)
 		if err != nil {
 			return nil, err
 		}
-		// https://docs.oracle.com/en-us/iaas/Content/Balance/Concepts/routing_policy_conditions.htm#routing_policy_conditions_conditions
-		if regexp.MustCompile(`^(not )?(any|all)\(`).MatchString(customCondition) {
-			// customCondition is already using multiple predicates. So use as
-			// TODO: inject hostnameCondition to customCondition by proper parsing
-			if matches := regexp.MustCompile(`^all\((.*)\)$`).FindStringSubmatch(customCondition); len(matches) > 1 {
-				conditions = append(conditions, strings.Split(matches[1], ",")...)
-			} else {
-				conditions = []string{customCondition}
-			}
-		} else {
-			conditions = append(conditions, customCondition)
-		}
+		conditions = append(conditions, customCondition)
 	default:
 		return nil, errors.Errorf("Unknown ingress path type: %s", *ingresPath.PathType)
 	}
+	conditions = append(conditions, extraConditions...)
 
-	var condition string
-	if len(conditions) == 1 {
-		condition = conditions[0]
-	} else {
-		condition = fmt.Sprintf("all(%s)", strings.Join(conditions, ","))
+	condition, err := combineConditions(conditions...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid routing rule of path %q", path)
 	}
 	return &loadbalancer.RoutingRule{
 		Name:      &ruleName,
@@ -81,6 +72,135 @@ func createRoutingRule(ingresPath networking.HTTPIngressPath, backendSetName str
 	}, nil
 }
 
+func createExactPathCondition(path string) string {
+	return "http.request.url.path eq " + Literal{Value: path}.String()
+}
+
+// createPrefixPathCondition matches the path element-wise, like Kubernetes does: /foo matches /foo and /foo/bar, but not
+// /foobar. Trailing slashes of the prefix are ignored.
+func createPrefixPathCondition(path string) string {
+	path = strings.TrimRight(path, "/")
+	if path == "" {
+		return "http.request.url.path sw '/'"
+	}
+	return fmt.Sprintf("any(%s, http.request.url.path sw %s)", createExactPathCondition(path), Literal{Value: path + "/"})
+}
+
+// routingRulePrecedence ranks routing rules the way Kubernetes ranks ingress paths
+type routingRulePrecedence struct {
+	fallback        bool // the default backend rule, which stays last
+	hostConditioned bool
+	pathLength      int // of the longest path matched, without trailing slashes
+	exact           bool
+	conditions      int // other than the host and path ones
+}
+
+func (p routingRulePrecedence) precedes(o routingRulePrecedence) bool {
+	if p.fallback != o.fallback {
+		return o.fallback
+	}
+	if p.hostConditioned != o.hostConditioned {
+		return p.hostConditioned
+	}
+	if p.pathLength != o.pathLength {
+		return p.pathLength > o.pathLength
+	}
+	if p.exact != o.exact {
+		return p.exact
+	}
+	return p.conditions > o.conditions
+}
+
+// getRoutingRulePrecedence ranks the rule by the host and path conditions createRoutingRule puts in it
+func getRoutingRulePrecedence(rule loadbalancer.RoutingRule) routingRulePrecedence {
+	precedence := routingRulePrecedence{fallback: *rule.Name == defaultBackendRoutingRuleName}
+	condition, err := ParseCondition(*rule.Condition)
+	if err != nil {
+		return precedence
+	}
+	conditions := []Condition{condition}
+	if all, ok := condition.(*LogicalCondition); ok && all.Operator == ConditionAll {
+		conditions = all.Conditions
+	}
+	for _, c := range conditions {
+		if isHostCondition(c) {
+			precedence.hostConditioned = true
+			continue
+		}
+		length, exact, ok := getPathConditionLength(c)
+		if !ok {
+			precedence.conditions++
+		} else if length > precedence.pathLength || length == precedence.pathLength && exact {
+			precedence.pathLength, precedence.exact = length, exact
+		}
+	}
+	return precedence
+}
+
+// getPathConditionLength returns the length of the path matched by an exact or prefix path condition
+func getPathConditionLength(c Condition) (length int, exact bool, ok bool) {
+	switch c := c.(type) {
+	case *Predicate:
+		if c.Attribute != "http.request.url.path" || c.Negated || c.Values[0].CaseInsensitive {
+			return 0, false, false
+		}
+		switch c.Matcher {
+		case MatcherEqual:
+			return len(c.Values[0].Value), true, true
+		case MatcherStartsWith:
+			return len(strings.TrimRight(c.Values[0].Value, "/")), false, true
+		}
+	case *LogicalCondition:
+		if c.Operator != ConditionAny {
+			return 0, false, false
+		}
+		for _, child := range c.Conditions {
+			childLength, _, childOk := getPathConditionLength(child)
+			if !childOk {
+				return 0, false, false
+			}
+			if childLength > length {
+				length = childLength
+			}
+		}
+		return length, false, true
+	}
+	return 0, false, false
+}
+
+func isHostCondition(c Condition) bool {
+	switch c := c.(type) {
+	case *Predicate:
+		return c.Attribute == "http.request.headers" && c.Key != nil && strings.EqualFold(c.Key.Value, "Host") && !c.Negated
+	case *LogicalCondition:
+		for _, child := range c.Conditions {
+			if !isHostCondition(child) {
+				return false
+			}
+		}
+		return c.Operator == ConditionAny
+	}
+	return false
+}
+
+// sortRoutingRules orders the rules by Kubernetes precedence: rules of precise hosts first, longer paths first, exact paths
+// before prefixes of the same length, and rules with more conditions before the others. The default backend rule is
+// last. Rules of the same precedence keep their order.
+func sortRoutingRules(rules []loadbalancer.RoutingRule) {
+	type rankedRule struct {
+		rule       loadbalancer.RoutingRule
+		precedence routingRulePrecedence
+	}
+	ranked := make([]rankedRule, len(rules))
+	for i, rule := range rules {
+		ranked[i] = rankedRule{rule: rule, precedence: getRoutingRulePrecedence(rule)}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].precedence.precedes(ranked[j].precedence) })
+	for i := range ranked {
+		rules[i] = ranked[i].rule
+	}
+}
+
 func createHostnameCondition(hostname string) string {
 	// Form Kubernetes documentation:
 	// .........
@@ -104,11 +224,12 @@ func createHostnameCondition(hostname string) string {
 	if strings.HasPrefix(hostname, "*.") {
 		// return fmt.Sprintf("http.request.headers[(i 'Host')] ew (i '%s')", host[2:])
 		//XXX: OCI LB does not support ew/sw matchers on Map Type values. Gets an err: invalid operand types for matcher IREndsWithMatcher, left: IRStringListValue, right: IRStringValue
-		// But it would be okay to not have Host header matching rule, because we have listener configured with correct wildcard hostname
+		// The listener of the wildcard hostname matches the host instead. It matches hosts of more than one label in place of
+		// the wildcard too, which is reported by WildcardHostWarnings().
 		return ""
 	}
 	hostHeaderMatch := func(h string) string {
-		return fmt.Sprintf("http.request.headers[(i 'Host')] eq (i '%s')", h)
+		return "http.request.headers[(i 'Host')] eq " + Literal{Value: h, CaseInsensitive: true}.String()
 	}
 	return fmt.Sprintf("any(%s, %s, %s)", hostHeaderMatch(hostname), hostHeaderMatch(hostname+":443"), hostHeaderMatch(hostname+":80"))
 }
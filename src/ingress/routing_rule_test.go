@@ -2,9 +2,12 @@ package ingress
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/oracle/oci-go-sdk/v46/loadbalancer"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	networking "k8s.io/api/networking/v1"
 )
 
@@ -69,8 +72,8 @@ func TestProcessImplementationSpecificPath(t *testing.T) {
 
 func TestCreateHostnameCondition(t *testing.T) {
 	expectations := map[string]string{
-		"foo":             "http.request.headers[(i 'Host')] eq (i 'foo')",
-		"www.example.com": "http.request.headers[(i 'Host')] eq (i 'www.example.com')",
+		"foo":             "any(http.request.headers[(i 'Host')] eq (i 'foo'), http.request.headers[(i 'Host')] eq (i 'foo:443'), http.request.headers[(i 'Host')] eq (i 'foo:80'))",
+		"www.example.com": "any(http.request.headers[(i 'Host')] eq (i 'www.example.com'), http.request.headers[(i 'Host')] eq (i 'www.example.com:443'), http.request.headers[(i 'Host')] eq (i 'www.example.com:80'))",
 		// "*.example.com":   "http.request.headers[(i 'Host')] ew (i 'example.com')",  this invalid rule.
 		"*.example.com": "", // see comment at function definition
 	}
@@ -93,19 +96,22 @@ func TestCreateRoutingRule(t *testing.T) {
 		action    string
 	}{
 		// PathTypePrefix
-		{host: "www.example.com", path: "/page", pathType: networking.PathTypePrefix, backendSetName: "nginx", condition: "all(http.request.headers[(i 'Host')] eq (i 'www.example.com'),http.request.url.path sw '/page')", action: "{ BackendSetName=nginx }"},
+		{host: "www.example.com", path: "/page", pathType: networking.PathTypePrefix, backendSetName: "nginx", condition: "all(any(http.request.headers[(i 'Host')] eq (i 'www.example.com'), http.request.headers[(i 'Host')] eq (i 'www.example.com:443'), http.request.headers[(i 'Host')] eq (i 'www.example.com:80')), any(http.request.url.path eq '/page', http.request.url.path sw '/page/'))", action: "{ BackendSetName=nginx }"},
 		// PathTypeExact
-		{host: "api.example.com", path: "/result/all/", pathType: networking.PathTypeExact, backendSetName: "api", condition: "all(http.request.headers[(i 'Host')] eq (i 'api.example.com'),http.request.url.path eq '/result/all/')", action: "{ BackendSetName=api }"},
+		{host: "api.example.com", path: "/result/all/", pathType: networking.PathTypeExact, backendSetName: "api", condition: "all(any(http.request.headers[(i 'Host')] eq (i 'api.example.com'), http.request.headers[(i 'Host')] eq (i 'api.example.com:443'), http.request.headers[(i 'Host')] eq (i 'api.example.com:80')), http.request.url.path eq '/result/all/')", action: "{ BackendSetName=api }"},
 
 		// PathTypeImplementationSpecific
-		{host: "api.example.com", path: "/result/get/*", pathType: networking.PathTypeImplementationSpecific, backendSetName: "api", condition: "all(http.request.headers[(i 'Host')] eq (i 'api.example.com'),http.request.url.path sw '/result/get/')", action: "{ BackendSetName=api }"},
-		{host: "api.example.com", path: "condition:http.request.cookies['cookie-name'] not eq 'cookie-value'", pathType: networking.PathTypeImplementationSpecific, backendSetName: "api", condition: "all(http.request.headers[(i 'Host')] eq (i 'api.example.com'),http.request.cookies['cookie-name'] not eq 'cookie-value')", action: "{ BackendSetName=api }"},
-		{host: "api.example.com", path: "condition:all(http.request.headers[(i 'user-agent')] eq (i 'mobile'), http.request.url.query['department'] eq 'HR')", pathType: networking.PathTypeImplementationSpecific, backendSetName: "api", condition: "all(http.request.headers[(i 'Host')] eq (i 'api.example.com'),http.request.headers[(i 'user-agent')] eq (i 'mobile'), http.request.url.query['department'] eq 'HR')", action: "{ BackendSetName=api }"},
-		{host: "api.example.com", path: "condition:any(http.request.url.path sw '/category', http.request.url.path ew '/id')", pathType: networking.PathTypeImplementationSpecific, backendSetName: "api", condition: "any(http.request.url.path sw '/category', http.request.url.path ew '/id')", action: "{ BackendSetName=api }"},
+		{host: "api.example.com", path: "/result/get/*", pathType: networking.PathTypeImplementationSpecific, backendSetName: "api", condition: "all(any(http.request.headers[(i 'Host')] eq (i 'api.example.com'), http.request.headers[(i 'Host')] eq (i 'api.example.com:443'), http.request.headers[(i 'Host')] eq (i 'api.example.com:80')), http.request.url.path sw '/result/get/')", action: "{ BackendSetName=api }"},
+		{host: "api.example.com", path: "condition:http.request.cookies['cookie-name'] not eq 'cookie-value'", pathType: networking.PathTypeImplementationSpecific, backendSetName: "api", condition: "all(any(http.request.headers[(i 'Host')] eq (i 'api.example.com'), http.request.headers[(i 'Host')] eq (i 'api.example.com:443'), http.request.headers[(i 'Host')] eq (i 'api.example.com:80')), http.request.cookies['cookie-name'] not eq 'cookie-value')", action: "{ BackendSetName=api }"},
+		{host: "api.example.com", path: "condition:all(http.request.headers[(i 'user-agent')] eq (i 'mobile'), http.request.url.query['department'] eq 'HR')", pathType: networking.PathTypeImplementationSpecific, backendSetName: "api", condition: "all(any(http.request.headers[(i 'Host')] eq (i 'api.example.com'), http.request.headers[(i 'Host')] eq (i 'api.example.com:443'), http.request.headers[(i 'Host')] eq (i 'api.example.com:80')), http.request.headers[(i 'user-agent')] eq (i 'mobile'), http.request.url.query['department'] eq 'HR')", action: "{ BackendSetName=api }"},
+		{host: "api.example.com", path: "condition:any(http.request.url.path sw '/category', http.request.url.path ew '/id')", pathType: networking.PathTypeImplementationSpecific, backendSetName: "api", condition: "all(any(http.request.headers[(i 'Host')] eq (i 'api.example.com'), http.request.headers[(i 'Host')] eq (i 'api.example.com:443'), http.request.headers[(i 'Host')] eq (i 'api.example.com:80')), any(http.request.url.path sw '/category', http.request.url.path ew '/id'))", action: "{ BackendSetName=api }"},
+
+		{host: "api.example.com", path: "condition:any(http.request.url.path sw '/category',)", pathType: networking.PathTypeImplementationSpecific, backendSetName: "api", err: "syntax error at position 42: expected a condition, found ')'"},
+		{host: "api.example.com", path: "condition:http.request.url.path eq '/it's'", pathType: networking.PathTypeImplementationSpecific, backendSetName: "api", err: "syntax error at position 32: unterminated string"},
 
 		// WildcardHost
 		// {host: "*.example.com", path: "/page", pathType: networking.PathTypePrefix, backendSetName: "nginx", condition: "all(http.request.headers[(i 'Host')] ew (i 'example.com'),http.request.url.path sw '/page')", action: "{ BackendSetName=nginx }"},
-		{host: "*.example.com", path: "/page", pathType: networking.PathTypePrefix, backendSetName: "nginx", condition: "http.request.url.path sw '/page'", action: "{ BackendSetName=nginx }"},
+		{host: "*.example.com", path: "/page", pathType: networking.PathTypePrefix, backendSetName: "nginx", condition: "any(http.request.url.path eq '/page', http.request.url.path sw '/page/')", action: "{ BackendSetName=nginx }"},
 	}
 	for i, it := range expectations {
 		// assert.Equal(t, condition, createHostnameCondition(hostname), "for host: %s", hostname)
@@ -115,7 +121,9 @@ func TestCreateRoutingRule(t *testing.T) {
 		}
 		rule, err := createRoutingRule(ingresPath, it.backendSetName, it.host)
 		if it.err != "" {
-			assert.Error(t, err, "No error for #%d", i)
+			if assert.Error(t, err, "No error for #%d", i) {
+				assert.Contains(t, err.Error(), it.err, "Error does not match for #%d", i)
+			}
 		} else {
 			if assert.NoError(t, err, "Unexpected error for #%d", i) {
 				assert.Equal(t, it.condition, *rule.Condition, "Rule Condition does not match for #%d", i)
@@ -125,3 +133,117 @@ func TestCreateRoutingRule(t *testing.T) {
 	}
 
 }
+
+// matchesRequest evaluates the condition for a request of the host and path. Other attributes never match.
+func matchesRequest(c Condition, host, path string) bool {
+	switch c := c.(type) {
+	case *LogicalCondition:
+		for _, child := range c.Conditions {
+			if matchesRequest(child, host, path) == (c.Operator == ConditionAny) {
+				return c.Operator == ConditionAny
+			}
+		}
+		return c.Operator == ConditionAll
+	case *NotCondition:
+		return !matchesRequest(c.Condition, host, path)
+	case *Predicate:
+		var value string
+		switch {
+		case c.Attribute == "http.request.url.path":
+			value = path
+		case c.Attribute == "http.request.headers" && strings.EqualFold(c.Key.Value, "Host"):
+			value = host
+		default:
+			return false
+		}
+		matched := false
+		for _, literal := range c.Values {
+			v, l := value, literal.Value
+			if literal.CaseInsensitive {
+				v, l = strings.ToLower(v), strings.ToLower(l)
+			}
+			switch c.Matcher {
+			case MatcherEqual, MatcherIn:
+				matched = matched || v == l
+			case MatcherStartsWith:
+				matched = matched || strings.HasPrefix(v, l)
+			case MatcherEndsWith:
+				matched = matched || strings.HasSuffix(v, l)
+			case MatcherContains:
+				matched = matched || strings.Contains(v, l)
+			}
+		}
+		return matched != c.Negated
+	}
+	return false
+}
+
+// TestPathMatchingConformance checks path matching of routing rules against the examples of
+// https://kubernetes.io/docs/concepts/services-networking/ingress/#examples and the path rules of
+// https://github.com/kubernetes-sigs/ingress-controller-conformance
+func TestPathMatchingConformance(t *testing.T) {
+	testCases := []struct {
+		paths   []string // "<path type> <path>", in declaration order
+		request string
+		match   string // path matched first, if any
+	}{
+		{paths: []string{"Prefix /"}, request: "/foo", match: "Prefix /"},
+		{paths: []string{"Exact /foo"}, request: "/foo", match: "Exact /foo"},
+		{paths: []string{"Exact /foo"}, request: "/bar"},
+		{paths: []string{"Exact /foo"}, request: "/foo/"},
+		{paths: []string{"Exact /foo/"}, request: "/foo"},
+		{paths: []string{"Prefix /foo"}, request: "/foo", match: "Prefix /foo"},
+		{paths: []string{"Prefix /foo"}, request: "/foo/", match: "Prefix /foo"},
+		{paths: []string{"Prefix /foo/"}, request: "/foo", match: "Prefix /foo/"},
+		{paths: []string{"Prefix /foo/"}, request: "/foo/", match: "Prefix /foo/"},
+		{paths: []string{"Prefix /aaa/bb"}, request: "/aaa/bbb"},
+		{paths: []string{"Prefix /aaa/bbb"}, request: "/aaa/bbb", match: "Prefix /aaa/bbb"},
+		{paths: []string{"Prefix /aaa/bbb/"}, request: "/aaa/bbb", match: "Prefix /aaa/bbb/"},
+		{paths: []string{"Prefix /aaa/bbb"}, request: "/aaa/bbb/", match: "Prefix /aaa/bbb"},
+		{paths: []string{"Prefix /aaa/bbb"}, request: "/aaa/bbb/ccc", match: "Prefix /aaa/bbb"},
+		{paths: []string{"Prefix /aaa/bbb"}, request: "/aaa/bbbxyz"},
+		{paths: []string{"Prefix /", "Prefix /aaa"}, request: "/aaa/ccc", match: "Prefix /aaa"},
+		{paths: []string{"Prefix /", "Prefix /aaa", "Prefix /aaa/bbb"}, request: "/aaa/bbb", match: "Prefix /aaa/bbb"},
+		{paths: []string{"Prefix /", "Prefix /aaa", "Prefix /aaa/bbb"}, request: "/ccc", match: "Prefix /"},
+		{paths: []string{"Prefix /aaa"}, request: "/ccc"},
+		{paths: []string{"Prefix /foo", "Exact /foo"}, request: "/foo", match: "Exact /foo"},
+		{paths: []string{"Prefix /foo", "Exact /foo"}, request: "/foo/bar", match: "Prefix /foo"},
+		{paths: []string{"Prefix /foo", "Prefix /foo/bar"}, request: "/foo/bar/baz", match: "Prefix /foo/bar"},
+		{paths: []string{"Prefix /foo", "Exact /foo/bar"}, request: "/foo/bar", match: "Exact /foo/bar"},
+		{paths: []string{"Prefix /foo", "Exact /foo/bar"}, request: "/foo/bar/", match: "Prefix /foo"},
+	}
+	for _, tc := range testCases {
+		var rules []loadbalancer.RoutingRule
+		for _, path := range tc.paths {
+			fields := strings.Fields(path)
+			pathType := networking.PathType(fields[0])
+			rule, err := createRoutingRule(networking.HTTPIngressPath{Path: fields[1], PathType: &pathType}, path, "foo.bar.com")
+			require.NoError(t, err)
+			rules = append(rules, *rule)
+		}
+		sortRoutingRules(rules)
+		var match string
+		for _, rule := range rules {
+			condition, err := ParseCondition(*rule.Condition)
+			require.NoError(t, err)
+			if matchesRequest(condition, "foo.bar.com", tc.request) {
+				match = *rule.Actions[0].(loadbalancer.ForwardToBackendSet).BackendSetName
+				break
+			}
+		}
+		assert.Equal(t, tc.match, match, "paths: %v, request: %s", tc.paths, tc.request)
+	}
+}
+
+func TestSortRoutingRules(t *testing.T) {
+	pathType := networking.PathTypePrefix
+	newRule := func(path string, extraConditions ...string) loadbalancer.RoutingRule {
+		rule, err := createRoutingRule(networking.HTTPIngressPath{Path: path, PathType: &pathType}, "", "foo.bar.com", extraConditions...)
+		require.NoError(t, err)
+		return *rule
+	}
+	plain, canary, longer := newRule("/api"), newRule("/api", "http.request.headers[(i 'X-Canary')] eq 'true'"), newRule("/api/v1")
+	rules := []loadbalancer.RoutingRule{plain, canary, longer}
+	sortRoutingRules(rules)
+	assert.Equal(t, []loadbalancer.RoutingRule{longer, canary, plain}, rules)
+}
@@ -4,10 +4,11 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	. "github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/configholder"
 	"github.com/pkg/errors"
-	networking "k8s.io/api/networking/v1"
 )
 
 const FlexibleShapeName = "flexible" // 100Mbps in official oci-cloud-provider Loadbalancer service implementation
@@ -16,11 +17,17 @@ var DefaultLBShape = FlexibleShapeName
 var DefaultFlexShapeMinMbps = 10
 var DefaultFlexShapeMaxMbps = 10
 
+// BackendDrainPeriod is how long backends removed from a backend set are drained before they are removed
+var BackendDrainPeriod = 30 * time.Second
+
 const FlexShapeAbsoluteMinMbps = 10
 const FlexShapeAbsoluteMaxMbps = 8192
 
-func getLBShape(ing *networking.Ingress) (string, *int, *int, error) {
+func getLBShape(config configholder.ConfigHolder, ing AnnotatedObject) (string, *int, *int, error) {
 	shape := DefaultLBShape
+	if s := config.GetLoadBalancerShape(); s != "" {
+		shape = s
+	}
 	if s := GetAnnotation(ing, AnnotationLoadBalancerShape); s != "" {
 		shape = s
 	}
@@ -53,6 +60,14 @@ func getLBShape(ing *networking.Ingress) (string, *int, *int, error) {
 	if flexMaxS == "" && flexMinS == "" {
 		flexMinS = strconv.Itoa(DefaultFlexShapeMinMbps)
 		flexMaxS = strconv.Itoa(DefaultFlexShapeMaxMbps)
+		if fmin, fmax := config.GetFlexShapeMbps(); fmin != nil || fmax != nil {
+			if fmin != nil {
+				flexMinS = strconv.Itoa(*fmin)
+			}
+			if fmax != nil {
+				flexMaxS = strconv.Itoa(*fmax)
+			}
+		}
 	}
 	if flexMinS == "" || flexMaxS == "" {
 		return "", nil, nil, fmt.Errorf("error parsing service annotation: %s=flexible requires %s and %s to be set",
@@ -109,3 +124,27 @@ func getLoadBalancerPolicy(obj AnnotatedObject) (string, error) {
 
 	return "", fmt.Errorf("loadbalancer policy \"%s\" is not valid", GetAnnotation(obj, AnnotationLoadBalancerPolicy))
 }
+
+// GetBackendTarget returns what backend sets of the object point at. Either BackendTargetNode or BackendTargetPod.
+func GetBackendTarget(obj AnnotatedObject) (string, error) {
+	switch target := GetAnnotationWithLowercase(obj, AnnotationBackendTarget); target {
+	case "", BackendTargetNode:
+		return BackendTargetNode, nil
+	case BackendTargetPod:
+		return target, nil
+	}
+	return "", fmt.Errorf("backend target \"%s\" is not valid", GetAnnotation(obj, AnnotationBackendTarget))
+}
+
+// GetBackendDrainPeriod returns how long backends removed from backend sets of the object's load balancer are drained
+func GetBackendDrainPeriod(obj AnnotatedObject) (time.Duration, error) {
+	value := GetAnnotation(obj, AnnotationBackendDrainPeriod)
+	if value == "" {
+		return BackendDrainPeriod, nil
+	}
+	period, err := time.ParseDuration(value)
+	if err != nil || period < 0 {
+		return 0, fmt.Errorf("backend drain period \"%s\" is not a non-negative duration", value)
+	}
+	return period, nil
+}
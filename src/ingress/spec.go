@@ -2,20 +2,25 @@ package ingress
 
 import (
 	"context"
+	"reflect"
+	"sync"
 
 	"github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci"
 	. "github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci"
 	ociclient "github.com/nom3ad/oci-lb-ingress-controller/pkg/oci/client"
 	"github.com/nom3ad/oci-lb-ingress-controller/pkg/oci/instance/metadata"
 	"github.com/nom3ad/oci-lb-ingress-controller/src/configholder"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/helpers"
 	"github.com/nom3ad/oci-lb-ingress-controller/src/utils"
 	"github.com/oracle/oci-go-sdk/v46/loadbalancer"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	networking "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/types"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 )
 
 var ForceHTTPSRedirectionByDefault bool
@@ -24,17 +29,29 @@ type IngressLBSpec struct {
 	oci.LBSpec
 
 	Ingress                *networking.Ingress
+	Gateway                *gatewayv1beta1.Gateway // set instead of Ingress for load balancers of gateways
+	GroupLeader            *networking.Ingress     // oldest member of the group sharing the load balancer, if any. Holds state of the group
 	Services               map[string]*corev1.Service
 	RoutingPolicies        map[string]loadbalancer.RoutingPolicy // to no ptrs
 	RuleSets               map[string]loadbalancer.RuleSetDetails
 	HostnameDetails        map[string]loadbalancer.HostnameDetails
 	Certificates           map[string]loadbalancer.CertificateDetails
 	_serviceAndNodeMapping map[string]map[string]corev1.Node
+	_healthCheckOverrides  map[string]HealthCheckConfig // by backend set name
+	_weightedBackendSets   map[string][]weightedBackendSet
 	//unused stuff from lbspec
 	// service *v1.Service
 	// nodes   []*v1.Node
 }
 
+// Object returns the ingress or the gateway the load balancer is derived from. Events are recorded on it.
+func (igs *IngressLBSpec) Object() k8sclient.Object {
+	if igs.Gateway != nil {
+		return igs.Gateway
+	}
+	return igs.Ingress
+}
+
 func (igs *IngressLBSpec) NodesForService(svcName string) []*corev1.Node {
 	var nodeList []*corev1.Node
 	if nodes, found := igs._serviceAndNodeMapping[svcName]; found {
@@ -84,22 +101,45 @@ func NewIngressLBSpec(config configholder.ConfigHolder, ing *networking.Ingress,
 		return nil, err
 	}
 
-	shape, flexShapeMinMbps, flexShapeMaxMbps, err := getLBShape(ing)
+	shape, flexShapeMinMbps, flexShapeMaxMbps, err := getLBShape(config, ing)
+	if err != nil {
+		return nil, err
+	}
+
+	backendTarget, err := GetBackendTarget(ing)
 	if err != nil {
 		return nil, err
 	}
 
+	pathHealthChecks, err := getPathHealthChecks(ing)
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid path health checks")
+	}
+
+	pathBackendWeights, err := getPathBackendWeights(ing)
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid path backend weights")
+	}
+
+	routeConditions, err := getRouteConditions(ing)
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid route conditions")
+	}
+
 	ctx := context.Background()
 
 	serviceAndNodeMapping := map[string]map[string]corev1.Node{}
+	healthCheckOverrides := map[string]HealthCheckConfig{}
+	healthCheckOverridePaths := map[string]string{}
+	weightedBackendSets := map[string][]weightedBackendSet{}
 
 	if err != nil {
 		return nil, err
 	}
 
-	nodeList := &corev1.NodeList{}
-	if err := k8sClient.List(ctx, nodeList); err != nil {
-		return nil, errors.Wrapf(err, "Couldn't list nodes")
+	backendNodes, err := listBackendNodes(ctx, k8sClient, ing)
+	if err != nil {
+		return nil, err
 	}
 
 	hostnameDetailsCollection := map[string]loadbalancer.HostnameDetails{}
@@ -120,45 +160,11 @@ func NewIngressLBSpec(config configholder.ConfigHolder, ing *networking.Ingress,
 	}
 
 	certificateCollection := map[string]loadbalancer.CertificateDetails{}
-	// sslConfigDetailsCollection := map[string]loadbalancer.SslConfigurationDetails{}
 	getOrCreateSSLConfigDetails := func(hostname, secretName string) (*loadbalancer.SslConfigurationDetails, error) {
 		if hostname == "" || secretName == "" {
 			return nil, errors.New("empty hostname or secretName")
 		}
-		crt, err := getCertificateBundle(ctx, ing.Namespace, secretName, k8sClient)
-		if err != nil {
-			return nil, err
-		}
-		certificateName := ing.Namespace + "_" + secretName + "_" + crt.UniqueID()
-		if _, found := certificateCollection[certificateName]; !found {
-			logger.Sugar().With("certificateName", certificateName, "cert", crt.Dump()).Debug("certificate")
-			if crt.CertificateX509.Issuer.String() == crt.CertificateX509.Subject.String() {
-				logger.Sugar().With("secretName", secretName, "issuer", crt.CertificateX509.Issuer.String()).Info("Certificate is self-signed")
-			}
-			if len(crt.CACertificateChainX509) == 0 {
-				logger.Sugar().With("secretName", secretName).Warn("No certificate chain is provided")
-			}
-			certDetails := loadbalancer.CertificateDetails{
-				CertificateName:   utils.PtrToString(certificateName),
-				PublicCertificate: &crt.CertificatePem,
-				PrivateKey:        &crt.PrivateKeyPem,
-				CaCertificate:     crt.CACertificateChainPem,
-				// Passphrase: *string,
-				// TODO: support caCert and password, and validate data
-			}
-			certificateCollection[certificateName] = certDetails
-		}
-		sslDetails := loadbalancer.SslConfigurationDetails{
-			CertificateName: &certificateName,
-			VerifyDepth:     utils.PtrToInt(1), //default value is not 0
-			// VerifyDepth: *int,
-			// VerifyPeerCertificate: *bool,
-			// CipherSuiteName: *string,
-			// ServerOrderPreference: loadbalancer.SslConfigurationDetailsServerOrderPreferenceEnum,
-			// Protocols: string,
-		}
-		// sslConfigDetailsCollection[certificateName] = details
-		return &sslDetails, nil
+		return createSSLConfigDetails(ctx, ing.Namespace, secretName, certificateCollection, k8sClient, logger)
 	}
 
 	namespace := ing.Namespace
@@ -190,7 +196,7 @@ func NewIngressLBSpec(config configholder.ConfigHolder, ing *networking.Ingress,
 			}
 			services[svcName] = svc
 		}
-		nodePort := -1
+		portFound, nodePort := false, -1
 		for _, servicePort := range svc.Spec.Ports {
 			if servicePort.Protocol != corev1.ProtocolTCP {
 				continue
@@ -203,24 +209,68 @@ func NewIngressLBSpec(config configholder.ConfigHolder, ing *networking.Ingress,
 			} else if servicePort.Port != svcPort {
 				continue
 			}
+			portFound = true
 			if servicePort.NodePort > 0 {
 				nodePort = int(servicePort.NodePort)
 			}
 		}
+		if backendTarget == BackendTargetPod {
+			// Pods are reached directly on their target port
+			if !portFound {
+				return "", errors.Errorf("Could not find port for service %q (Ports=%s) for BackendPort: %s %d ",
+					svcNsName, utils.Jsonify(svc.Spec.Ports), svcPortName, svcPort)
+			}
+			backendSetName = getBackendSetName(ing, svcName, int(svcPort))
+			return backendSetName, nil
+		}
 		if nodePort == -1 {
 			return "", errors.Errorf("Could not find NodePort for service %q (Type=%s Ports=%s) for BackendPort: %s %d ",
 				svcNsName, svc.Spec.Type, utils.Jsonify(svc.Spec.Ports), svcPortName, svcPort)
 		}
-		nodes := map[string]corev1.Node{}
-		for _, node := range nodeList.Items {
-			nodes[node.Name] = node
+		if _, found := serviceAndNodeMapping[svcName]; !found {
+			nodes := map[string]corev1.Node{}
+			for _, node := range backendNodes {
+				nodes[node.Name] = node
+			}
+			if helpers.RequestsOnlyLocalTraffic(svc) {
+				// Nodes without a ready endpoint of the service drop the traffic
+				if nodes, err = filterNodesWithReadyEndpoints(ctx, k8sClient, svc, nodes); err != nil {
+					return "", err
+				}
+			}
+			serviceAndNodeMapping[svcName] = nodes
 		}
-		serviceAndNodeMapping[svcName] = nodes
 
-		backendSetName = oci.GetBackendSetName(svcName, string(corev1.ProtocolTCP), int(svcPort))
+		backendSetName = getBackendSetName(ing, svcName, int(svcPort))
 		return backendSetName, nil
 	}
 
+	// processWeightedBackends returns the backend set splitting traffic of the backend between services, by weight
+	processWeightedBackends := func(backend networking.IngressBackend, weights map[string]int32) (string, error) {
+		var backends []weightedBackendSet
+		for _, svcName := range weightedServices(backend.Service.Name, weights) {
+			weighted := backend
+			weighted.Service = &networking.IngressServiceBackend{Name: svcName, Port: backend.Service.Port}
+			name, err := processBackendSpec(weighted)
+			if err != nil {
+				return "", err
+			}
+			backends = append(backends, weightedBackendSet{Name: name, Weight: weights[svcName]})
+		}
+		switch len(backends) {
+		case 0:
+			return "", errors.New("no service has a weight greater than 0")
+		case 1:
+			return backends[0].Name, nil
+		}
+		name := getWeightedBackendSetName(backends)
+		if existing, found := weightedBackendSets[name]; found && !reflect.DeepEqual(existing, backends) {
+			return "", errors.New("another path splits traffic between the same services with other weights")
+		}
+		weightedBackendSets[name] = backends
+		return name, nil
+	}
+
 	for _, ingRule := range ing.Spec.Rules {
 		host := ingRule.Host
 		httpRoutingRules := []loadbalancer.RoutingRule{}
@@ -230,7 +280,25 @@ func NewIngressLBSpec(config configholder.ConfigHolder, ing *networking.Ingress,
 			if err != nil {
 				return nil, err
 			}
-			routingRule, err := createRoutingRule(ingPath, backendSetName, host)
+			if healthCheck, found := pathHealthChecks[ingPath.Path]; found {
+				// Paths routing to the same backend set share its health check
+				if otherPath, taken := healthCheckOverridePaths[backendSetName]; taken && healthCheckOverrides[backendSetName] != healthCheck {
+					return nil, errors.Errorf("Paths %q and %q route to backend set %s but have different health checks", otherPath, ingPath.Path, backendSetName)
+				}
+				healthCheckOverrides[backendSetName] = healthCheck
+				healthCheckOverridePaths[backendSetName] = ingPath.Path
+			}
+			if weights, found := pathBackendWeights[ingPath.Path]; found {
+				backendSetName, err = processWeightedBackends(backend, weights)
+				if err != nil {
+					return nil, errors.Wrapf(err, "Could not split traffic of path %q", ingPath.Path)
+				}
+			}
+			var extraConditions []string
+			if rc, found := routeConditions[ingPath.Path]; found && rc.appliesTo(backend) {
+				extraConditions = rc.compile()
+			}
+			routingRule, err := createRoutingRule(ingPath, backendSetName, host, extraConditions...)
 			if err != nil {
 				return nil, errors.Wrapf(err, "Could not deduce routing rule. host: %s | backendSet: %s | path: %v", host, backendSetName, ingPath)
 			}
@@ -263,6 +331,7 @@ func NewIngressLBSpec(config configholder.ConfigHolder, ing *networking.Ingress,
 			logger.Sugar().With("host", host, "routingPolicyName", routingPolicyName).Debug("Merging routing policies")
 			httpRoutingPolicy.Rules = append(samePolicy.Rules, httpRoutingPolicy.Rules...)
 		}
+		sortRoutingRules(httpRoutingPolicy.Rules)
 		routePolicies[routingPolicyName] = httpRoutingPolicy
 		listener.RoutingPolicyName = &routingPolicyName
 
@@ -301,7 +370,11 @@ func NewIngressLBSpec(config configholder.ConfigHolder, ing *networking.Ingress,
 		// TODO: should we add sans-virtualhost listener for HTTPS?
 	}
 
-	if len(hostsWithTLS) > 0 && (GetAnnotationWithLowercase(ing, AnnotationForceHTTPSRedirect) == "true" || (GetAnnotationWithLowercase(ing, AnnotationForceHTTPSRedirect) == "" && ForceHTTPSRedirectionByDefault)) {
+	forceHTTPSRedirection := ForceHTTPSRedirectionByDefault
+	if force := config.GetForceHTTPSRedirection(); force != nil {
+		forceHTTPSRedirection = *force
+	}
+	if len(hostsWithTLS) > 0 && (GetAnnotationWithLowercase(ing, AnnotationForceHTTPSRedirect) == "true" || (GetAnnotationWithLowercase(ing, AnnotationForceHTTPSRedirect) == "" && forceHTTPSRedirection)) {
 		ruleSetName, httpRedirectorRuleSet, listenerName, httpRedirectorListener := createListenerDetailsAndRulesetDetailsForHTTPSRedirect(utils.StringKeys(hostsWithTLS).List())
 		ruleSets[ruleSetName] = httpRedirectorRuleSet
 		listeners[listenerName] = httpRedirectorListener
@@ -330,7 +403,7 @@ func NewIngressLBSpec(config configholder.ConfigHolder, ing *networking.Ingress,
 		// Ports: ports,
 		// SSLConfig: sslConfig,
 		// SourceCIDRs:             sourceCIDRs,
-		// NetworkSecurityGroupIds: networkSecurityGroupIds,
+		NetworkSecurityGroupIds: config.GetNetworkSecurityGroupIds(),
 		// Nodes:               nodes, // TODO
 		SecurityListManager: oci.NewSecurityListManagerNOOP(), // TODO
 	}
@@ -343,20 +416,78 @@ func NewIngressLBSpec(config configholder.ConfigHolder, ing *networking.Ingress,
 		HostnameDetails:        hostnameDetailsCollection,
 		Certificates:           certificateCollection,
 		_serviceAndNodeMapping: serviceAndNodeMapping,
+		_healthCheckOverrides:  healthCheckOverrides,
+		_weightedBackendSets:   weightedBackendSets,
 	}
-	if err := setupBackendSetsForSpec(spec, ing, logger); err != nil {
+	prioritizePreciseHosts(spec.Listeners, spec.RoutingPolicies, spec.HostnameDetails)
+	if err := setupBackendSetsForSpec(ctx, spec, ing, backendTarget, k8sClient, logger); err != nil {
 		return nil, err
 	}
 
 	return spec, nil
 }
 
-var discoveredSubnet = ""
+// createSSLConfigDetails creates the SSL configuration of a listener serving the certificate of the secret.
+// The certificate is added to certificates, unless it is already there.
+func createSSLConfigDetails(ctx context.Context, namespace, secretName string, certificates map[string]loadbalancer.CertificateDetails, k8sClient k8sclient.Client, logger *zap.Logger) (*loadbalancer.SslConfigurationDetails, error) {
+	crt, err := getCertificateBundle(ctx, namespace, secretName, k8sClient)
+	if err != nil {
+		return nil, err
+	}
+	certificateName := namespace + "_" + secretName + "_" + crt.UniqueID()
+	if _, found := certificates[certificateName]; !found {
+		logger.Sugar().With("certificateName", certificateName, "cert", crt.Dump()).Debug("certificate")
+		if crt.CertificateX509.Issuer.String() == crt.CertificateX509.Subject.String() {
+			logger.Sugar().With("secretName", secretName, "issuer", crt.CertificateX509.Issuer.String()).Info("Certificate is self-signed")
+		}
+		if len(crt.CACertificateChainX509) == 0 {
+			logger.Sugar().With("secretName", secretName).Warn("No certificate chain is provided")
+		}
+		certDetails := loadbalancer.CertificateDetails{
+			CertificateName:   utils.PtrToString(certificateName),
+			PublicCertificate: &crt.CertificatePem,
+			PrivateKey:        &crt.PrivateKeyPem,
+			CaCertificate:     crt.CACertificateChainPem,
+			// Passphrase: *string,
+			// TODO: support caCert and password, and validate data
+		}
+		certificates[certificateName] = certDetails
+	}
+	sslDetails := loadbalancer.SslConfigurationDetails{
+		CertificateName: &certificateName,
+		VerifyDepth:     utils.PtrToInt(1), //default value is not 0
+		// VerifyDepth: *int,
+		// VerifyPeerCertificate: *bool,
+		// CipherSuiteName: *string,
+		// ServerOrderPreference: loadbalancer.SslConfigurationDetailsServerOrderPreferenceEnum,
+		// Protocols: string,
+	}
+	// sslConfigDetailsCollection[certificateName] = details
+	return &sslDetails, nil
+}
+
+// discoveredSubnet caches the subnet found by tryFindLoadbalancerSubnet(). Guarded by discoveredSubnetMu, which is held
+// during discovery, so that concurrent reconciles discover it only once.
+var (
+	discoveredSubnet   = ""
+	discoveredSubnetMu sync.Mutex
+)
 
 func tryFindLoadbalancerSubnet(ociClient ociclient.Interface, logger *zap.Logger) (string, error) {
+	discoveredSubnetMu.Lock()
+	defer discoveredSubnetMu.Unlock()
 	if discoveredSubnet != "" {
 		return discoveredSubnet, nil
 	}
+	subnetId, err := discoverLoadbalancerSubnet(ociClient, logger)
+	if err != nil {
+		return "", err
+	}
+	discoveredSubnet = subnetId
+	return subnetId, nil
+}
+
+func discoverLoadbalancerSubnet(ociClient ociclient.Interface, logger *zap.Logger) (string, error) {
 	meta, err := metadata.New().Get()
 	if err != nil {
 		return "", err
@@ -387,7 +518,7 @@ func tryFindLoadbalancerSubnet(ociClient ociclient.Interface, logger *zap.Logger
 	return instanceSubnetId, nil
 }
 
-func getLoadBalancerSubnetIds(config configholder.ConfigHolder, ing *networking.Ingress, ociClient ociclient.Interface, logger *zap.Logger) (subnetIds []string, err error) {
+func getLoadBalancerSubnetIds(config configholder.ConfigHolder, ing AnnotatedObject, ociClient ociclient.Interface, logger *zap.Logger) (subnetIds []string, err error) {
 	if subnet1 := GetAnnotation(ing, AnnotationLoadBalancerSubnet1); subnet1 != "" {
 		subnetIds = append(subnetIds, subnet1)
 	}
@@ -414,7 +545,7 @@ func getLoadBalancerSubnetIds(config configholder.ConfigHolder, ing *networking.
 	return nil, errors.New("Could not get subnetIds")
 }
 
-func setupBackendSetsForSpec(spec *IngressLBSpec, ing *networking.Ingress, logger *zap.Logger) error {
+func setupBackendSetsForSpec(ctx context.Context, spec *IngressLBSpec, ing *networking.Ingress, backendTarget string, k8sClient k8sclient.Client, logger *zap.Logger) error {
 	backendSetDetails := map[string]loadbalancer.BackendSetDetails{}
 
 	loadbalancerPolicy, err := getLoadBalancerPolicy(ing)
@@ -425,16 +556,93 @@ func setupBackendSetsForSpec(spec *IngressLBSpec, ing *networking.Ingress, logge
 	var sslConfig *oci.SSLConfig = nil // TODO
 
 	for _, svc := range spec.Services {
-		backendSetList, err := oci.GetBackendSets(logger.Sugar(), svc, spec.NodesForService(svc.Name), sslConfig, loadbalancerPolicy)
+		var backendSetList map[string]loadbalancer.BackendSetDetails
+		if backendTarget == BackendTargetPod {
+			endpointSlices, err := listEndpointSlices(ctx, k8sClient, svc)
+			if err != nil {
+				return err
+			}
+			backendSetList, err = oci.GetPodBackendSets(logger.Sugar().With("service", svc.Name), svc, endpointSlices, sslConfig, loadbalancerPolicy)
+		} else {
+			backendSetList, err = oci.GetBackendSets(logger.Sugar(), svc, spec.NodesForService(svc.Name), sslConfig, loadbalancerPolicy)
+		}
 		if err != nil {
 			return err
 		}
+		if GetLoadBalancerGroup(ing) != "" {
+			backendSetList = namespaceBackendSets(svc, backendSetList)
+		}
+		if err := setupHealthChecks(ctx, svc, backendSetList, spec._healthCheckOverrides, spec.Certificates, k8sClient, logger); err != nil {
+			return err
+		}
 		for name, backendset := range backendSetList {
 			backendSetDetails[name] = backendset
 		}
 	}
 
-	backendSetDetails[DummyBackendSetName] = loadbalancer.BackendSetDetails{ // TODO
+	for name, backends := range spec._weightedBackendSets {
+		if backendSetDetails[name], err = createWeightedBackendSetDetails(backendSetDetails, backends); err != nil {
+			return err
+		}
+	}
+
+	backendSetDetails[DummyBackendSetName] = createDummyBackendSetDetails(loadbalancerPolicy)
+	spec.BackendSets = backendSetDetails
+	return nil
+}
+
+// getBackendSetName returns the name of the backend set of the TCP port of the service. Backend sets of grouped ingresses
+// are named after the namespace too, as ingresses of several namespaces share the load balancer.
+func getBackendSetName(ing *networking.Ingress, svcName string, port int) string {
+	if GetLoadBalancerGroup(ing) != "" {
+		return oci.GetNamespacedBackendSetName(ing.Namespace, svcName, string(corev1.ProtocolTCP), port)
+	}
+	return oci.GetBackendSetName(svcName, string(corev1.ProtocolTCP), port)
+}
+
+// namespaceBackendSets renames the backend sets of the service, as named by the cloud provider, after its namespace too
+func namespaceBackendSets(svc *corev1.Service, backendSets map[string]loadbalancer.BackendSetDetails) map[string]loadbalancer.BackendSetDetails {
+	renamed := map[string]loadbalancer.BackendSetDetails{}
+	for _, servicePort := range svc.Spec.Ports {
+		name := oci.GetBackendSetName(svc.Name, string(servicePort.Protocol), int(servicePort.Port))
+		if backendSet, found := backendSets[name]; found {
+			renamed[oci.GetNamespacedBackendSetName(svc.Namespace, svc.Name, string(servicePort.Protocol), int(servicePort.Port))] = backendSet
+		}
+	}
+	return renamed
+}
+
+func listEndpointSlices(ctx context.Context, k8sClient k8sclient.Client, svc *corev1.Service) ([]discoveryv1.EndpointSlice, error) {
+	endpointSlices := &discoveryv1.EndpointSliceList{}
+	if err := k8sClient.List(ctx, endpointSlices, k8sclient.InNamespace(svc.Namespace), k8sclient.MatchingLabels{discoveryv1.LabelServiceName: svc.Name}); err != nil {
+		return nil, errors.Wrapf(err, "Couldn't list endpoint slices of service %s/%s", svc.Namespace, svc.Name)
+	}
+	return endpointSlices.Items, nil
+}
+
+// filterNodesWithReadyEndpoints keeps the nodes running a ready endpoint of the service
+func filterNodesWithReadyEndpoints(ctx context.Context, k8sClient k8sclient.Client, svc *corev1.Service, nodes map[string]corev1.Node) (map[string]corev1.Node, error) {
+	endpointSlices, err := listEndpointSlices(ctx, k8sClient, svc)
+	if err != nil {
+		return nil, err
+	}
+	filtered := map[string]corev1.Node{}
+	for _, slice := range endpointSlices {
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.NodeName == nil || !oci.IsEndpointReady(endpoint) {
+				continue
+			}
+			if node, found := nodes[*endpoint.NodeName]; found {
+				filtered[node.Name] = node
+			}
+		}
+	}
+	return filtered, nil
+}
+
+// createDummyBackendSetDetails creates the backend set without backends, which listeners default to
+func createDummyBackendSetDetails(loadbalancerPolicy string) loadbalancer.BackendSetDetails {
+	return loadbalancer.BackendSetDetails{ // TODO
 		Policy:   utils.PtrToString(loadbalancerPolicy),
 		Backends: []loadbalancer.BackendDetails{},
 		HealthChecker: &loadbalancer.HealthCheckerDetails{
@@ -447,6 +655,4 @@ func setupBackendSetsForSpec(spec *IngressLBSpec, ing *networking.Ingress, logge
 			Retries:          utils.PtrToInt(3),
 		},
 	}
-	spec.BackendSets = backendSetDetails
-	return nil
 }
@@ -0,0 +1,92 @@
+package ingress
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSetupPodBackendSets(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80}}},
+	}
+	portName, port, tcp := "http", int32(8080), corev1.ProtocolTCP
+	yes, no := true, false
+	slice := func(name, service string, endpoints ...discoveryv1.Endpoint) *discoveryv1.EndpointSlice {
+		return &discoveryv1.EndpointSlice{
+			ObjectMeta:  metav1.ObjectMeta{Namespace: "default", Name: name, Labels: map[string]string{discoveryv1.LabelServiceName: service}},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Ports:       []discoveryv1.EndpointPort{{Name: &portName, Protocol: &tcp, Port: &port}},
+			Endpoints:   endpoints,
+		}
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		slice("web-1", "web",
+			discoveryv1.Endpoint{Addresses: []string{"10.0.1.2"}, Conditions: discoveryv1.EndpointConditions{Ready: &yes}},
+			discoveryv1.Endpoint{Addresses: []string{"10.0.1.3"}, Conditions: discoveryv1.EndpointConditions{Ready: &no}},
+			discoveryv1.Endpoint{Addresses: []string{"10.0.1.4"}, Conditions: discoveryv1.EndpointConditions{Ready: &yes, Terminating: &yes}},
+		),
+		slice("web-2", "web",
+			discoveryv1.Endpoint{Addresses: []string{"10.0.1.1"}},
+			discoveryv1.Endpoint{Addresses: []string{"10.0.1.2"}, Conditions: discoveryv1.EndpointConditions{Ready: &yes}},
+		),
+		slice("other-1", "other", discoveryv1.Endpoint{Addresses: []string{"10.0.2.1"}}),
+	).Build()
+
+	spec := &IngressLBSpec{Services: map[string]*corev1.Service{svc.Name: svc}}
+	require.NoError(t, setupBackendSetsForSpec(context.Background(), spec, &networking.Ingress{}, BackendTargetPod, k8sClient, zap.NewNop()))
+
+	backendSet, found := spec.BackendSets[GetBackendSetName("web", "TCP", 80)]
+	require.True(t, found)
+	var backends []string
+	for _, backend := range backendSet.Backends {
+		assert.Equal(t, 8080, *backend.Port)
+		backends = append(backends, *backend.IpAddress)
+	}
+	assert.Equal(t, []string{"10.0.1.1", "10.0.1.2"}, backends)
+	assert.Equal(t, "TCP", *backendSet.HealthChecker.Protocol)
+	assert.Equal(t, 0, *backendSet.HealthChecker.Port)
+}
+
+func TestFilterNodesWithReadyEndpoints(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeNodePort, ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyTypeLocal},
+	}
+	node1, node2, node3 := "node1", "node2", "node3"
+	no := false
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(&discoveryv1.EndpointSlice{
+		ObjectMeta:  metav1.ObjectMeta{Namespace: "default", Name: "web-1", Labels: map[string]string{discoveryv1.LabelServiceName: "web"}},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints: []discoveryv1.Endpoint{
+			{Addresses: []string{"10.0.1.1"}, NodeName: &node1},
+			{Addresses: []string{"10.0.1.2"}, NodeName: &node2, Conditions: discoveryv1.EndpointConditions{Ready: &no}},
+			{Addresses: []string{"10.0.1.3"}, NodeName: &node3},
+		},
+	}).Build()
+	nodes := map[string]corev1.Node{}
+	for _, name := range []string{node1, node2} {
+		nodes[name] = corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+
+	filtered, err := filterNodesWithReadyEndpoints(context.Background(), k8sClient, svc, nodes)
+	require.NoError(t, err)
+	assert.Len(t, filtered, 1)
+	assert.Contains(t, filtered, node1)
+}
@@ -1,16 +1,173 @@
 package ingress
 
-import networking "k8s.io/api/networking/v1"
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	. "github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// OCI limits on names derived from the ingress
+const (
+	maxHostnameLength        = 255
+	maxCertificateNameLength = 255
+	certificateUniqueIDLen   = 24
+)
+
+var (
+	subnetOCIDRegex = regexp.MustCompile(`^ocid1\.subnet\.[a-z0-9-]+\.[a-z0-9-]*\.[a-z0-9]+$`)
+	fixedShapeRegex = regexp.MustCompile(`^[0-9]+Mbps(-Micro)?$`)
+)
 
 func validateIngress(ing *networking.Ingress) error {
-	// TODO:
-	// if err := validateProtocols(svc.Spec.Ports); err != nil {
-	// 	return err
-	// }
+	return ValidateIngress(ing).ToAggregate()
+}
+
+// ValidateIngress reports everything in the ingress that would make the load balancer spec fail to build or be rejected by OCI.
+// Errors carry the path of the offending field.
+func ValidateIngress(ing *networking.Ingress) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, validateAnnotations(ing, field.NewPath("metadata", "annotations"))...)
+	specPath := field.NewPath("spec")
+	if ing.Spec.DefaultBackend != nil {
+		errs = append(errs, validateBackend(*ing.Spec.DefaultBackend, specPath.Child("defaultBackend"))...)
+	}
+	for i, rule := range ing.Spec.Rules {
+		rulePath := specPath.Child("rules").Index(i)
+		errs = append(errs, validateHost(rule.Host, rulePath.Child("host"))...)
+		if rule.HTTP == nil {
+			errs = append(errs, field.Required(rulePath.Child("http"), "only HTTP rules are supported"))
+			continue
+		}
+		for j, path := range rule.HTTP.Paths {
+			pathPath := rulePath.Child("http", "paths").Index(j)
+			if path.PathType != nil && *path.PathType == networking.PathTypeImplementationSpecific {
+				if condition, err := processImplementationSpecificPath(path.Path); err != nil {
+					errs = append(errs, field.Invalid(pathPath.Child("path"), path.Path, "not a valid ImplementationSpecific path: "+err.Error()))
+				} else if _, err := ParseCondition(condition); err != nil {
+					errs = append(errs, field.Invalid(pathPath.Child("path"), path.Path, "not a valid routing policy condition: "+err.Error()))
+				}
+			}
+			errs = append(errs, validateBackend(path.Backend, pathPath.Child("backend"))...)
+		}
+	}
+	for i, tls := range ing.Spec.TLS {
+		tlsPath := specPath.Child("tls").Index(i)
+		for j, host := range tls.Hosts {
+			errs = append(errs, validateHost(host, tlsPath.Child("hosts").Index(j))...)
+		}
+		// certificate name is "<namespace>_<secretName>_<digest>"
+		if l := len(ing.Namespace) + len(tls.SecretName) + certificateUniqueIDLen + 2; l > maxCertificateNameLength {
+			errs = append(errs, field.TooLong(tlsPath.Child("secretName"), tls.SecretName, maxCertificateNameLength-certificateUniqueIDLen-2-len(ing.Namespace)))
+		}
+	}
+	return errs
+}
 
-	// if svc.Spec.SessionAffinity != corev1.ServiceAffinityNone {
-	// 	return errors.New("OCI only supports SessionAffinity \"None\" currently")
-	// }
+func validateAnnotations(ing *networking.Ingress, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	annotationPath := func(name string) *field.Path {
+		return path.Key(IngressAnnotationPrefix + name)
+	}
+	if shape := GetAnnotation(ing, AnnotationLoadBalancerShape); shape != "" && strings.ToLower(shape) != FlexibleShapeName && !fixedShapeRegex.MatchString(shape) {
+		errs = append(errs, field.Invalid(annotationPath(AnnotationLoadBalancerShape), shape, `must be "flexible" or a fixed shape like "100Mbps"`))
+	}
+	flexMin, flexMinErrs := validateFlexMbps(ing, AnnotationLoadBalancerShapeFlexMin, annotationPath(AnnotationLoadBalancerShapeFlexMin))
+	flexMax, flexMaxErrs := validateFlexMbps(ing, AnnotationLoadBalancerShapeFlexMax, annotationPath(AnnotationLoadBalancerShapeFlexMax))
+	errs = append(errs, flexMinErrs...)
+	errs = append(errs, flexMaxErrs...)
+	if (flexMin == nil) != (flexMax == nil) && len(flexMinErrs)+len(flexMaxErrs) == 0 {
+		missing := AnnotationLoadBalancerShapeFlexMax
+		if flexMin == nil {
+			missing = AnnotationLoadBalancerShapeFlexMin
+		}
+		errs = append(errs, field.Required(annotationPath(missing), fmt.Sprintf("%s and %s must be set together", AnnotationLoadBalancerShapeFlexMin, AnnotationLoadBalancerShapeFlexMax)))
+	}
+	if flexMin != nil && flexMax != nil && *flexMin > *flexMax {
+		errs = append(errs, field.Invalid(annotationPath(AnnotationLoadBalancerShapeFlexMin), *flexMin, fmt.Sprintf("must not be greater than %s (%d)", AnnotationLoadBalancerShapeFlexMax, *flexMax)))
+	}
+	if _, err := getLoadBalancerPolicy(ing); err != nil {
+		errs = append(errs, field.NotSupported(annotationPath(AnnotationLoadBalancerPolicy), GetAnnotation(ing, AnnotationLoadBalancerPolicy),
+			[]string{IPHashLoadBalancerPolicy, LeastConnectionsLoadBalancerPolicy, RoundRobinLoadBalancerPolicy}))
+	}
+	if _, err := GetBackendTarget(ing); err != nil {
+		errs = append(errs, field.NotSupported(annotationPath(AnnotationBackendTarget), GetAnnotation(ing, AnnotationBackendTarget),
+			[]string{BackendTargetNode, BackendTargetPod}))
+	}
+	if _, err := getBackendNodeSelector(ing); err != nil {
+		errs = append(errs, field.Invalid(annotationPath(AnnotationBackendNodeSelector), GetAnnotation(ing, AnnotationBackendNodeSelector), "must be a label selector"))
+	}
+	if _, err := GetBackendDrainPeriod(ing); err != nil {
+		errs = append(errs, field.Invalid(annotationPath(AnnotationBackendDrainPeriod), GetAnnotation(ing, AnnotationBackendDrainPeriod), "must be a non-negative duration like \"30s\""))
+	}
+	errs = append(errs, validatePathHealthChecks(ing, annotationPath(AnnotationPathHealthChecks))...)
+	errs = append(errs, validatePathBackendWeights(ing, annotationPath(AnnotationPathBackendWeights))...)
+	errs = append(errs, validateRouteConditions(ing, annotationPath(AnnotationRouteConditions))...)
+	for _, name := range []string{AnnotationLoadBalancerSubnet1, AnnotationLoadBalancerSubnet2} {
+		if subnet := GetAnnotation(ing, name); subnet != "" && !subnetOCIDRegex.MatchString(subnet) {
+			errs = append(errs, field.Invalid(annotationPath(name), subnet, "must be a subnet OCID"))
+		}
+	}
+	if _, err := IsInternalLB(ing); err != nil {
+		errs = append(errs, field.Invalid(annotationPath(AnnotationLoadBalancerInternal), GetAnnotation(ing, AnnotationLoadBalancerInternal), "must be a boolean"))
+	}
+	if _, err := GetLoadBalancerIP(ing); err != nil {
+		errs = append(errs, field.Invalid(annotationPath(AnnotationLoadBalancerReservedIP), GetAnnotation(ing, AnnotationLoadBalancerReservedIP), err.Error()))
+	}
+	if group := GetLoadBalancerGroup(ing); group != "" {
+		for _, msg := range validation.IsDNS1123Label(group) {
+			errs = append(errs, field.Invalid(annotationPath(AnnotationLoadBalancerGroup), group, msg))
+		}
+	}
+	return errs
+}
+
+func validateFlexMbps(ing *networking.Ingress, name string, path *field.Path) (*int, field.ErrorList) {
+	value := GetAnnotation(ing, name)
+	if value == "" {
+		return nil, nil
+	}
+	mbps, err := strconv.Atoi(value)
+	if err != nil || mbps < 0 {
+		return nil, field.ErrorList{field.Invalid(path, value, "must be a non-negative integer")}
+	}
+	return &mbps, nil
+}
+
+func validateHost(host string, path *field.Path) field.ErrorList {
+	if host == "" {
+		return nil
+	}
+	if len(host) > maxHostnameLength {
+		return field.ErrorList{field.TooLong(path, host, maxHostnameLength)}
+	}
+	var msgs []string
+	if strings.HasPrefix(host, "*.") {
+		msgs = validation.IsWildcardDNS1123Subdomain(host)
+	} else {
+		msgs = validation.IsDNS1123Subdomain(host)
+	}
+	var errs field.ErrorList
+	for _, msg := range msgs {
+		errs = append(errs, field.Invalid(path, host, msg))
+	}
+	return errs
+}
 
+func validateBackend(backend networking.IngressBackend, path *field.Path) field.ErrorList {
+	if backend.Resource != nil {
+		return field.ErrorList{field.Forbidden(path.Child("resource"), "resource backends are not supported")}
+	}
+	if backend.Service == nil {
+		return field.ErrorList{field.Required(path.Child("service"), "")}
+	}
+	if backend.Service.Port.Number == 0 && backend.Service.Port.Name == "" {
+		return field.ErrorList{field.Required(path.Child("service", "port"), "port number or name is required")}
+	}
 	return nil
 }
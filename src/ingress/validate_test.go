@@ -0,0 +1,139 @@
+package ingress
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newValidationTestIngress(annotations map[string]string, pathType networking.PathType, path string) *networking.Ingress {
+	withPrefix := map[string]string{}
+	for k, v := range annotations {
+		withPrefix[IngressAnnotationPrefix+k] = v
+	}
+	return &networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test", Annotations: withPrefix},
+		Spec: networking.IngressSpec{
+			Rules: []networking.IngressRule{{
+				Host: "www.example.com",
+				IngressRuleValue: networking.IngressRuleValue{HTTP: &networking.HTTPIngressRuleValue{
+					Paths: []networking.HTTPIngressPath{{
+						Path:     path,
+						PathType: &pathType,
+						Backend:  networking.IngressBackend{Service: &networking.IngressServiceBackend{Name: "web", Port: networking.ServiceBackendPort{Number: 80}}},
+					}},
+				}},
+			}},
+		},
+	}
+}
+
+func TestValidateIngress(t *testing.T) {
+	testCases := []struct {
+		name        string
+		ingress     *networking.Ingress
+		errorFields []string
+	}{
+		{
+			name:    "valid",
+			ingress: newValidationTestIngress(map[string]string{AnnotationLoadBalancerShape: "flexible", AnnotationLoadBalancerShapeFlexMin: "10", AnnotationLoadBalancerShapeFlexMax: "100"}, networking.PathTypePrefix, "/"),
+		},
+		{
+			name:    "valid ImplementationSpecific path",
+			ingress: newValidationTestIngress(nil, networking.PathTypeImplementationSpecific, "/api/*"),
+		},
+		{
+			name:        "invalid shape",
+			ingress:     newValidationTestIngress(map[string]string{AnnotationLoadBalancerShape: "huge"}, networking.PathTypePrefix, "/"),
+			errorFields: []string{"metadata.annotations[ingress.beta.kubernetes.io/oci-load-balancer-shape]"},
+		},
+		{
+			name:        "flex min without max",
+			ingress:     newValidationTestIngress(map[string]string{AnnotationLoadBalancerShapeFlexMin: "10"}, networking.PathTypePrefix, "/"),
+			errorFields: []string{"metadata.annotations[ingress.beta.kubernetes.io/oci-load-balancer-shape-flex-max]"},
+		},
+		{
+			name:        "flex min greater than max",
+			ingress:     newValidationTestIngress(map[string]string{AnnotationLoadBalancerShapeFlexMin: "100", AnnotationLoadBalancerShapeFlexMax: "10"}, networking.PathTypePrefix, "/"),
+			errorFields: []string{"metadata.annotations[ingress.beta.kubernetes.io/oci-load-balancer-shape-flex-min]"},
+		},
+		{
+			name:        "non integer flex max",
+			ingress:     newValidationTestIngress(map[string]string{AnnotationLoadBalancerShapeFlexMin: "10", AnnotationLoadBalancerShapeFlexMax: "10M"}, networking.PathTypePrefix, "/"),
+			errorFields: []string{"metadata.annotations[ingress.beta.kubernetes.io/oci-load-balancer-shape-flex-max]"},
+		},
+		{
+			name:        "unknown policy",
+			ingress:     newValidationTestIngress(map[string]string{AnnotationLoadBalancerPolicy: "RANDOM"}, networking.PathTypePrefix, "/"),
+			errorFields: []string{"metadata.annotations[ingress.beta.kubernetes.io/oci-load-balancer-policy]"},
+		},
+		{
+			name:        "unknown backend target",
+			ingress:     newValidationTestIngress(map[string]string{AnnotationBackendTarget: "vm"}, networking.PathTypePrefix, "/"),
+			errorFields: []string{"metadata.annotations[ingress.beta.kubernetes.io/oci-backend-target]"},
+		},
+		{
+			name: "invalid path health checks",
+			ingress: newValidationTestIngress(map[string]string{AnnotationPathHealthChecks: `{"/": {"protocol": "tcp", "urlPath": "/healthz"}, "/api": {"port": 8080}}`},
+				networking.PathTypePrefix, "/"),
+			errorFields: []string{
+				"metadata.annotations[ingress.beta.kubernetes.io/oci-path-health-checks][/].urlPath",
+				"metadata.annotations[ingress.beta.kubernetes.io/oci-path-health-checks][/api]",
+			},
+		},
+		{
+			name:    "path backend weights",
+			ingress: newValidationTestIngress(map[string]string{AnnotationPathBackendWeights: `{"/": {"web": 90, "web-canary": 10}}`}, networking.PathTypePrefix, "/"),
+		},
+		{
+			name:        "path backend weights without the service of the path",
+			ingress:     newValidationTestIngress(map[string]string{AnnotationPathBackendWeights: `{"/": {"web-canary": 10}}`}, networking.PathTypePrefix, "/"),
+			errorFields: []string{"metadata.annotations[ingress.beta.kubernetes.io/oci-path-backend-weights][/][web]"},
+		},
+		{
+			name:        "invalid subnet",
+			ingress:     newValidationTestIngress(map[string]string{AnnotationLoadBalancerSubnet1: "ocid1.vcn.oc1.phx.abc"}, networking.PathTypePrefix, "/"),
+			errorFields: []string{"metadata.annotations[ingress.beta.kubernetes.io/oci-load-balancer-subnet1]"},
+		},
+		{
+			name:        "invalid load balancer group",
+			ingress:     newValidationTestIngress(map[string]string{AnnotationLoadBalancerGroup: "Shared_Public"}, networking.PathTypePrefix, "/"),
+			errorFields: []string{"metadata.annotations[ingress.beta.kubernetes.io/oci-load-balancer-group]"},
+		},
+		{
+			name:        "unparsable ImplementationSpecific path",
+			ingress:     newValidationTestIngress(nil, networking.PathTypeImplementationSpecific, "/a*b*c"),
+			errorFields: []string{"spec.rules[0].http.paths[0].path"},
+		},
+		{
+			name:        "unparsable routing policy condition",
+			ingress:     newValidationTestIngress(nil, networking.PathTypeImplementationSpecific, "condition:any(http.request.url.path sw '/a'"),
+			errorFields: []string{"spec.rules[0].http.paths[0].path"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var fields []string
+			for _, err := range ValidateIngress(tc.ingress) {
+				fields = append(fields, err.Field)
+			}
+			assert.Equal(t, tc.errorFields, fields)
+		})
+	}
+}
+
+func TestValidateIngressBackendsAndNames(t *testing.T) {
+	ing := newValidationTestIngress(nil, networking.PathTypePrefix, "/")
+	ing.Spec.Rules[0].HTTP.Paths[0].Backend = networking.IngressBackend{Resource: &corev1.TypedLocalObjectReference{Kind: "Bucket", Name: "static"}}
+	ing.Spec.TLS = []networking.IngressTLS{{Hosts: []string{"www.example.com"}, SecretName: strings.Repeat("s", 250)}}
+	var fields []string
+	for _, err := range ValidateIngress(ing) {
+		fields = append(fields, err.Field)
+	}
+	assert.Equal(t, []string{"spec.rules[0].http.paths[0].backend.resource", "spec.tls[0].secretName"}, fields)
+}
@@ -0,0 +1,144 @@
+package ingress
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+
+	. "github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/utils"
+	"github.com/oracle/oci-go-sdk/v46/loadbalancer"
+	"github.com/pkg/errors"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// maxServiceWeight is the largest weight of a service in AnnotationPathBackendWeights, as for backendRefs of HTTPRoutes
+const maxServiceWeight = 1000000
+
+// getPathBackendWeights reads the weights of services by ingress path
+func getPathBackendWeights(ing *networking.Ingress) (map[string]map[string]int32, error) {
+	weights := map[string]map[string]int32{}
+	value := GetAnnotation(ing, AnnotationPathBackendWeights)
+	if value == "" {
+		return weights, nil
+	}
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&weights); err != nil {
+		return nil, err
+	}
+	return weights, nil
+}
+
+func validatePathBackendWeights(ing *networking.Ingress, path *field.Path) field.ErrorList {
+	weights, err := getPathBackendWeights(ing)
+	if err != nil {
+		return field.ErrorList{field.Invalid(path, GetAnnotation(ing, AnnotationPathBackendWeights), "must be a JSON object of weights by service name by path: "+err.Error())}
+	}
+	pathServices := map[string][]string{}
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, ingPath := range rule.HTTP.Paths {
+			if ingPath.Backend.Service != nil {
+				pathServices[ingPath.Path] = append(pathServices[ingPath.Path], ingPath.Backend.Service.Name)
+			}
+		}
+	}
+	var errs field.ErrorList
+	for _, ingPath := range utils.StringKeys(weights).List() {
+		pathPath := path.Key(ingPath)
+		services, found := pathServices[ingPath]
+		if !found {
+			errs = append(errs, field.NotFound(pathPath, ingPath))
+			continue
+		}
+		for _, svcName := range services {
+			if _, found := weights[ingPath][svcName]; !found {
+				errs = append(errs, field.Required(pathPath.Key(svcName), "the service of the path must have a weight"))
+			}
+		}
+		var total int64
+		for _, svcName := range utils.StringKeys(weights[ingPath]).List() {
+			weight := weights[ingPath][svcName]
+			if weight < 0 || weight > maxServiceWeight {
+				errs = append(errs, field.Invalid(pathPath.Key(svcName), weight, "must be between 0 and 1000000"))
+			}
+			total += int64(weight)
+		}
+		if total == 0 {
+			errs = append(errs, field.Invalid(pathPath, weights[ingPath], "a weight must be greater than 0"))
+		}
+	}
+	return errs
+}
+
+// weightedServices returns the services of the path weighted above 0. The service of the path comes first.
+func weightedServices(svcName string, weights map[string]int32) []string {
+	var services []string
+	for name, weight := range weights {
+		if name != svcName && weight > 0 {
+			services = append(services, name)
+		}
+	}
+	sort.Strings(services)
+	if weights[svcName] > 0 {
+		services = append([]string{svcName}, services...)
+	}
+	return services
+}
+
+// getWeightedBackendSetName returns the name of the backend set combining the backend sets. Weights are left out, so
+// that changing them updates the weights of backends rather than replacing the backend set.
+func getWeightedBackendSetName(backends []weightedBackendSet) string {
+	var names []string
+	for _, backend := range backends {
+		names = append(names, backend.Name)
+	}
+	sort.Strings(names)
+	return "weighted_" + utils.ObjectHash(names, 23) // max 32
+}
+
+// createWeightedBackendSetDetails puts backends of all the backend sets in a backend set of their own. Each backend set
+// gets its share of the traffic, as per its weight, split evenly between its backends. Backend weights only go up to
+// maxBackendWeight, so shares spread over many backends are rounded, to a weight of 1 at least.
+// A backend set holds a single health check. So the backend sets must be checked the same way, eg: backend sets of
+// services with externalTrafficPolicy=Local can't be combined, as their HealthCheckNodePorts differ.
+func createWeightedBackendSetDetails(backendSets map[string]loadbalancer.BackendSetDetails, backends []weightedBackendSet) (loadbalancer.BackendSetDetails, error) {
+	first := backendSets[backends[0].Name]
+	var maxShare float64
+	for _, backendSet := range backends {
+		details := backendSets[backendSet.Name]
+		if !utils.NullOrDeepEqual(details.HealthChecker, first.HealthChecker) {
+			return loadbalancer.BackendSetDetails{}, errors.Errorf("backend sets %s and %s are health checked differently. They can't be weighted in a single backend set", backends[0].Name, backendSet.Name)
+		}
+		if len(details.Backends) > 0 {
+			maxShare = math.Max(maxShare, float64(backendSet.Weight)/float64(len(details.Backends)))
+		}
+	}
+	weighted := loadbalancer.BackendSetDetails{
+		Policy:           first.Policy,
+		HealthChecker:    first.HealthChecker,
+		SslConfiguration: first.SslConfiguration,
+		Backends:         []loadbalancer.BackendDetails{},
+	}
+	for _, backendSet := range backends {
+		details := backendSets[backendSet.Name]
+		if len(details.Backends) == 0 {
+			continue
+		}
+		share := float64(backendSet.Weight) / float64(len(details.Backends))
+		weight := int(math.Round(share / maxShare * maxBackendWeight))
+		if weight < 1 {
+			weight = 1
+		}
+		for _, backend := range details.Backends {
+			backend.Weight = utils.PtrToInt(weight)
+			weighted.Backends = append(weighted.Backends, backend)
+		}
+	}
+	return weighted, nil
+}
@@ -0,0 +1,57 @@
+package ingress
+
+import (
+	"testing"
+
+	"github.com/nom3ad/oci-lb-ingress-controller/src/utils"
+	"github.com/oracle/oci-go-sdk/v46/loadbalancer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateWeightedBackendSetDetails(t *testing.T) {
+	backendSet := func(healthCheckPort int, ips ...string) loadbalancer.BackendSetDetails {
+		details := loadbalancer.BackendSetDetails{Policy: utils.PtrToString("ROUND_ROBIN"), HealthChecker: &loadbalancer.HealthCheckerDetails{Port: utils.PtrToInt(healthCheckPort)}}
+		for _, ip := range ips {
+			details.Backends = append(details.Backends, loadbalancer.BackendDetails{IpAddress: utils.PtrToString(ip), Port: utils.PtrToInt(8080), Weight: utils.PtrToInt(1)})
+		}
+		return details
+	}
+	weights := func(details loadbalancer.BackendSetDetails) []int {
+		var weights []int
+		for _, backend := range details.Backends {
+			weights = append(weights, *backend.Weight)
+		}
+		return weights
+	}
+	backendSets := map[string]loadbalancer.BackendSetDetails{
+		"web":    backendSet(10256, "10.0.0.1", "10.0.0.2"),
+		"canary": backendSet(10256, "10.0.0.1"),
+		"pods":   backendSet(10256, "10.1.0.1", "10.1.0.2", "10.1.0.3", "10.1.0.4", "10.1.0.5", "10.1.0.6", "10.1.0.7", "10.1.0.8", "10.1.0.9", "10.1.0.10"),
+		"empty":  backendSet(10256),
+		"local":  backendSet(31500, "10.0.0.1"),
+	}
+
+	weighted, err := createWeightedBackendSetDetails(backendSets, []weightedBackendSet{{Name: "web", Weight: 90}, {Name: "canary", Weight: 10}})
+	require.NoError(t, err)
+	assert.Equal(t, 10256, *weighted.HealthChecker.Port)
+	assert.Equal(t, []int{100, 100, 22}, weights(weighted)) // 45 per web backend, 10 per canary one
+	assert.Equal(t, 1, *backendSets["web"].Backends[0].Weight)
+
+	// 90/10 between 10 stable pods and a canary pod: 9 per stable pod, 10 for the canary one
+	weighted, err = createWeightedBackendSetDetails(backendSets, []weightedBackendSet{{Name: "pods", Weight: 90}, {Name: "canary", Weight: 10}})
+	require.NoError(t, err)
+	assert.Equal(t, []int{90, 90, 90, 90, 90, 90, 90, 90, 90, 90, 100}, weights(weighted))
+
+	weighted, err = createWeightedBackendSetDetails(backendSets, []weightedBackendSet{{Name: "web", Weight: 50}, {Name: "empty", Weight: 50}})
+	require.NoError(t, err)
+	assert.Equal(t, []int{100, 100}, weights(weighted))
+
+	_, err = createWeightedBackendSetDetails(backendSets, []weightedBackendSet{{Name: "web", Weight: 90}, {Name: "local", Weight: 10}})
+	assert.Error(t, err, "health checked on another port")
+
+	assert.Equal(t,
+		getWeightedBackendSetName([]weightedBackendSet{{Name: "web", Weight: 90}, {Name: "canary", Weight: 10}}),
+		getWeightedBackendSetName([]weightedBackendSet{{Name: "web", Weight: 50}, {Name: "canary", Weight: 50}}))
+	assert.Equal(t, []string{"web", "canary", "other"}, weightedServices("web", map[string]int32{"other": 1, "canary": 10, "web": 90, "off": 0}))
+}
@@ -0,0 +1,116 @@
+package ingress
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nom3ad/oci-lb-ingress-controller/src/utils"
+	"github.com/oracle/oci-go-sdk/v46/loadbalancer"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// coversHostname tells whether the wildcard host of an ingress rule matches the host, which is the case if the host has
+// a single label in place of the wildcard. eg: *.foo.com covers bar.foo.com, but not foo.com nor baz.bar.foo.com
+func coversHostname(wildcard, hostname string) bool {
+	if !strings.HasPrefix(wildcard, "*.") || strings.HasPrefix(hostname, "*.") || !strings.HasSuffix(hostname, wildcard[1:]) {
+		return false
+	}
+	label := strings.TrimSuffix(hostname, wildcard[1:])
+	return label != "" && !strings.Contains(label, ".")
+}
+
+// WildcardHostWarnings warns, for each wildcard host of the ingress rules, that hosts of more than one label in place of the
+// wildcard are routed by its rules too. OCI wildcard hostnames match them, and routing policies can't match Host suffixes.
+func WildcardHostWarnings(ing *networking.Ingress) []string {
+	var warnings []string
+	seen := sets.NewString()
+	for _, rule := range ing.Spec.Rules {
+		if !strings.HasPrefix(rule.Host, "*.") || seen.Has(rule.Host) {
+			continue
+		}
+		seen.Insert(rule.Host)
+		warnings = append(warnings, fmt.Sprintf("Wildcard host %q also routes hosts of more than one label in place of *, eg: a.b%s. OCI can't restrict it to a single label", rule.Host, rule.Host[1:]))
+	}
+	return warnings
+}
+
+// prioritizePreciseHosts routes requests for precise hosts to their own rules on ports where only a wildcard host covering
+// them has a listener. OCI hands such requests to the listener of the wildcard, whose rules don't match hosts since OCI
+// can't match suffixes of headers. Rules of the precise host are put first in the routing policy of the wildcard, followed
+// by one forwarding its other requests where its own listener would: to the default backend if any, else nowhere.
+func prioritizePreciseHosts(listeners map[string]loadbalancer.ListenerDetails, policies map[string]loadbalancer.RoutingPolicy, hostnameDetails map[string]loadbalancer.HostnameDetails) {
+	listenerHostname := func(listener loadbalancer.ListenerDetails) string {
+		if len(listener.HostnameNames) != 1 {
+			return ""
+		}
+		if details, found := hostnameDetails[listener.HostnameNames[0]]; found {
+			return *details.Hostname
+		}
+		return ""
+	}
+	hostPorts := map[string]sets.Int{}
+	hostPolicies := map[string]string{}
+	for _, listener := range listeners {
+		for _, name := range listener.HostnameNames {
+			details, found := hostnameDetails[name]
+			if !found {
+				continue
+			}
+			if hostPorts[*details.Hostname] == nil {
+				hostPorts[*details.Hostname] = sets.NewInt()
+			}
+			hostPorts[*details.Hostname].Insert(*listener.Port)
+		}
+		if hostname := listenerHostname(listener); hostname != "" && listener.RoutingPolicyName != nil {
+			hostPolicies[hostname] = *listener.RoutingPolicyName
+		}
+	}
+	for _, listenerName := range utils.StringKeys(listeners).List() {
+		listener := listeners[listenerName]
+		wildcard := listenerHostname(listener)
+		if !strings.HasPrefix(wildcard, "*.") || listener.RoutingPolicyName == nil {
+			continue
+		}
+		policy, found := policies[*listener.RoutingPolicyName]
+		if !found {
+			continue
+		}
+		otherwise := DummyBackendSetName
+		for _, rule := range policy.Rules {
+			if *rule.Name == defaultBackendRoutingRuleName {
+				otherwise = *rule.Actions[0].(loadbalancer.ForwardToBackendSet).BackendSetName
+			}
+		}
+		added := false
+		for _, hostname := range utils.StringKeys(hostPolicies).List() {
+			if !coversHostname(wildcard, hostname) || hostPorts[hostname].Has(*listener.Port) {
+				continue
+			}
+			var rules []loadbalancer.RoutingRule
+			for _, rule := range policies[hostPolicies[hostname]].Rules {
+				if *rule.Name != defaultBackendRoutingRuleName {
+					rules = append(rules, rule)
+				}
+			}
+			otherwiseCondition := createHostnameCondition(hostname)
+			rules = append(rules, loadbalancer.RoutingRule{
+				Name:      utils.PtrToString("otherwise"),
+				Condition: &otherwiseCondition,
+				Actions:   []loadbalancer.Action{loadbalancer.ForwardToBackendSet{BackendSetName: utils.PtrToString(otherwise)}},
+			})
+			for _, rule := range rules {
+				// Rule names are unique within a policy only
+				rule.Name = utils.PtrToString(utils.ObjectHash(struct{ Host, Rule string }{hostname, *rule.Name}, 22))
+				if !utils.ContainsMatching(policy.Rules, func(r loadbalancer.RoutingRule) bool { return *r.Name == *rule.Name }) {
+					policy.Rules = append(policy.Rules, rule)
+					added = true
+				}
+			}
+		}
+		if added {
+			sortRoutingRules(policy.Rules)
+			policies[*listener.RoutingPolicyName] = policy
+		}
+	}
+}
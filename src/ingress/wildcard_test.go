@@ -0,0 +1,89 @@
+package ingress
+
+import (
+	"testing"
+
+	"github.com/nom3ad/oci-lb-ingress-controller/src/utils"
+	"github.com/oracle/oci-go-sdk/v46/loadbalancer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	networking "k8s.io/api/networking/v1"
+)
+
+func TestCoversHostname(t *testing.T) {
+	expectations := map[string]bool{
+		"bar.foo.com":     true,
+		"BAR-1.foo.com":   true,
+		"foo.com":         false,
+		".foo.com":        false,
+		"baz.bar.foo.com": false,
+		"barfoo.com":      false,
+		"*.foo.com":       false,
+	}
+	for hostname, covered := range expectations {
+		assert.Equal(t, covered, coversHostname("*.foo.com", hostname), "for host: %s", hostname)
+	}
+	assert.False(t, coversHostname("bar.foo.com", "bar.foo.com"))
+}
+
+func TestWildcardHostWarnings(t *testing.T) {
+	ing := &networking.Ingress{Spec: networking.IngressSpec{Rules: []networking.IngressRule{
+		{Host: "*.example.com"}, {Host: "www.example.com"}, {Host: "*.example.com"}, {},
+	}}}
+	warnings := WildcardHostWarnings(ing)
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], `"*.example.com"`)
+	assert.Contains(t, warnings[0], "a.b.example.com")
+	assert.Empty(t, WildcardHostWarnings(&networking.Ingress{}))
+}
+
+func TestPrioritizePreciseHosts(t *testing.T) {
+	listeners := map[string]loadbalancer.ListenerDetails{}
+	policies := map[string]loadbalancer.RoutingPolicy{}
+	hostnameDetails := map[string]loadbalancer.HostnameDetails{}
+	defaultRule, _ := createDefaultBackendRoutingRule("default")
+	addHost := func(host, path string, tls bool) {
+		pathType := networking.PathTypePrefix
+		rule, err := createRoutingRule(networking.HTTPIngressPath{Path: path, PathType: &pathType}, host, host)
+		require.NoError(t, err)
+		details := loadbalancer.HostnameDetails{Name: utils.PtrToString(getHostnameName(host)), Hostname: &host}
+		hostnameDetails[*details.Name] = details
+		var ssl *loadbalancer.SslConfigurationDetails
+		if tls {
+			ssl = &loadbalancer.SslConfigurationDetails{}
+		}
+		name, listener := createListenerDetails(nil, &details, ssl)
+		listener.RoutingPolicyName = utils.PtrToString(getRoutingPolicyName(host))
+		listeners[name] = listener
+		policies[*listener.RoutingPolicyName] = loadbalancer.RoutingPolicy{Name: listener.RoutingPolicyName, Rules: []loadbalancer.RoutingRule{*rule, *defaultRule}}
+	}
+	addHost("*.example.com", "/", true)
+	addHost("www.example.com", "/api", false)    // only on port 80, where the wildcard has no listener
+	addHost("shop.example.com", "/shop", true)   // has its own listener on port 443
+	addHost("a.b.example.com", "/deeper", false) // not covered by the wildcard
+
+	prioritizePreciseHosts(listeners, policies, hostnameDetails)
+	wildcardPolicy := policies[getRoutingPolicyName("*.example.com")]
+	require.Len(t, wildcardPolicy.Rules, 4)
+
+	route := func(host, path string) string {
+		for _, rule := range wildcardPolicy.Rules {
+			condition, err := ParseCondition(*rule.Condition)
+			require.NoError(t, err)
+			if matchesRequest(condition, host, path) {
+				return *rule.Actions[0].(loadbalancer.ForwardToBackendSet).BackendSetName
+			}
+		}
+		return ""
+	}
+	assert.Equal(t, "www.example.com", route("www.example.com", "/api/v1"))
+	assert.Equal(t, "default", route("www.example.com", "/other"))
+	assert.Equal(t, "*.example.com", route("foo.example.com", "/other"))
+	assert.Equal(t, "*.example.com", route("shop.example.com", "/shop"))
+	assert.Equal(t, defaultBackendRoutingRuleName, *wildcardPolicy.Rules[3].Name)
+
+	// Already prioritized hosts are not added again
+	prioritizePreciseHosts(listeners, policies, hostnameDetails)
+	assert.Equal(t, wildcardPolicy, policies[getRoutingPolicyName("*.example.com")])
+	assert.Len(t, policies[getRoutingPolicyName("www.example.com")].Rules, 2)
+}
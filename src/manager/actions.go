@@ -2,6 +2,7 @@ package manager
 
 import (
 	"context"
+	"fmt"
 
 	"go.uber.org/zap"
 )
@@ -18,10 +19,11 @@ type action struct {
 	done    bool
 	verb    ActionVerb
 	subject interface{}
+	name    string
 	fn      func() error
 }
 
-func (a action) do() error {
+func (a *action) do() error {
 	defer func() {
 		a.done = true
 	}()
@@ -64,7 +66,8 @@ func (ad *ActionDispatcher) Run(verb ActionVerb, subjects ...interface{}) error
 		subjects = ad.allSubjects()
 	}
 	for _, sub := range subjects {
-		for _, action := range ad._actions {
+		for i := range ad._actions {
+			action := &ad._actions[i]
 			if action.done || action.verb != verb || action.subject != sub {
 				continue
 			}
@@ -77,10 +80,28 @@ func (ad *ActionDispatcher) Run(verb ActionVerb, subjects ...interface{}) error
 	return nil
 }
 
-func (ad *ActionDispatcher) AddFunc(verb ActionVerb, subject interface{}, fn func() error) {
+func (ad *ActionDispatcher) AddFunc(verb ActionVerb, subject interface{}, name string, fn func() error) {
 	ad._actions = append(ad._actions, (action{
 		verb:    verb,
 		subject: subject,
+		name:    name,
 		fn:      fn,
 	}))
 }
+
+// Plan describes the pending actions Run() would execute for the same arguments, without executing them
+func (ad *ActionDispatcher) Plan(verb ActionVerb, subjects ...interface{}) []string {
+	if (len(subjects)) == 0 {
+		subjects = ad.allSubjects()
+	}
+	var plan []string
+	for _, sub := range subjects {
+		for _, action := range ad._actions {
+			if action.done || action.verb != verb || action.subject != sub {
+				continue
+			}
+			plan = append(plan, fmt.Sprintf("%s %v %q", action.verb, action.subject, action.name))
+		}
+	}
+	return plan
+}
@@ -0,0 +1,26 @@
+package manager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestActionDispatcherRunsActionsOnce(t *testing.T) {
+	ad := &ActionDispatcher{ctx: context.Background(), logger: zap.NewNop().Sugar()}
+	calls := map[string]int{}
+	add := func(subject, name string) {
+		ad.AddFunc(UpdateAction, subject, name, func() error { calls[name]++; return nil })
+	}
+	add("routingpolicy", "www")
+	add("ruleset", "redirect")
+
+	assert.Equal(t, []string{`update routingpolicy "www"`, `update ruleset "redirect"`}, ad.Plan(UpdateAction))
+	require.NoError(t, ad.Run(UpdateAction, "routingpolicy"))
+	require.NoError(t, ad.Run(UpdateAction))
+	assert.Equal(t, map[string]int{"www": 1, "redirect": 1}, calls)
+	assert.Empty(t, ad.Plan(UpdateAction))
+}
@@ -0,0 +1,136 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/ingress"
+	"github.com/oracle/oci-go-sdk/v46/common"
+	"github.com/oracle/oci-go-sdk/v46/loadbalancer"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/sets"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// drainingBackends maps backends being drained, as "<backendSet>/<ip>:<port>", to the time draining started
+type drainingBackends map[string]time.Time
+
+// equal tells whether both record the same backends, drained since the same times
+func (d drainingBackends) equal(other drainingBackends) bool {
+	if len(d) != len(other) {
+		return false
+	}
+	for key, started := range d {
+		if otherStarted, found := other[key]; !found || !started.Equal(otherStarted) {
+			return false
+		}
+	}
+	return true
+}
+
+func getDrainingBackends(obj oci.AnnotatedObject, logger *zap.SugaredLogger) drainingBackends {
+	draining := drainingBackends{}
+	if value := oci.GetAnnotation(obj, oci.AnnotationDrainingBackends); value != "" {
+		if err := json.Unmarshal([]byte(value), &draining); err != nil {
+			// Backends are drained again, for a full period
+			logger.Warnf("Ignoring invalid %s annotation: %s", oci.AnnotationDrainingBackends, err)
+			return drainingBackends{}
+		}
+	}
+	return draining
+}
+
+// drainRemovedBackends removes backends from backend sets in two phases. Backends the spec removes from a backend set
+// are kept in it with the drain flag set, until the drain period is over. Draining backends are recorded on the ingress
+// (or gateway) so that restarts don't reset their period. Those of a load balancer shared by a group are recorded on the
+// group leader whichever member is synced, so that members don't restart each other's drains. Returned is how long until
+// the next drain period is over.
+func (mgr *lbManager) drainRemovedBackends(ctx context.Context, obj k8sclient.Object, lb *loadbalancer.LoadBalancer, spec *ingress.IngressLBSpec, logger *zap.SugaredLogger) (time.Duration, error) {
+	if spec.GroupLeader != nil {
+		// Backends are drained again, for a full period, when the leader leaves the group
+		obj = spec.GroupLeader
+	}
+	period, err := ingress.GetBackendDrainPeriod(obj)
+	if err != nil {
+		return 0, err
+	}
+	recorded := getDrainingBackends(obj, logger)
+	draining, requeueAfter := keepDrainingBackends(lb.BackendSets, spec.BackendSets, recorded, period, time.Now())
+	if len(draining) != 0 {
+		logger.With("drainPeriod", period).Infof("Draining %d backends removed from backend sets", len(draining))
+	}
+	if recorded.equal(draining) {
+		return requeueAfter, nil
+	}
+	patch := k8sclient.MergeFrom(obj.DeepCopyObject().(k8sclient.Object))
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	if len(draining) == 0 {
+		delete(annotations, oci.IngressAnnotationPrefix+oci.AnnotationDrainingBackends)
+	} else {
+		value, _ := json.Marshal(draining)
+		annotations[oci.IngressAnnotationPrefix+oci.AnnotationDrainingBackends] = string(value)
+	}
+	obj.SetAnnotations(annotations)
+	if err := mgr.k8sClient.Patch(ctx, obj, patch); err != nil {
+		return 0, fmt.Errorf("could not record draining backends on %s: %v", objectKind(obj), err)
+	}
+	return requeueAfter, nil
+}
+
+// keepDrainingBackends adds backends of actual backend sets missing in the desired ones back to them, drained, unless
+// their drain period is over. Returned are the backends still draining, and how long until the first of them is done.
+func keepDrainingBackends(actual map[string]loadbalancer.BackendSet, desired map[string]loadbalancer.BackendSetDetails, recorded drainingBackends, period time.Duration, now time.Time) (drainingBackends, time.Duration) {
+	draining := drainingBackends{}
+	var requeueAfter time.Duration
+	if period <= 0 {
+		return draining, 0
+	}
+	for name, actualBackendSet := range actual {
+		desiredBackendSet, found := desired[name]
+		if !found {
+			// Whole backend set is deleted along with its listeners
+			continue
+		}
+		desiredBackends := sets.NewString()
+		for _, backend := range desiredBackendSet.Backends {
+			desiredBackends.Insert(fmt.Sprintf("%s:%d", *backend.IpAddress, *backend.Port))
+		}
+		backends := append([]loadbalancer.BackendDetails{}, desiredBackendSet.Backends...)
+		for _, backend := range actualBackendSet.Backends {
+			backendName := fmt.Sprintf("%s:%d", *backend.IpAddress, *backend.Port)
+			if desiredBackends.Has(backendName) {
+				continue
+			}
+			key := name + "/" + backendName
+			started, found := recorded[key]
+			if !found {
+				started = now
+			}
+			remaining := period - now.Sub(started)
+			if remaining <= 0 {
+				continue
+			}
+			draining[key] = started
+			backends = append(backends, loadbalancer.BackendDetails{
+				IpAddress: backend.IpAddress,
+				Port:      backend.Port,
+				Weight:    backend.Weight,
+				Backup:    backend.Backup,
+				Offline:   backend.Offline,
+				Drain:     common.Bool(true),
+			})
+			if requeueAfter == 0 || remaining < requeueAfter {
+				requeueAfter = remaining
+			}
+		}
+		desiredBackendSet.Backends = backends
+		desired[name] = desiredBackendSet
+	}
+	return draining, requeueAfter
+}
@@ -0,0 +1,94 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/ingress"
+	"github.com/oracle/oci-go-sdk/v46/common"
+	"github.com/oracle/oci-go-sdk/v46/loadbalancer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestKeepDrainingBackends(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	backend := func(ip string) loadbalancer.Backend {
+		return loadbalancer.Backend{IpAddress: common.String(ip), Port: common.Int(30080), Weight: common.Int(1), Drain: common.Bool(false)}
+	}
+	actual := map[string]loadbalancer.BackendSet{
+		"web": {Backends: []loadbalancer.Backend{backend("10.0.0.1"), backend("10.0.0.2"), backend("10.0.0.3"), backend("10.0.0.4")}},
+		"old": {Backends: []loadbalancer.Backend{backend("10.0.0.1")}},
+	}
+	desired := map[string]loadbalancer.BackendSetDetails{
+		"web": {Backends: []loadbalancer.BackendDetails{{IpAddress: common.String("10.0.0.1"), Port: common.Int(30080)}}},
+	}
+	recorded := drainingBackends{
+		"web/10.0.0.3:30080": now.Add(-10 * time.Second),
+		"web/10.0.0.4:30080": now.Add(-time.Minute), // drain period is over
+		"web/10.0.0.9:30080": now.Add(-10 * time.Second),
+	}
+
+	draining, requeueAfter := keepDrainingBackends(actual, desired, recorded, 30*time.Second, now)
+	assert.Equal(t, drainingBackends{"web/10.0.0.2:30080": now, "web/10.0.0.3:30080": now.Add(-10 * time.Second)}, draining)
+	assert.Equal(t, 20*time.Second, requeueAfter)
+	backends := desired["web"].Backends
+	require.Len(t, backends, 3)
+	assert.Equal(t, "10.0.0.2", *backends[1].IpAddress)
+	assert.True(t, *backends[1].Drain)
+	assert.Equal(t, "10.0.0.3", *backends[2].IpAddress)
+	assert.NotContains(t, desired, "old")
+
+	draining, requeueAfter = keepDrainingBackends(actual, desired, recorded, 0, now)
+	assert.Empty(t, draining)
+	assert.Zero(t, requeueAfter)
+}
+
+func TestDrainRemovedBackendsOfGroup(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	member := func(name string) *networking.Ingress {
+		return &networking.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Annotations: map[string]string{
+			oci.IngressAnnotationPrefix + oci.AnnotationBackendDrainPeriod: "1m",
+		}}}
+	}
+	leader, other := member("leader"), member("other")
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(leader, other).Build()
+	mgr := &lbManager{k8sClient: k8sClient}
+
+	backend := loadbalancer.Backend{IpAddress: common.String("10.0.0.2"), Port: common.Int(30080), Weight: common.Int(1)}
+	lb := &loadbalancer.LoadBalancer{BackendSets: map[string]loadbalancer.BackendSet{"web": {Backends: []loadbalancer.Backend{backend}}}}
+	newSpec := func() *ingress.IngressLBSpec {
+		spec := &ingress.IngressLBSpec{Ingress: other, GroupLeader: leader}
+		spec.BackendSets = map[string]loadbalancer.BackendSetDetails{"web": {}}
+		return spec
+	}
+
+	// Syncing another member records the drain on the leader
+	spec := newSpec()
+	requeueAfter, err := mgr.drainRemovedBackends(context.Background(), other, lb, spec, zap.NewNop().Sugar())
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, requeueAfter)
+	require.Len(t, spec.BackendSets["web"].Backends, 1)
+	assert.True(t, *spec.BackendSets["web"].Backends[0].Drain)
+	assert.NotContains(t, other.Annotations, oci.IngressAnnotationPrefix+oci.AnnotationDrainingBackends)
+	recorded := getDrainingBackends(leader, zap.NewNop().Sugar())
+	require.Contains(t, recorded, "web/10.0.0.2:30080")
+
+	// Syncing the leader, or any other member, keeps the drain going instead of restarting it
+	leader.Annotations[oci.IngressAnnotationPrefix+oci.AnnotationDrainingBackends] = `{"web/10.0.0.2:30080":"` + time.Now().Add(-50*time.Second).Format(time.RFC3339) + `"}`
+	requeueAfter, err = mgr.drainRemovedBackends(context.Background(), leader, lb, newSpec(), zap.NewNop().Sugar())
+	require.NoError(t, err)
+	assert.LessOrEqual(t, requeueAfter, 10*time.Second)
+	requeueAfter, err = mgr.drainRemovedBackends(context.Background(), other, lb, newSpec(), zap.NewNop().Sugar())
+	require.NoError(t, err)
+	assert.LessOrEqual(t, requeueAfter, 10*time.Second)
+}
@@ -0,0 +1,18 @@
+package manager
+
+// Reasons of the kubernetes events recorded on ingress objects
+const (
+	ReasonInvalidIngress       = "InvalidIngress"
+	ReasonWildcardHost         = "WildcardHost"
+	ReasonCreatingLoadBalancer = "CreatingLoadBalancer"
+	ReasonCreatedLoadBalancer  = "CreatedLoadBalancer"
+	ReasonAdoptedLoadBalancer  = "AdoptedLoadBalancer"
+	ReasonDeletingLoadBalancer = "DeletingLoadBalancer"
+	ReasonDeletedLoadBalancer  = "DeletedLoadBalancer"
+	ReasonWorkRequestSucceeded = "WorkRequestSucceeded"
+	ReasonWorkRequestFailed    = "WorkRequestFailed"
+	ReasonCertificateRotated   = "CertificateRotated"
+	ReasonSynced               = "Synced"
+	ReasonSyncFailed           = "SyncFailed"
+	ReasonPlanned              = "Planned"
+)
@@ -0,0 +1,314 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/nom3ad/oci-lb-ingress-controller/src/configholder"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/ingress"
+	"github.com/oracle/oci-go-sdk/v46/loadbalancer"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// ReasonInvalidGateway is recorded on gateways of which load balancer spec can't be derived
+const ReasonInvalidGateway = "InvalidGateway"
+
+// objectKind names the kind of the object in logs and errors. Kind is not set on typed objects read from the API.
+func objectKind(obj k8sclient.Object) string {
+	if _, ok := obj.(*gatewayv1beta1.Gateway); ok {
+		return "gateway"
+	}
+	return "ingress"
+}
+
+// gatewayConfigFor returns the config of the gateway, overridden by the IngressClassParameters of its GatewayClass if any.
+// Config is used as is once the class is gone, so that the load balancer can still be deleted.
+func (mgr *lbManager) gatewayConfigFor(ctx context.Context, gw *gatewayv1beta1.Gateway) (configholder.ConfigHolder, error) {
+	class := &gatewayv1beta1.GatewayClass{}
+	if err := mgr.k8sClient.Get(ctx, types.NamespacedName{Name: string(gw.Spec.GatewayClassName)}, class); err != nil {
+		if apierrors.IsNotFound(err) {
+			return mgr.conf, nil
+		}
+		return nil, errors.Wrapf(err, "get gateway class %q", gw.Spec.GatewayClassName)
+	}
+	params, err := ingress.GetGatewayClassParameters(ctx, mgr.k8sClient, class)
+	if err != nil {
+		return nil, err
+	}
+	return configholder.WithIngressClassParameters(mgr.conf, params), nil
+}
+
+// listGatewayRoutes lists the HTTPRoutes referring to the gateway as a parent. Routes being deleted are left out.
+func (mgr *lbManager) listGatewayRoutes(ctx context.Context, gw *gatewayv1beta1.Gateway) ([]gatewayv1beta1.HTTPRoute, error) {
+	routeList := &gatewayv1beta1.HTTPRouteList{}
+	if err := mgr.k8sClient.List(ctx, routeList); err != nil {
+		return nil, errors.Wrap(err, "list HTTPRoutes")
+	}
+	gatewayName := types.NamespacedName{Namespace: gw.Namespace, Name: gw.Name}
+	var routes []gatewayv1beta1.HTTPRoute
+	for _, route := range routeList.Items {
+		if route.DeletionTimestamp != nil {
+			continue
+		}
+		for _, parent := range ingress.GetRouteGateways(&route) {
+			if parent == gatewayName {
+				routes = append(routes, route)
+				break
+			}
+		}
+	}
+	return routes, nil
+}
+
+// tryGetGatewayLoadBalancer finds the load balancer of the gateway, by the OCID recorded on it, by display name, or by freeform tags
+func (mgr *lbManager) tryGetGatewayLoadBalancer(ctx context.Context, conf configholder.ConfigHolder, gw *gatewayv1beta1.Gateway, logger *zap.SugaredLogger) (*loadbalancer.LoadBalancer, error) {
+	lb, err := mgr.tryGetRecordedLoadBalancer(ctx, gw, logger)
+	if err != nil || lb != nil {
+		return lb, err
+	}
+	lb, err = mgr.tryGetLoadBalancerByName(ctx, conf, ingress.GetGatewayLoadBalancerName(gw.Namespace, gw.Name), logger)
+	if err != nil || lb != nil || gw.UID == "" {
+		return lb, err
+	}
+	return mgr.tryGetLoadBalancerByFreeformTag(ctx, conf, FreeformTagGatewayUID, string(gw.UID), logger)
+}
+
+// ensureGatewayOwnership stamps the freeform tags of the gateway on a load balancer that is not tagged for it yet.
+// A load balancer of ingresses, or of another gateway which still exists, is refused. See checkAdoptable too.
+func (mgr *lbManager) ensureGatewayOwnership(ctx context.Context, conf configholder.ConfigHolder, gw *gatewayv1beta1.Gateway, lb *loadbalancer.LoadBalancer, spec *ingress.IngressLBSpec, logger *zap.SugaredLogger) error {
+	if specOwnsLoadBalancer(spec, lb) {
+		return mgr.ensureClusterTag(ctx, gw, lb)
+	}
+	if err := checkAdoptable(conf, gw, lb); err != nil {
+		return err
+	}
+	if _, tagged := lb.FreeformTags[FreeformTagIngressUID]; tagged {
+		return errors.Errorf("Lb %s (%s) is owned by an ingress. Cant adopt it", *lb.Id, *lb.DisplayName)
+	}
+	if group, grouped := lb.FreeformTags[FreeformTagLoadBalancerGroup]; grouped {
+		return errors.Errorf("Lb %s (%s) is shared by load balancer group %q. Cant adopt it", *lb.Id, *lb.DisplayName, group)
+	}
+	ownerUID, tagged := lb.FreeformTags[FreeformTagGatewayUID]
+	if tagged {
+		owner := &gatewayv1beta1.Gateway{}
+		ownerName := types.NamespacedName{Namespace: lb.FreeformTags[FreeformTagGatewayNamespace], Name: lb.FreeformTags[FreeformTagGatewayName]}
+		err := mgr.k8sClient.Get(ctx, ownerName, owner)
+		if err == nil && string(owner.UID) == ownerUID {
+			return errors.Errorf("Lb %s (%s) is owned by gateway %s. Cant adopt it", *lb.Id, *lb.DisplayName, ownerName)
+		}
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "get owner gateway %s of load balancer %s", ownerName, *lb.Id)
+		}
+	}
+	logger.With("previousGatewayUID", ownerUID).Info("Adopting LB")
+	tags := map[string]string{}
+	for k, v := range lb.FreeformTags {
+		tags[k] = v
+	}
+	for k, v := range specOwnerTags(spec) {
+		tags[k] = v
+	}
+	wrID, err := mgr.client.LoadBalancer().UpdateLoadBalancer(ctx, *lb.Id, loadbalancer.UpdateLoadBalancerDetails{FreeformTags: tags})
+	if err := mgr.awaitRequest(ctx, gw, wrID, err, func() { lb.FreeformTags = tags }, "update freeform tags of load balancer %q", *lb.Id); err != nil {
+		return err
+	}
+	mgr.recorder.Eventf(gw, corev1.EventTypeNormal, ReasonAdoptedLoadBalancer, "Adopted load balancer %s (%s)", *lb.Id, *lb.DisplayName)
+	return nil
+}
+
+// UpdateOrCreateGateway syncs the load balancer of the gateway with its listeners and the HTTPRoutes attached to them.
+// Status of the gateway, and of its routes, is updated along.
+func (mgr *lbManager) UpdateOrCreateGateway(gw *gatewayv1beta1.Gateway) (time.Duration, error) {
+	ctx := context.Background()
+	namespacedName := types.NamespacedName{Namespace: gw.Namespace, Name: gw.Name}
+	logger := mgr.logger.With("gateway", namespacedName)
+	defer mgr.locks.Lock(ingress.GetGatewayLoadBalancerName(gw.Namespace, gw.Name))()
+
+	conf, err := mgr.gatewayConfigFor(ctx, gw)
+	if err != nil {
+		mgr.recorder.Eventf(gw, corev1.EventTypeWarning, ReasonInvalidGateway, "Couldn't get gateway class parameters: %s", err)
+		return 0, err
+	}
+	routes, err := mgr.listGatewayRoutes(ctx, gw)
+	if err != nil {
+		return 0, err
+	}
+	spec, err := ingress.NewGatewayLBSpec(conf, gw, routes, mgr.client, mgr.k8sClient, logger.Desugar())
+	if err != nil {
+		mgr.recorder.Eventf(gw, corev1.EventTypeWarning, ReasonInvalidGateway, "Couldn't derive load balancer spec: %s", err)
+		return 0, errors.Wrap(err, "Couldn't derive LB spec from gateway")
+	}
+	lb, err := mgr.tryGetGatewayLoadBalancer(ctx, conf, gw, logger)
+	if err != nil {
+		logger.With(zap.Error(err)).Error("Failed tryGetGatewayLoadBalancer()")
+		return 0, err
+	}
+	if IsPlanOnly(gw) {
+		mgr.publishPlan(gw, mgr.planLoadBalancer(ctx, lb, spec.IngressLBSpec))
+		return 0, nil
+	}
+
+	var requeueAfter time.Duration
+	if lb == nil {
+		if lb, err = mgr.createLoadBalancer(ctx, conf, spec.IngressLBSpec); err != nil {
+			return 0, errors.Wrap(err, "Failed to create Loadbalancer")
+		}
+		if err := mgr.recordLoadBalancerID(ctx, gw, *lb.Id); err != nil {
+			return 0, err
+		}
+		// create follows an update to update all associations
+		if lb, err = mgr.updateLoadBalancer(ctx, lb, spec.IngressLBSpec); err != nil {
+			return 0, errors.Wrap(err, "Failed to update newly created Loadbalancer")
+		}
+	} else {
+		if lb.LifecycleState == loadbalancer.LoadBalancerLifecycleStateFailed || lb.LifecycleState == loadbalancer.LoadBalancerLifecycleStateDeleting {
+			return 0, errors.Errorf("Lb %s (%s) is in %s state. Cant update it", *lb.Id, *lb.DisplayName, lb.LifecycleState)
+		}
+		if err := mgr.ensureGatewayOwnership(ctx, conf, gw, lb, spec.IngressLBSpec, logger); err != nil {
+			return 0, err
+		}
+		if err := mgr.recordLoadBalancerID(ctx, gw, *lb.Id); err != nil {
+			return 0, err
+		}
+		if requeueAfter, err = mgr.drainRemovedBackends(ctx, gw, lb, spec.IngressLBSpec, logger); err != nil {
+			return 0, err
+		}
+		if lb, err = mgr.updateLoadBalancer(ctx, lb, spec.IngressLBSpec); err != nil {
+			return 0, errors.Wrap(err, "Failed to update existing Loadbalancer")
+		}
+	}
+	if err := mgr.updateGatewayStatus(ctx, gw, lb, spec); err != nil {
+		return 0, errors.Wrap(err, "Failed to update gateway status")
+	}
+	return requeueAfter, mgr.updateRouteParents(ctx, gw, routes, spec.RouteParents)
+}
+
+// DeleteGateway deletes the load balancer of the gateway, and drops the status the gateway reported on routes
+func (mgr *lbManager) DeleteGateway(gw *gatewayv1beta1.Gateway) error {
+	ctx := context.Background()
+	namespacedName := types.NamespacedName{Namespace: gw.Namespace, Name: gw.Name}
+	logger := mgr.logger.With("gateway", namespacedName)
+	defer mgr.locks.Lock(ingress.GetGatewayLoadBalancerName(gw.Namespace, gw.Name))()
+
+	conf, err := mgr.gatewayConfigFor(ctx, gw)
+	if err != nil {
+		return err
+	}
+	lb, err := mgr.tryGetGatewayLoadBalancer(ctx, conf, gw, logger)
+	if err != nil {
+		logger.With(zap.Error(err)).Error("Failed tryGetGatewayLoadBalancer()")
+		return err
+	}
+	if lb == nil {
+		logger.Warnf("No loadbalancer exists for %s to delete", namespacedName)
+	} else if lb.FreeformTags[FreeformTagGatewayUID] != string(gw.UID) {
+		// Never adopted, eg: recorded by hand. Not ours to delete.
+		logger.With("loadBalancerID", *lb.Id).Warn("Leaving loadbalancer not owned by the gateway")
+	} else if err := mgr.deleteLoadBalancer(ctx, gw, lb, logger); err != nil {
+		return err
+	}
+	routes, err := mgr.listGatewayRoutes(ctx, gw)
+	if err != nil {
+		return err
+	}
+	return mgr.updateRouteParents(ctx, gw, routes, nil)
+}
+
+// updateGatewayStatus reports the address of the load balancer, and the status of listeners, on the gateway
+func (mgr *lbManager) updateGatewayStatus(ctx context.Context, gw *gatewayv1beta1.Gateway, lb *loadbalancer.LoadBalancer, spec *ingress.GatewayLBSpec) error {
+	addressType := gatewayv1beta1.IPAddressType
+	gw.Status.Addresses = nil
+	for _, ip := range lb.IpAddresses {
+		if ip.IpAddress != nil {
+			gw.Status.Addresses = append(gw.Status.Addresses, gatewayv1beta1.GatewayAddress{Type: &addressType, Value: *ip.IpAddress})
+		}
+	}
+	meta.SetStatusCondition(&gw.Status.Conditions, metav1.Condition{
+		Type:               string(gatewayv1beta1.GatewayConditionScheduled),
+		Status:             metav1.ConditionTrue,
+		Reason:             string(gatewayv1beta1.GatewayReasonScheduled),
+		ObservedGeneration: gw.Generation,
+	})
+	ready := metav1.Condition{
+		Type:               string(gatewayv1beta1.GatewayConditionReady),
+		Status:             metav1.ConditionTrue,
+		Reason:             string(gatewayv1beta1.GatewayReasonReady),
+		ObservedGeneration: gw.Generation,
+	}
+	for _, listener := range spec.ListenerStatuses {
+		if !meta.IsStatusConditionTrue(listener.Conditions, string(gatewayv1beta1.ListenerConditionReady)) {
+			ready.Status, ready.Reason, ready.Message = metav1.ConditionFalse, string(gatewayv1beta1.GatewayReasonListenersNotValid), fmt.Sprintf("listener %q is not ready", listener.Name)
+			break
+		}
+	}
+	if len(gw.Status.Addresses) == 0 {
+		ready.Status, ready.Reason, ready.Message = metav1.ConditionFalse, string(gatewayv1beta1.GatewayReasonAddressNotAssigned), "load balancer has no IP address"
+	}
+	meta.SetStatusCondition(&gw.Status.Conditions, ready)
+
+	listeners := make([]gatewayv1beta1.ListenerStatus, len(spec.ListenerStatuses))
+	for i, listener := range spec.ListenerStatuses {
+		listeners[i] = listener
+		listeners[i].Conditions = nil
+		for _, previous := range gw.Status.Listeners {
+			if previous.Name == listener.Name {
+				listeners[i].Conditions = previous.Conditions
+			}
+		}
+		for _, condition := range listener.Conditions {
+			meta.SetStatusCondition(&listeners[i].Conditions, condition)
+		}
+	}
+	gw.Status.Listeners = listeners
+	if err := mgr.k8sClient.Status().Update(ctx, gw); err != nil {
+		return fmt.Errorf("could not update gateway status: %v", err)
+	}
+	return nil
+}
+
+// updateRouteParents replaces the status the gateway reported on routes with parents. Status reported for other
+// gateways, or by other controllers, is kept. Nil parents drop the status of the gateway.
+func (mgr *lbManager) updateRouteParents(ctx context.Context, gw *gatewayv1beta1.Gateway, routes []gatewayv1beta1.HTTPRoute, parents map[types.NamespacedName][]gatewayv1beta1.RouteParentStatus) error {
+	for i := range routes {
+		route := &routes[i]
+		var statuses []gatewayv1beta1.RouteParentStatus
+		previous := map[int]gatewayv1beta1.RouteParentStatus{}
+		for _, status := range route.Status.Parents {
+			if string(status.ControllerName) != ingress.GatewayControllerName || !ingress.RefersToGateway(status.ParentRef, route.Namespace, gw) {
+				statuses = append(statuses, status)
+				continue
+			}
+			for j, parent := range parents[types.NamespacedName{Namespace: route.Namespace, Name: route.Name}] {
+				if reflect.DeepEqual(parent.ParentRef, status.ParentRef) {
+					previous[j] = status
+				}
+			}
+		}
+		for j, parent := range parents[types.NamespacedName{Namespace: route.Namespace, Name: route.Name}] {
+			conditions := previous[j].Conditions
+			for _, condition := range parent.Conditions {
+				meta.SetStatusCondition(&conditions, condition)
+			}
+			parent.Conditions = conditions
+			statuses = append(statuses, parent)
+		}
+		if reflect.DeepEqual(statuses, route.Status.Parents) {
+			continue
+		}
+		route.Status.Parents = statuses
+		if err := mgr.k8sClient.Status().Update(ctx, route); k8sclient.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("could not update status of HTTPRoute %s/%s: %v", route.Namespace, route.Name, err)
+		}
+	}
+	return nil
+}
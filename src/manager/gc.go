@@ -0,0 +1,191 @@
+package manager
+
+import (
+	"context"
+	"time"
+
+	ociclient "github.com/nom3ad/oci-lb-ingress-controller/pkg/oci/client"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/configholder"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/ingress"
+	"github.com/oracle/oci-go-sdk/v46/loadbalancer"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// OrphanGCInterval is the interval between two sweeps of orphaned load balancers. Zero disables the collector.
+	OrphanGCInterval = 15 * time.Minute
+	// OrphanGCGracePeriod is how long a load balancer has to be continuously seen as orphaned before it is collected.
+	OrphanGCGracePeriod = 1 * time.Hour
+	// OrphanGCDryRun makes the collector only report orphaned load balancers, without deleting them.
+	OrphanGCDryRun = true
+)
+
+// OrphanCollector periodically deletes load balancers created by this controller, whose ingress no longer exists.
+// Load balancers are matched with ingresses using the IngressUID freeform tag, or the LoadBalancerGroup one if shared by a group.
+// Only load balancers with the ClusterID freeform tag of this cluster are collected.
+type OrphanCollector struct {
+	client    ociclient.Interface
+	conf      configholder.ConfigHolder
+	k8sReader k8sclient.Reader
+	logger    *zap.SugaredLogger
+
+	interval    time.Duration
+	gracePeriod time.Duration
+	dryRun      bool
+
+	// orphanSince holds the time at which a load balancer (by OCID) was first seen without its ingress
+	orphanSince map[string]time.Time
+}
+
+// NewOrphanCollector returns a collector configured with OrphanGCInterval, OrphanGCGracePeriod and OrphanGCDryRun
+func NewOrphanCollector(ociClient ociclient.Interface, conf configholder.ConfigHolder, k8sReader k8sclient.Reader, logger *zap.Logger) *OrphanCollector {
+	return &OrphanCollector{
+		client:      ociClient,
+		conf:        conf,
+		k8sReader:   k8sReader,
+		logger:      logger.Sugar().Named("orphan-gc"),
+		interval:    OrphanGCInterval,
+		gracePeriod: OrphanGCGracePeriod,
+		dryRun:      OrphanGCDryRun,
+		orphanSince: map[string]time.Time{},
+	}
+}
+
+// Start implements manager.Runnable
+func (gc *OrphanCollector) Start(ctx context.Context) error {
+	gc.logger.With("interval", gc.interval, "gracePeriod", gc.gracePeriod, "dryRun", gc.dryRun).Info("Starting orphaned loadbalancer collector")
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := gc.Sweep(ctx); err != nil {
+			gc.logger.With(zap.Error(err)).Error("Orphaned loadbalancer sweep failed")
+		}
+	}, gc.interval)
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable
+func (gc *OrphanCollector) NeedLeaderElection() bool {
+	return true
+}
+
+// Sweep runs a single pass of the collector
+func (gc *OrphanCollector) Sweep(ctx context.Context) error {
+	// Ingresses are listed before load balancers, so that an ingress created in between can't get its load balancer collected.
+	ingressList := &networking.IngressList{}
+	if err := gc.k8sReader.List(ctx, ingressList); err != nil {
+		// Without a complete view of ingresses, every load balancer would look orphaned.
+		return errors.Wrap(err, "Couldn't list ingresses. Skipping sweep")
+	}
+	liveUIDs := sets.NewString()
+	liveGroups := sets.NewString()
+	// Load balancers are looked up in the compartment of the config and the ones set by IngressClassParameters
+	compartmentIDs := sets.NewString(gc.conf.GetCompartmentId())
+	for i := range ingressList.Items {
+		ing := &ingressList.Items[i]
+		_, ours, err := ingress.GetIngressClass(ctx, ing)
+		if err != nil {
+			return errors.Wrap(err, "Couldn't resolve ingress class. Skipping sweep")
+		}
+		if ours || ingress.HasIngressFinalizer(ing) {
+			// A load balancer of the ingress from before it joined a group is orphaned
+			if group := ingress.GetLoadBalancerGroup(ing); group != "" {
+				liveGroups.Insert(group)
+			} else {
+				liveUIDs.Insert(string(ing.UID))
+			}
+		}
+		if !ours {
+			continue
+		}
+		params, err := ingress.GetIngressClassParameters(ctx, ing)
+		if err != nil {
+			return errors.Wrap(err, "Couldn't get ingress class parameters. Skipping sweep")
+		}
+		if params != nil && params.CompartmentID != "" {
+			compartmentIDs.Insert(params.CompartmentID)
+		}
+	}
+
+	var lbs []loadbalancer.LoadBalancer
+	for _, compartmentID := range compartmentIDs.List() {
+		compartmentLBs, err := gc.client.LoadBalancer().ListLoadBalancers(ctx, compartmentID)
+		if err != nil {
+			return errors.Wrapf(err, "Couldn't list loadbalancers in compartment %s", compartmentID)
+		}
+		lbs = append(lbs, compartmentLBs...)
+	}
+
+	now := time.Now()
+	seen := sets.NewString()
+	for i := range lbs {
+		lb := &lbs[i]
+		uid, managed := lb.FreeformTags[FreeformTagIngressUID]
+		group, grouped := lb.FreeformTags[FreeformTagLoadBalancerGroup]
+		if !(managed || grouped) || lb.Id == nil {
+			continue
+		}
+		// Live ingresses are only known for this cluster. Load balancers tagged before ClusterID was set are skipped too,
+		// they get the tag on the next sync of their ingress.
+		if ClusterID != "" && lb.FreeformTags[FreeformTagClusterID] != ClusterID {
+			continue
+		}
+		if lb.LifecycleState == loadbalancer.LoadBalancerLifecycleStateDeleting || lb.LifecycleState == loadbalancer.LoadBalancerLifecycleStateDeleted {
+			continue
+		}
+		if (grouped && liveGroups.Has(group)) || (!grouped && liveUIDs.Has(uid)) {
+			continue
+		}
+		id := *lb.Id
+		seen.Insert(id)
+		logger := gc.logger.With("loadBalancerID", id, "loadBalancerName", lb.DisplayName,
+			"ingress", lb.FreeformTags[FreeformTagIngressNamespace]+"/"+lb.FreeformTags[FreeformTagIngressName], "ingressUID", uid, "loadBalancerGroup", group)
+
+		since, found := gc.orphanSince[id]
+		if !found {
+			since = now
+			gc.orphanSince[id] = since
+		}
+		if lb.TimeCreated != nil && lb.TimeCreated.After(since) {
+			since = lb.TimeCreated.Time
+		}
+		if orphanedFor := now.Sub(since); orphanedFor < gc.gracePeriod {
+			logger.Infof("Loadbalancer has no ingress. It will be collected if it stays orphaned for %s", gc.gracePeriod-orphanedFor)
+			continue
+		}
+		if gc.dryRun {
+			logger.Warn("Loadbalancer is orphaned. Not deleting it as dry-run mode is enabled")
+			continue
+		}
+		logger.Warn("Deleting orphaned loadbalancer")
+		if err := gc.deleteLoadBalancer(ctx, id); err != nil {
+			logger.With(zap.Error(err)).Error("Failed to delete orphaned loadbalancer")
+			continue
+		}
+		delete(gc.orphanSince, id)
+		logger.Info("Deleted orphaned loadbalancer")
+	}
+
+	// Forget load balancers which are not orphaned anymore (or gone)
+	for id := range gc.orphanSince {
+		if !seen.Has(id) {
+			delete(gc.orphanSince, id)
+		}
+	}
+	return nil
+}
+
+func (gc *OrphanCollector) deleteLoadBalancer(ctx context.Context, id string) error {
+	wrID, err := gc.client.LoadBalancer().DeleteLoadBalancer(ctx, id)
+	if err != nil {
+		if ociclient.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	_, err = gc.client.LoadBalancer().AwaitWorkRequest(ctx, wrID)
+	return err
+}
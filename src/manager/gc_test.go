@@ -0,0 +1,264 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci"
+	ociclient "github.com/nom3ad/oci-lb-ingress-controller/pkg/oci/client"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/apis/v1alpha1"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/configholder"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/ingress"
+	"github.com/oracle/oci-go-sdk/v46/common"
+	"github.com/oracle/oci-go-sdk/v46/loadbalancer"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakeOCIClient serves load balancers from memory. Calls not implemented by fakeLoadBalancerClient panic.
+type fakeOCIClient struct {
+	ociclient.Interface
+	lb *fakeLoadBalancerClient
+}
+
+func (c *fakeOCIClient) LoadBalancer() ociclient.LoadBalancerInterface {
+	return c.lb
+}
+
+type fakeLoadBalancerClient struct {
+	ociclient.LoadBalancerInterface
+	lbs     []loadbalancer.LoadBalancer
+	listed  []string
+	deleted []string
+}
+
+func (c *fakeLoadBalancerClient) ListLoadBalancers(ctx context.Context, compartmentID string) ([]loadbalancer.LoadBalancer, error) {
+	c.listed = append(c.listed, compartmentID)
+	var lbs []loadbalancer.LoadBalancer
+	for _, lb := range c.lbs {
+		if *lb.CompartmentId == compartmentID {
+			lbs = append(lbs, lb)
+		}
+	}
+	return lbs, nil
+}
+
+func (c *fakeLoadBalancerClient) DeleteLoadBalancer(ctx context.Context, id string) (string, error) {
+	c.deleted = append(c.deleted, id)
+	return "wr-" + id, nil
+}
+
+func (c *fakeLoadBalancerClient) AwaitWorkRequest(ctx context.Context, id string) (*loadbalancer.WorkRequest, error) {
+	return &loadbalancer.WorkRequest{Id: common.String(id)}, nil
+}
+
+// failingReader fails listing ingresses, or getting ingress classes
+type failingReader struct {
+	k8sclient.Reader
+	failList, failGet bool
+}
+
+func (r *failingReader) List(ctx context.Context, list k8sclient.ObjectList, opts ...k8sclient.ListOption) error {
+	if _, ingresses := list.(*networking.IngressList); ingresses && r.failList {
+		return errors.New("connection refused")
+	}
+	return r.Reader.List(ctx, list, opts...)
+}
+
+func (r *failingReader) Get(ctx context.Context, key types.NamespacedName, obj k8sclient.Object) error {
+	if _, class := obj.(*networking.IngressClass); class && r.failGet {
+		return errors.New("connection refused")
+	}
+	return r.Reader.Get(ctx, key, obj)
+}
+
+func TestOrphanCollectorSweep(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	compartmentID := "ocid1.compartment.oc1..ours"
+	conf := configholder.WithIngressClassParameters(nil, &v1alpha1.IngressClassParametersSpec{CompartmentID: compartmentID})
+	now := time.Now()
+	ClusterID = "cluster-ours"
+	defer func() { ClusterID = "" }()
+
+	className := ingress.OCILoadbalancerIngressClass
+	ing := func(name, uid, group string) *networking.Ingress {
+		ing := &networking.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID(uid)},
+			Spec:       networking.IngressSpec{IngressClassName: &className},
+		}
+		if group != "" {
+			ing.Annotations = map[string]string{oci.IngressAnnotationPrefix + oci.AnnotationLoadBalancerGroup: group}
+		}
+		return ing
+	}
+	lb := func(id string, state loadbalancer.LoadBalancerLifecycleStateEnum, tags map[string]string) loadbalancer.LoadBalancer {
+		return loadbalancer.LoadBalancer{
+			Id:             common.String(id),
+			DisplayName:    common.String(id),
+			CompartmentId:  common.String(compartmentID),
+			LifecycleState: state,
+			TimeCreated:    &common.SDKTime{Time: now.Add(-24 * time.Hour)},
+			FreeformTags:   tags,
+		}
+	}
+	active := loadbalancer.LoadBalancerLifecycleStateActive
+	ingressTags := func(uid string) map[string]string {
+		return map[string]string{FreeformTagIngressName: "web", FreeformTagIngressNamespace: "default", FreeformTagIngressUID: uid, FreeformTagClusterID: ClusterID}
+	}
+	groupTags := func(group string) map[string]string {
+		return map[string]string{FreeformTagLoadBalancerGroup: group, FreeformTagClusterID: ClusterID}
+	}
+	withClusterID := func(tags map[string]string, clusterID string) map[string]string {
+		if clusterID == "" {
+			delete(tags, FreeformTagClusterID)
+		} else {
+			tags[FreeformTagClusterID] = clusterID
+		}
+		return tags
+	}
+	recreated := lb("lb-new", active, ingressTags("gone"))
+	recreated.TimeCreated = &common.SDKTime{Time: now}
+
+	testCases := []struct {
+		name        string
+		ingresses   []runtime.Object
+		failList    bool
+		failGet     bool
+		lbs         []loadbalancer.LoadBalancer
+		orphanSince map[string]time.Time
+		dryRun      bool
+
+		wantErr     string
+		wantDeleted []string
+		wantOrphans []string
+	}{
+		{
+			name:        "new orphan waits for the grace period",
+			lbs:         []loadbalancer.LoadBalancer{lb("lb-gone", active, ingressTags("gone"))},
+			wantOrphans: []string{"lb-gone"},
+		},
+		{
+			name:        "orphan past the grace period is deleted",
+			lbs:         []loadbalancer.LoadBalancer{lb("lb-gone", active, ingressTags("gone"))},
+			orphanSince: map[string]time.Time{"lb-gone": now.Add(-2 * time.Hour)},
+			wantDeleted: []string{"lb-gone"},
+		},
+		{
+			name:        "orphan created again within the grace period is kept",
+			lbs:         []loadbalancer.LoadBalancer{recreated},
+			orphanSince: map[string]time.Time{"lb-new": now.Add(-2 * time.Hour)},
+			wantOrphans: []string{"lb-new"},
+		},
+		{
+			name:        "dry-run doesn't delete",
+			lbs:         []loadbalancer.LoadBalancer{lb("lb-gone", active, ingressTags("gone"))},
+			orphanSince: map[string]time.Time{"lb-gone": now.Add(-2 * time.Hour)},
+			dryRun:      true,
+			wantOrphans: []string{"lb-gone"},
+		},
+		{
+			name:        "load balancer whose ingress is back is forgotten",
+			ingresses:   []runtime.Object{ing("web", "uid-web", "")},
+			lbs:         []loadbalancer.LoadBalancer{lb("lb-web", active, ingressTags("uid-web"))},
+			orphanSince: map[string]time.Time{"lb-web": now.Add(-2 * time.Hour)},
+		},
+		{
+			name:        "load balancer that is gone is forgotten",
+			orphanSince: map[string]time.Time{"lb-gone": now.Add(-2 * time.Hour)},
+		},
+		{
+			name:      "load balancer of a live ingress is kept",
+			ingresses: []runtime.Object{ing("web", "uid-web", "")},
+			lbs:       []loadbalancer.LoadBalancer{lb("lb-web", active, ingressTags("uid-web"))},
+		},
+		{
+			name:        "load balancer of a live group is kept",
+			ingresses:   []runtime.Object{ing("web", "uid-web", "shared")},
+			lbs:         []loadbalancer.LoadBalancer{lb("lb-shared", active, groupTags("shared")), lb("lb-web", active, ingressTags("uid-web"))},
+			orphanSince: map[string]time.Time{"lb-shared": now.Add(-2 * time.Hour), "lb-web": now.Add(-2 * time.Hour)},
+			// load balancer of the ingress from before it joined the group is orphaned
+			wantDeleted: []string{"lb-web"},
+		},
+		{
+			name:        "deleting load balancer is skipped",
+			lbs:         []loadbalancer.LoadBalancer{lb("lb-gone", loadbalancer.LoadBalancerLifecycleStateDeleting, ingressTags("gone"))},
+			orphanSince: map[string]time.Time{"lb-gone": now.Add(-2 * time.Hour)},
+		},
+		{
+			name: "load balancer without tags of the controller is skipped",
+			lbs:  []loadbalancer.LoadBalancer{lb("lb-svc", active, map[string]string{"owner": "service"})},
+		},
+		{
+			name:        "load balancer of another cluster is skipped",
+			lbs:         []loadbalancer.LoadBalancer{lb("lb-theirs", active, withClusterID(ingressTags("gone"), "cluster-theirs"))},
+			orphanSince: map[string]time.Time{"lb-theirs": now.Add(-2 * time.Hour)},
+		},
+		{
+			name:        "load balancer tagged before the cluster was identified is skipped",
+			lbs:         []loadbalancer.LoadBalancer{lb("lb-legacy", active, withClusterID(groupTags("gone"), ""))},
+			orphanSince: map[string]time.Time{"lb-legacy": now.Add(-2 * time.Hour)},
+		},
+		{
+			name:        "sweep is aborted if ingresses can't be listed",
+			failList:    true,
+			lbs:         []loadbalancer.LoadBalancer{lb("lb-gone", active, ingressTags("gone"))},
+			orphanSince: map[string]time.Time{"lb-gone": now.Add(-2 * time.Hour)},
+			wantErr:     "Couldn't list ingresses",
+			wantOrphans: []string{"lb-gone"},
+		},
+		{
+			name:        "sweep is aborted if ingress classes can't be resolved",
+			ingresses:   []runtime.Object{ing("web", "uid-web", "")},
+			failGet:     true,
+			lbs:         []loadbalancer.LoadBalancer{lb("lb-gone", active, ingressTags("gone"))},
+			orphanSince: map[string]time.Time{"lb-gone": now.Add(-2 * time.Hour)},
+			wantErr:     "Couldn't resolve ingress class",
+			wantOrphans: []string{"lb-gone"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reader := &failingReader{
+				Reader:   fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(tc.ingresses...).Build(),
+				failList: tc.failList,
+				failGet:  tc.failGet,
+			}
+			ingress.UseIngressClasses(reader, "ingress.beta.kubernetes.io/oci")
+			lbClient := &fakeLoadBalancerClient{lbs: tc.lbs}
+			gc := NewOrphanCollector(&fakeOCIClient{lb: lbClient}, conf, reader, zap.NewNop())
+			gc.gracePeriod = time.Hour
+			gc.dryRun = tc.dryRun
+			for id, since := range tc.orphanSince {
+				gc.orphanSince[id] = since
+			}
+
+			err := gc.Sweep(context.Background())
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				assert.Empty(t, lbClient.listed, "load balancers must not be listed")
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, []string{compartmentID}, lbClient.listed)
+			}
+			assert.Equal(t, tc.wantDeleted, lbClient.deleted)
+			var orphans []string
+			for id := range gc.orphanSince {
+				orphans = append(orphans, id)
+			}
+			assert.ElementsMatch(t, tc.wantOrphans, orphans)
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package manager
+
+import (
+	"context"
+
+	"github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/configholder"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/ingress"
+	"github.com/oracle/oci-go-sdk/v46/loadbalancer"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// tryGetGroupLoadBalancer will fetch the load balancer shared by the group if it exists
+func (mgr *lbManager) tryGetGroupLoadBalancer(ctx context.Context, conf configholder.ConfigHolder, group string, logger *zap.SugaredLogger) (*loadbalancer.LoadBalancer, error) {
+	lb, err := mgr.tryGetLoadBalancerByName(ctx, conf, ingress.GetGroupLoadBalancerName(group), logger)
+	if err != nil || lb != nil {
+		return lb, err
+	}
+	return mgr.tryGetLoadBalancerByFreeformTag(ctx, conf, FreeformTagLoadBalancerGroup, group, logger)
+}
+
+// listGroupMembers lists the ingresses of this controller joining the group from namespaces allowing it. Ingresses being deleted are left out.
+func (mgr *lbManager) listGroupMembers(ctx context.Context, group string) ([]*networking.Ingress, error) {
+	ingressList := &networking.IngressList{}
+	if err := mgr.k8sClient.List(ctx, ingressList); err != nil {
+		return nil, errors.Wrap(err, "list ingresses")
+	}
+	allowedNamespaces := map[string]bool{}
+	var members []*networking.Ingress
+	for i := range ingressList.Items {
+		member := &ingressList.Items[i]
+		if ingress.GetLoadBalancerGroup(member) != group || member.DeletionTimestamp != nil {
+			continue
+		}
+		if _, ours, err := ingress.GetIngressClass(ctx, member); err != nil {
+			return nil, err
+		} else if !ours {
+			continue
+		}
+		allowed, found := allowedNamespaces[member.Namespace]
+		if !found {
+			var err error
+			if allowed, err = mgr.isGroupAllowed(ctx, member.Namespace, group); err != nil {
+				return nil, err
+			}
+			allowedNamespaces[member.Namespace] = allowed
+		}
+		if allowed {
+			members = append(members, member)
+		}
+	}
+	return members, nil
+}
+
+func (mgr *lbManager) isGroupAllowed(ctx context.Context, namespace, group string) (bool, error) {
+	ns := &corev1.Namespace{}
+	if err := mgr.k8sClient.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		return false, errors.Wrapf(err, "get namespace %q", namespace)
+	}
+	return ingress.IsLoadBalancerGroupAllowed(ns, group), nil
+}
+
+// newGroupLBSpec builds the spec of the load balancer shared by the group, by merging the specs of its members. The ingress is
+// left out of members if leaving. Returned config is the one of the oldest member. Returned spec is nil if no member is left.
+// Members which conflict with older ones, or of which spec can't be derived, are left out. Only the ingress itself fails the build.
+func (mgr *lbManager) newGroupLBSpec(ctx context.Context, ing *networking.Ingress, group string, leaving bool, logger *zap.SugaredLogger) (*ingress.IngressLBSpec, configholder.ConfigHolder, error) {
+	namespacedName := types.NamespacedName{Namespace: ing.Namespace, Name: ing.Name}
+	logger = logger.With("loadBalancerGroup", group)
+	listed, err := mgr.listGroupMembers(ctx, group)
+	if err != nil {
+		return nil, nil, err
+	}
+	var members []*networking.Ingress
+	for _, member := range listed {
+		if member.Namespace != ing.Namespace || member.Name != ing.Name {
+			members = append(members, member)
+		}
+	}
+	if !leaving {
+		if allowed, err := mgr.isGroupAllowed(ctx, ing.Namespace, group); err != nil {
+			return nil, nil, err
+		} else if !allowed {
+			return nil, nil, errors.Errorf("namespace %q does not allow load balancer group %q. See the %s namespace annotation",
+				ing.Namespace, group, oci.IngressAnnotationPrefix+oci.AnnotationAllowedLoadBalancerGroups)
+		}
+		// Cached copy may be older than the one being reconciled
+		members = append(members, ing)
+	}
+	if len(members) == 0 {
+		conf, err := mgr.configFor(ctx, ing)
+		return nil, conf, err
+	}
+
+	ingress.SortGroupMembers(members)
+	conflicts, err := ingress.FindGroupConflicts(ctx, members)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := conflicts[namespacedName]; err != nil {
+		return nil, nil, errors.Wrap(err, "conflicts with another member")
+	}
+	conf, err := mgr.configFor(ctx, members[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	var specs []*ingress.IngressLBSpec
+	for _, member := range members {
+		memberName := types.NamespacedName{Namespace: member.Namespace, Name: member.Name}
+		if err := conflicts[memberName]; err != nil {
+			logger.With("member", memberName, zap.Error(err)).Debug("Leaving out conflicting member")
+			continue
+		}
+		spec, err := ingress.NewIngressLBSpec(conf, member, mgr.client, mgr.k8sClient, logger.Desugar())
+		if err != nil {
+			if memberName == namespacedName {
+				return nil, nil, err
+			}
+			logger.With("member", memberName, zap.Error(err)).Warn("Leaving out member as its load balancer spec can't be derived")
+			continue
+		}
+		specs = append(specs, spec)
+	}
+	if len(specs) == 0 {
+		// Members still exist. Load balancer must not be taken as unused.
+		return nil, nil, errors.Errorf("load balancer spec of none of %d member(s) can be derived", len(members))
+	}
+	return ingress.MergeGroupSpecs(group, ing, specs), conf, nil
+}
+
+// leaveGroup removes the ingress from the load balancer shared by the group. Load balancer is deleted along with the last member.
+func (mgr *lbManager) leaveGroup(ctx context.Context, ing *networking.Ingress, group string, logger *zap.SugaredLogger) error {
+	logger = logger.With("loadBalancerGroup", group)
+	defer mgr.locks.Lock(ingress.GetGroupLoadBalancerName(group))()
+	spec, conf, err := mgr.newGroupLBSpec(ctx, ing, group, true, logger)
+	if err != nil {
+		return errors.Wrapf(err, "Couldn't derive LB spec of group %q", group)
+	}
+	lb, err := mgr.tryGetGroupLoadBalancer(ctx, conf, group, logger)
+	if err != nil {
+		logger.With(zap.Error(err)).Error("Failed tryGetGroupLoadBalancer()")
+		return err
+	}
+	if lb == nil {
+		logger.Warnf("No loadbalancer exists for group %q", group)
+		return nil
+	}
+	if spec == nil {
+		logger.Info("Last member is leaving the group")
+		return mgr.deleteLoadBalancer(ctx, ing, lb, logger)
+	}
+	if IsPlanOnly(ing) {
+		mgr.publishPlan(ing, mgr.planLoadBalancer(ctx, lb, spec))
+		return ErrPlanOnly
+	}
+	if lb.LifecycleState == loadbalancer.LoadBalancerLifecycleStateFailed || lb.LifecycleState == loadbalancer.LoadBalancerLifecycleStateDeleting {
+		return errors.Errorf("Lb %s (%s) is in %s state. Cant remove routes of the ingress from it", *lb.Id, *lb.DisplayName, lb.LifecycleState)
+	}
+	logger.Info("Removing ingress from the shared LB")
+	// Remaining members sync again once their drain periods are over, as recording draining backends on the leader triggers it
+	if _, err := mgr.drainRemovedBackends(ctx, ing, lb, spec, logger); err != nil {
+		return err
+	}
+	if _, err := mgr.updateLoadBalancer(ctx, lb, spec); err != nil {
+		return errors.Wrap(err, "Failed to update shared Loadbalancer")
+	}
+	return nil
+}
+
+// releasePreviousLoadBalancer releases the load balancer the ingress used before joining or leaving a group.
+// A load balancer which can't be released this way is left for the orphaned load balancer collector.
+func (mgr *lbManager) releasePreviousLoadBalancer(ctx context.Context, ing *networking.Ingress, previous *loadbalancer.LoadBalancer, logger *zap.SugaredLogger) error {
+	if group, grouped := previous.FreeformTags[FreeformTagLoadBalancerGroup]; grouped {
+		return mgr.leaveGroup(ctx, ing, group, logger)
+	}
+	defer mgr.locks.Lock(ingress.GetLoadBalancerName(ing.Namespace, ing.Name))()
+	logger.With("loadBalancerID", *previous.Id).Info("Releasing LB the ingress used before joining the group")
+	return mgr.deleteLoadBalancer(ctx, ing, previous, logger)
+}
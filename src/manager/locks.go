@@ -0,0 +1,41 @@
+package manager
+
+import "sync"
+
+// keyedMutex serializes operations per key (load balancer), while letting different keys proceed in parallel
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*refCountedMutex
+}
+
+type refCountedMutex struct {
+	sync.Mutex
+	refs int
+}
+
+func newKeyedMutex() *keyedMutex {
+	return &keyedMutex{locks: map[string]*refCountedMutex{}}
+}
+
+// Lock blocks until the lock of key is acquired. Returned function releases it.
+func (km *keyedMutex) Lock(key string) func() {
+	km.mu.Lock()
+	l, found := km.locks[key]
+	if !found {
+		l = &refCountedMutex{}
+		km.locks[key] = l
+	}
+	l.refs++
+	km.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		km.mu.Lock()
+		defer km.mu.Unlock()
+		// Drop the entry once nobody holds or waits for it, so that the map doesn't grow with deleted ingresses
+		if l.refs--; l.refs == 0 {
+			delete(km.locks, key)
+		}
+	}
+}
@@ -4,54 +4,98 @@ import (
 	"context"
 	"fmt"
 	"regexp"
-	"sync"
+	"time"
 
 	"github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci"
 	ociclient "github.com/nom3ad/oci-lb-ingress-controller/pkg/oci/client"
 	"github.com/nom3ad/oci-lb-ingress-controller/src/configholder"
 	"github.com/nom3ad/oci-lb-ingress-controller/src/ingress"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/metrics"
 	"github.com/nom3ad/oci-lb-ingress-controller/src/utils"
 	"github.com/oracle/oci-go-sdk/v46/loadbalancer"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	networking "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 )
 
-// Manager maps Kubernetes Ingress objects to OCI load balancers.
+// Freeform tags stamped on every load balancer created by this controller
+const (
+	FreeformTagIngressName      = "IngressName"
+	FreeformTagIngressNamespace = "IngressNamespace"
+	FreeformTagIngressUID       = "IngressUID"
+	// FreeformTagLoadBalancerGroup is stamped instead of the ones above on load balancers shared by a group of ingresses
+	FreeformTagLoadBalancerGroup = "LoadBalancerGroup"
+	// Stamped instead of the ones above on load balancers of gateways
+	FreeformTagGatewayName      = "GatewayName"
+	FreeformTagGatewayNamespace = "GatewayNamespace"
+	FreeformTagGatewayUID       = "GatewayUID"
+	// FreeformTagClusterID is stamped along all of the above, with ClusterID as value
+	FreeformTagClusterID = "ClusterID"
+)
+
+// ClusterID identifies the cluster of the controller in the ClusterID freeform tag, so that load balancers of other clusters
+// sharing the compartment are left alone. controller.Run() defaults it to the UID of the kube-system namespace.
+var ClusterID = ""
+
+// Manager maps Kubernetes Ingress, and Gateway, objects to OCI load balancers.
 type Manager interface {
-	UpdateOrCreateIngress(ingress *networking.Ingress) error
-	DeleteIngress(namespacedName types.NamespacedName) error
+	// UpdateOrCreateIngress syncs the load balancer of the ingress. It needs another sync after the returned duration if not 0.
+	UpdateOrCreateIngress(ingress *networking.Ingress) (time.Duration, error)
+	DeleteIngress(ingress *networking.Ingress) error
+	// UpdateOrCreateGateway syncs the load balancer of the gateway. It needs another sync after the returned duration if not 0.
+	UpdateOrCreateGateway(gateway *gatewayv1beta1.Gateway) (time.Duration, error)
+	DeleteGateway(gateway *gatewayv1beta1.Gateway) error
 }
 
 // ociIngressManager wraps logic for create,update,delete load balancers in OCI.
 type lbManager struct {
-	mu        sync.Mutex
+	locks     *keyedMutex
 	client    ociclient.Interface
 	conf      configholder.ConfigHolder
 	logger    *zap.SugaredLogger
 	k8sClient k8sclient.Client
+	recorder  record.EventRecorder
 	dummyCp   *oci.CloudProvider
 }
 
 // New will create a new OCILoadBalancerController
-func New(ociClient ociclient.Interface, conf configholder.ConfigHolder, controllerMgr manager.Manager, dummyCp *oci.CloudProvider, logger *zap.Logger) Manager {
+func New(ociClient ociclient.Interface, conf configholder.ConfigHolder, controllerMgr manager.Manager, recorder record.EventRecorder, dummyCp *oci.CloudProvider, logger *zap.Logger) Manager {
 	return &lbManager{
 		client:    ociClient,
 		conf:      conf,
+		locks:     newKeyedMutex(),
 		k8sClient: controllerMgr.GetClient(),
+		recorder:  recorder,
 		logger:    logger.Sugar().Named("manager"),
 		dummyCp:   dummyCp,
 	}
 }
 
-// GetLoadBalancerByName will fetch a load balancer with a given display name if it exists
-func (mgr *lbManager) tryGetLoadBalancerByNamespacedName(ctx context.Context, namespacedName types.NamespacedName, logger *zap.SugaredLogger) (*loadbalancer.LoadBalancer, error) {
-	loadBalancerName := ingress.GetLoadBalancerName(namespacedName.Namespace, namespacedName.Name)
-	compartmentID := mgr.conf.GetCompartmentId()
+// lockLoadBalancer serializes operations on the load balancer of the ingress, which is shared by all members of its group if any.
+// Returned function releases the lock.
+func (mgr *lbManager) lockLoadBalancer(ing *networking.Ingress) func() {
+	return mgr.locks.Lock(ingress.GetLoadBalancerNameForIngress(ing))
+}
+
+// configFor returns the config of the ingress, overridden by the IngressClassParameters of its class if any
+func (mgr *lbManager) configFor(ctx context.Context, ing *networking.Ingress) (configholder.ConfigHolder, error) {
+	params, err := ingress.GetIngressClassParameters(ctx, ing)
+	if err != nil {
+		return nil, err
+	}
+	return configholder.WithIngressClassParameters(mgr.conf, params), nil
+}
+
+// tryGetLoadBalancerByName will fetch a load balancer with the given display name if it exists
+func (mgr *lbManager) tryGetLoadBalancerByName(ctx context.Context, conf configholder.ConfigHolder, loadBalancerName string, logger *zap.SugaredLogger) (*loadbalancer.LoadBalancer, error) {
+	compartmentID := conf.GetCompartmentId()
 	logger.With("loadBalancerName", loadBalancerName).With("compartment", compartmentID).Debug("Get LB by name")
 	if lb, err := mgr.client.LoadBalancer().GetLoadBalancerByName(ctx, compartmentID, loadBalancerName); err != nil {
 		// { "code": "NotAuthorizedOrNotFound", "message": "Authorization failed or requested resource not found.", "status": 404 }
@@ -67,23 +111,284 @@ func (mgr *lbManager) tryGetLoadBalancerByNamespacedName(ctx context.Context, na
 	}
 }
 
-// DeleteLoadBalancerByName will delete a load balancer with a matching display name if it exists in OCI.
-func (mgr *lbManager) DeleteIngress(namespacedName types.NamespacedName) error {
+// tryGetLoadBalancerByFreeformTag will fetch a load balancer carrying the freeform tag with the given value if it exists
+func (mgr *lbManager) tryGetLoadBalancerByFreeformTag(ctx context.Context, conf configholder.ConfigHolder, tag, value string, logger *zap.SugaredLogger) (*loadbalancer.LoadBalancer, error) {
+	compartmentID := conf.GetCompartmentId()
+	logger.With("tag", tag, "value", value).With("compartment", compartmentID).Debug("Get LB by tag")
+	lbs, err := mgr.client.LoadBalancer().ListLoadBalancers(ctx, compartmentID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range lbs {
+		if lbs[i].LifecycleState == loadbalancer.LoadBalancerLifecycleStateDeleted {
+			continue
+		}
+		if lbs[i].FreeformTags[tag] == value {
+			return &lbs[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// tryGetRecordedLoadBalancer will fetch the load balancer recorded in the ingress (or gateway) annotation if it still exists
+func (mgr *lbManager) tryGetRecordedLoadBalancer(ctx context.Context, obj oci.AnnotatedObject, logger *zap.SugaredLogger) (*loadbalancer.LoadBalancer, error) {
+	id := oci.GetAnnotation(obj, oci.AnnotationLoadBalancerID)
+	if id == "" {
+		return nil, nil
+	}
+	logger.With("loadBalancerID", id).Debug("Get LB by id")
+	lb, err := mgr.client.LoadBalancer().GetLoadBalancer(ctx, id)
+	if err == nil && lb.LifecycleState != loadbalancer.LoadBalancerLifecycleStateDeleted {
+		return lb, nil
+	}
+	if err != nil && !ociclient.IsNotFound(err) {
+		return nil, errors.Wrapf(err, "get load balancer %q", id)
+	}
+	logger.With("loadBalancerID", id).Warn("Loadbalancer recorded on the object does not exist anymore")
+	return nil, nil
+}
+
+// tryGetLoadBalancer finds the load balancer of the ingress. The OCID recorded in the ingress annotation is used first.
+// Display name and freeform tags are used as fallbacks, so that load balancers created before the annotation was introduced are still found.
+// If the recorded load balancer is the one used before the ingress joined or left a group, it is returned as previous.
+func (mgr *lbManager) tryGetLoadBalancer(ctx context.Context, conf configholder.ConfigHolder, ing *networking.Ingress, logger *zap.SugaredLogger) (lb, previous *loadbalancer.LoadBalancer, err error) {
+	recorded, err := mgr.tryGetRecordedLoadBalancer(ctx, ing, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	if recorded != nil {
+		if !isPreviousLoadBalancer(ing, recorded) {
+			return recorded, nil, nil
+		}
+		previous = recorded
+	}
+	if group := ingress.GetLoadBalancerGroup(ing); group != "" {
+		lb, err = mgr.tryGetGroupLoadBalancer(ctx, conf, group, logger)
+		return lb, previous, err
+	}
+	lb, err = mgr.tryGetLoadBalancerByName(ctx, conf, ingress.GetLoadBalancerName(ing.Namespace, ing.Name), logger)
+	if err != nil || lb != nil || ing.UID == "" {
+		return lb, previous, err
+	}
+	lb, err = mgr.tryGetLoadBalancerByFreeformTag(ctx, conf, FreeformTagIngressUID, string(ing.UID), logger)
+	return lb, previous, err
+}
+
+// isPreviousLoadBalancer tells whether lb is the one the ingress used before it joined or left a load balancer group
+func isPreviousLoadBalancer(ing *networking.Ingress, lb *loadbalancer.LoadBalancer) bool {
+	group := ingress.GetLoadBalancerGroup(ing)
+	if lbGroup, grouped := lb.FreeformTags[FreeformTagLoadBalancerGroup]; grouped {
+		return lbGroup != group
+	}
+	return group != "" && lb.FreeformTags[FreeformTagIngressUID] == string(ing.UID)
+}
+
+// ownsLoadBalancer tells whether lb is tagged for the ingress, or for its group
+func ownsLoadBalancer(ing *networking.Ingress, lb *loadbalancer.LoadBalancer) bool {
+	if isOtherClusters(lb) {
+		return false
+	}
+	if group := ingress.GetLoadBalancerGroup(ing); group != "" {
+		return lb.FreeformTags[FreeformTagLoadBalancerGroup] == group
+	}
+	_, grouped := lb.FreeformTags[FreeformTagLoadBalancerGroup]
+	return !grouped && lb.FreeformTags[FreeformTagIngressUID] == string(ing.UID)
+}
+
+// ownerTags returns the freeform tags marking a load balancer as owned by the ingress, or by its group
+func ownerTags(ing *networking.Ingress) map[string]string {
+	if group := ingress.GetLoadBalancerGroup(ing); group != "" {
+		return withClusterTag(map[string]string{FreeformTagLoadBalancerGroup: group})
+	}
+	return withClusterTag(map[string]string{
+		FreeformTagIngressName:      ing.Name,
+		FreeformTagIngressNamespace: ing.Namespace,
+		FreeformTagIngressUID:       string(ing.UID),
+	})
+}
+
+// specOwnerTags returns the freeform tags marking a load balancer as owned by the ingress, group or gateway of the spec
+func specOwnerTags(spec *ingress.IngressLBSpec) map[string]string {
+	if gw := spec.Gateway; gw != nil {
+		return withClusterTag(map[string]string{
+			FreeformTagGatewayName:      gw.Name,
+			FreeformTagGatewayNamespace: gw.Namespace,
+			FreeformTagGatewayUID:       string(gw.UID),
+		})
+	}
+	return ownerTags(spec.Ingress)
+}
+
+// withClusterTag adds the ClusterID freeform tag to tags, unless ClusterID is not set
+func withClusterTag(tags map[string]string) map[string]string {
+	if ClusterID != "" {
+		tags[FreeformTagClusterID] = ClusterID
+	}
+	return tags
+}
+
+// isOtherClusters tells whether lb is tagged for another cluster. Load balancers tagged before ClusterID was set are not.
+func isOtherClusters(lb *loadbalancer.LoadBalancer) bool {
+	clusterID, tagged := lb.FreeformTags[FreeformTagClusterID]
+	return tagged && ClusterID != "" && clusterID != ClusterID
+}
+
+// specOwnsLoadBalancer tells whether lb is tagged for the ingress, group or gateway of the spec
+func specOwnsLoadBalancer(spec *ingress.IngressLBSpec, lb *loadbalancer.LoadBalancer) bool {
+	if gw := spec.Gateway; gw != nil {
+		return !isOtherClusters(lb) && lb.FreeformTags[FreeformTagGatewayUID] == string(gw.UID)
+	}
+	return ownsLoadBalancer(spec.Ingress, lb)
+}
+
+// hasControllerTags tells whether lb is tagged for an ingress, group or gateway of this controller
+func hasControllerTags(lb *loadbalancer.LoadBalancer) bool {
+	for _, tag := range []string{FreeformTagIngressUID, FreeformTagLoadBalancerGroup, FreeformTagGatewayUID} {
+		if _, tagged := lb.FreeformTags[tag]; tagged {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAdoptable refuses adopting a load balancer outside the compartment of the controller. So is a load balancer
+// carrying none of the tags of the controller, eg: of a Service, unless the object opts in with AnnotationAdoptLoadBalancer.
+func checkAdoptable(conf configholder.ConfigHolder, obj oci.AnnotatedObject, lb *loadbalancer.LoadBalancer) error {
+	if lb.CompartmentId == nil || *lb.CompartmentId != conf.GetCompartmentId() {
+		return errors.Errorf("Lb %s (%s) is not in compartment %s. Cant adopt it", *lb.Id, *lb.DisplayName, conf.GetCompartmentId())
+	}
+	if isOtherClusters(lb) {
+		return errors.Errorf("Lb %s (%s) is managed by cluster %s. Cant adopt it", *lb.Id, *lb.DisplayName, lb.FreeformTags[FreeformTagClusterID])
+	}
+	if !hasControllerTags(lb) && oci.GetAnnotationWithLowercase(obj, oci.AnnotationAdoptLoadBalancer) != "true" {
+		return errors.Errorf("Lb %s (%s) is not managed by this controller. Cant adopt it without %s%s annotation", *lb.Id, *lb.DisplayName, oci.IngressAnnotationPrefix, oci.AnnotationAdoptLoadBalancer)
+	}
+	return nil
+}
+
+// ensureOwnership stamps the freeform tags of the ingress on a load balancer that is not tagged for it yet, i.e. on adoption.
+// A load balancer of a gateway, of another ingress which still exists, or of another group, is refused. See checkAdoptable too.
+func (mgr *lbManager) ensureOwnership(ctx context.Context, conf configholder.ConfigHolder, ing *networking.Ingress, lb *loadbalancer.LoadBalancer, logger *zap.SugaredLogger) error {
+	if ownsLoadBalancer(ing, lb) {
+		return mgr.ensureClusterTag(ctx, ing, lb)
+	}
+	if err := checkAdoptable(conf, ing, lb); err != nil {
+		return err
+	}
+	if _, tagged := lb.FreeformTags[FreeformTagGatewayUID]; tagged {
+		return errors.Errorf("Lb %s (%s) is owned by a gateway. Cant adopt it", *lb.Id, *lb.DisplayName)
+	}
+	if group, grouped := lb.FreeformTags[FreeformTagLoadBalancerGroup]; grouped {
+		return errors.Errorf("Lb %s (%s) is shared by load balancer group %q. Cant adopt it", *lb.Id, *lb.DisplayName, group)
+	}
+	ownerUID, tagged := lb.FreeformTags[FreeformTagIngressUID]
+	if tagged && ownerUID != string(ing.UID) {
+		owner := &networking.Ingress{}
+		ownerName := types.NamespacedName{Namespace: lb.FreeformTags[FreeformTagIngressNamespace], Name: lb.FreeformTags[FreeformTagIngressName]}
+		err := mgr.k8sClient.Get(ctx, ownerName, owner)
+		if err == nil && string(owner.UID) == ownerUID {
+			return errors.Errorf("Lb %s (%s) is owned by ingress %s. Cant adopt it", *lb.Id, *lb.DisplayName, ownerName)
+		}
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "get owner ingress %s of load balancer %s", ownerName, *lb.Id)
+		}
+	}
+	logger.With("previousIngressUID", ownerUID).Info("Adopting LB")
+	tags := map[string]string{}
+	for k, v := range lb.FreeformTags {
+		tags[k] = v
+	}
+	if ingress.GetLoadBalancerGroup(ing) != "" {
+		// Shared load balancer must not be taken as orphaned once the adopting ingress is gone
+		delete(tags, FreeformTagIngressName)
+		delete(tags, FreeformTagIngressNamespace)
+		delete(tags, FreeformTagIngressUID)
+	}
+	for k, v := range ownerTags(ing) {
+		tags[k] = v
+	}
+	wrID, err := mgr.client.LoadBalancer().UpdateLoadBalancer(ctx, *lb.Id, loadbalancer.UpdateLoadBalancerDetails{FreeformTags: tags})
+	if err := mgr.awaitRequest(ctx, ing, wrID, err, func() { lb.FreeformTags = tags }, "update freeform tags of load balancer %q", *lb.Id); err != nil {
+		return err
+	}
+	mgr.recorder.Eventf(ing, corev1.EventTypeNormal, ReasonAdoptedLoadBalancer, "Adopted load balancer %s (%s)", *lb.Id, *lb.DisplayName)
+	return nil
+}
+
+// ensureClusterTag stamps the ClusterID freeform tag on a load balancer of the object tagged before ClusterID was set
+func (mgr *lbManager) ensureClusterTag(ctx context.Context, obj k8sclient.Object, lb *loadbalancer.LoadBalancer) error {
+	if ClusterID == "" || lb.FreeformTags[FreeformTagClusterID] == ClusterID {
+		return nil
+	}
+	tags := map[string]string{FreeformTagClusterID: ClusterID}
+	for k, v := range lb.FreeformTags {
+		if k != FreeformTagClusterID {
+			tags[k] = v
+		}
+	}
+	wrID, err := mgr.client.LoadBalancer().UpdateLoadBalancer(ctx, *lb.Id, loadbalancer.UpdateLoadBalancerDetails{FreeformTags: tags})
+	return mgr.awaitRequest(ctx, obj, wrID, err, func() { lb.FreeformTags = tags }, "update freeform tags of load balancer %q", *lb.Id)
+}
+
+// recordLoadBalancerID saves the load balancer OCID on the ingress (or gateway), to be used for later lookups
+func (mgr *lbManager) recordLoadBalancerID(ctx context.Context, obj k8sclient.Object, id string) error {
+	if oci.GetAnnotation(obj, oci.AnnotationLoadBalancerID) == id {
+		return nil
+	}
+	patch := k8sclient.MergeFrom(obj.DeepCopyObject().(k8sclient.Object))
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[oci.IngressAnnotationPrefix+oci.AnnotationLoadBalancerID] = id
+	obj.SetAnnotations(annotations)
+	if err := mgr.k8sClient.Patch(ctx, obj, patch); err != nil {
+		return fmt.Errorf("could not record load balancer id on %s: %v", objectKind(obj), err)
+	}
+	return nil
+}
+
+// DeleteIngress will delete the load balancer of the ingress if it exists in OCI and waits until the deletion is complete.
+// A load balancer shared by a group is deleted only along with its last member. Other members keep it otherwise.
+func (mgr *lbManager) DeleteIngress(ing *networking.Ingress) error {
 	ctx := context.Background()
+	namespacedName := types.NamespacedName{Namespace: ing.Namespace, Name: ing.Name}
 	logger := mgr.logger.With("ingress", namespacedName)
-	lb, err := mgr.tryGetLoadBalancerByNamespacedName(ctx, namespacedName, logger)
+	if group := ingress.GetLoadBalancerGroup(ing); group != "" {
+		return mgr.leaveGroup(ctx, ing, group, logger)
+	}
+	defer mgr.lockLoadBalancer(ing)()
+	conf, err := mgr.configFor(ctx, ing)
+	if err != nil {
+		return err
+	}
+	lb, _, err := mgr.tryGetLoadBalancer(ctx, conf, ing, logger)
 	if err != nil {
-		logger.With(zap.Error(err)).Error("Failed tryGetLoadBalancerByNamespacedName()")
+		logger.With(zap.Error(err)).Error("Failed tryGetLoadBalancer()")
 		return err
 	}
 	if lb == nil {
 		logger.Warnf("No loadbalancer exists for %s to delete", namespacedName)
 		return nil
 	}
+	if !ownsLoadBalancer(ing, lb) {
+		// Never adopted, eg: recorded by hand. Not ours to delete.
+		logger.With("loadBalancerID", *lb.Id).Warn("Leaving loadbalancer not owned by the ingress")
+		return nil
+	}
+	return mgr.deleteLoadBalancer(ctx, ing, lb, logger)
+}
+
+func (mgr *lbManager) deleteLoadBalancer(ctx context.Context, obj k8sclient.Object, lb *loadbalancer.LoadBalancer, logger *zap.SugaredLogger) error {
 	id := *lb.Id
 	name := *lb.DisplayName
+	if IsPlanOnly(obj) {
+		mgr.publishPlan(obj, []string{fmt.Sprintf("delete load balancer %q (%s)", name, id)})
+		return ErrPlanOnly
+	}
 	logger = logger.With("loadBalancerID", id, "loadBalancerName", name)
 	logger.Info("Deleting LB")
+	mgr.recorder.Eventf(obj, corev1.EventTypeNormal, ReasonDeletingLoadBalancer, "Deleting load balancer %s (%s)", id, name)
 	workReqID, err := mgr.client.LoadBalancer().DeleteLoadBalancer(ctx, *lb.Id)
 	if err != nil {
 		if ociclient.IsNotFound(err) {
@@ -96,55 +401,110 @@ func (mgr *lbManager) DeleteIngress(namespacedName types.NamespacedName) error {
 	_, err = mgr.client.LoadBalancer().AwaitWorkRequest(ctx, workReqID)
 	if err != nil {
 		logger.With(zap.Error(err)).Error("Timeout waiting for loadbalancer delete")
+		mgr.recorder.Eventf(obj, corev1.EventTypeWarning, ReasonWorkRequestFailed, "Work request %s failed: delete load balancer %s: %s", workReqID, id, err)
 		return errors.Wrapf(err, "awaiting deletion of load balancer %s|%q", name, name)
 	}
 	logger.Info("Successfully deleted LB")
+	mgr.recorder.Eventf(obj, corev1.EventTypeNormal, ReasonDeletedLoadBalancer, "Deleted load balancer %s (%s)", id, name)
 	return nil
 }
 
 // UpdateOrCreateIngress creates/update ingress based on OCI LB
-func (mgr *lbManager) UpdateOrCreateIngress(ing *networking.Ingress) error {
-	mgr.mu.Lock()
-	defer mgr.mu.Unlock()
+func (mgr *lbManager) UpdateOrCreateIngress(ing *networking.Ingress) (time.Duration, error) {
 	ctx := context.Background()
 	namespacedName := types.NamespacedName{Namespace: ing.Namespace, Name: ing.Name}
 	logger := mgr.logger.With("ingress", namespacedName)
+	previous, requeueAfter, err := mgr.updateOrCreateIngress(ctx, ing, logger)
+	if err != nil || previous == nil {
+		return requeueAfter, err
+	}
+	// Released only after the lock of the current load balancer is, so that locks of two load balancers are never held together
+	return requeueAfter, mgr.releasePreviousLoadBalancer(ctx, ing, previous, logger)
+}
 
-	spec, err := ingress.NewIngressLBSpec(mgr.conf, ing, mgr.client, mgr.k8sClient, logger.Desugar())
-	if err != nil {
-		return errors.Wrap(err, "Couldn't derive LB spec from ingress")
+// updateOrCreateIngress syncs the load balancer of the ingress. Returned is the load balancer the ingress used before
+// joining or leaving a group, if any, to be released after, and how long until backends being drained are removed.
+func (mgr *lbManager) updateOrCreateIngress(ctx context.Context, ing *networking.Ingress, logger *zap.SugaredLogger) (*loadbalancer.LoadBalancer, time.Duration, error) {
+	namespacedName := types.NamespacedName{Namespace: ing.Namespace, Name: ing.Name}
+	defer mgr.lockLoadBalancer(ing)()
+
+	var conf configholder.ConfigHolder
+	var spec *ingress.IngressLBSpec
+	var err error
+	if group := ingress.GetLoadBalancerGroup(ing); group != "" {
+		if spec, conf, err = mgr.newGroupLBSpec(ctx, ing, group, false, logger); err != nil {
+			mgr.recorder.Eventf(ing, corev1.EventTypeWarning, ReasonInvalidIngress, "Couldn't derive load balancer spec of group %q: %s", group, err)
+			return nil, 0, errors.Wrapf(err, "Couldn't derive LB spec of group %q", group)
+		}
+	} else {
+		if conf, err = mgr.configFor(ctx, ing); err != nil {
+			mgr.recorder.Eventf(ing, corev1.EventTypeWarning, ReasonInvalidIngress, "Couldn't get ingress class parameters: %s", err)
+			return nil, 0, err
+		}
+		if spec, err = ingress.NewIngressLBSpec(conf, ing, mgr.client, mgr.k8sClient, logger.Desugar()); err != nil {
+			mgr.recorder.Eventf(ing, corev1.EventTypeWarning, ReasonInvalidIngress, "Couldn't derive load balancer spec: %s", err)
+			return nil, 0, errors.Wrap(err, "Couldn't derive LB spec from ingress")
+		}
+	}
+	for _, warning := range ingress.WildcardHostWarnings(ing) {
+		mgr.recorder.Event(ing, corev1.EventTypeWarning, ReasonWildcardHost, warning)
 	}
-	lb, err := mgr.tryGetLoadBalancerByNamespacedName(ctx, namespacedName, logger)
+	lb, previous, err := mgr.tryGetLoadBalancer(ctx, conf, ing, logger)
 	if err != nil {
-		logger.With(zap.Error(err)).Error("Failed tryGetLoadBalancerByNamespacedName()")
-		return err
+		logger.With(zap.Error(err)).Error("Failed tryGetLoadBalancer()")
+		return nil, 0, err
 	}
 	exists := lb != nil //! TODO: fix upstream: !ociclient.IsNotFound(err)
+	var requeueAfter time.Duration
+	if exists {
+		metrics.SetLoadBalancerState(namespacedName, *lb.Id, string(lb.LifecycleState))
+	}
+	if IsPlanOnly(ing) {
+		plan := mgr.planLoadBalancer(ctx, lb, spec)
+		if previous != nil {
+			plan = append(plan, fmt.Sprintf("release previous load balancer %q (%s)", *previous.DisplayName, *previous.Id))
+		}
+		mgr.publishPlan(ing, plan)
+		return nil, 0, nil
+	}
 
 	if !exists {
-		if lb, err = mgr.createLoadBalancer(ctx, spec); err != nil {
-			return errors.Wrap(err, "Failed to create Loadbalancer")
+		if lb, err = mgr.createLoadBalancer(ctx, conf, spec); err != nil {
+			return nil, 0, errors.Wrap(err, "Failed to create Loadbalancer")
+		}
+		if err := mgr.recordLoadBalancerID(ctx, ing, *lb.Id); err != nil {
+			return nil, 0, err
 		}
 		// create follows an update to update all associations
 		if lb, err = mgr.updateLoadBalancer(ctx, lb, spec); err != nil {
-			return errors.Wrap(err, "Failed to update newly created Loadbalancer")
+			return nil, 0, errors.Wrap(err, "Failed to update newly created Loadbalancer")
 		}
 	} else {
 		if lb.LifecycleState == "FAILED" {
-			return errors.Errorf("Lb %s (%s) is in FAILED state. Cant update. Need to delete manually", *lb.Id, *lb.DisplayName)
+			return nil, 0, errors.Errorf("Lb %s (%s) is in FAILED state. Cant update. Need to delete manually", *lb.Id, *lb.DisplayName)
 			// TODO: Should we try delete to delete LB?
 		}
 		if lb.LifecycleState == "DELETING" {
-			return errors.Errorf("Lb %s (%s) is being deleted. Cant update it", *lb.Id, *lb.DisplayName)
+			return nil, 0, errors.Errorf("Lb %s (%s) is being deleted. Cant update it", *lb.Id, *lb.DisplayName)
+		}
+		if err := mgr.ensureOwnership(ctx, conf, ing, lb, logger); err != nil {
+			return nil, 0, err
+		}
+		if err := mgr.recordLoadBalancerID(ctx, ing, *lb.Id); err != nil {
+			return nil, 0, err
+		}
+		if requeueAfter, err = mgr.drainRemovedBackends(ctx, ing, lb, spec, logger); err != nil {
+			return nil, 0, err
 		}
 		if lb, err = mgr.updateLoadBalancer(ctx, lb, spec); err != nil {
-			return errors.Wrap(err, "Failed to update existing Loadbalancer")
+			return nil, 0, errors.Wrap(err, "Failed to update existing Loadbalancer")
 		}
 	}
+	metrics.SetLoadBalancerState(namespacedName, *lb.Id, string(lb.LifecycleState))
 	if err := mgr.updateIngressStatus(ing, lb); err != nil {
-		return errors.Wrap(err, "Failed to update ingress status")
+		return nil, 0, errors.Wrap(err, "Failed to update ingress status")
 	}
-	return nil
+	return previous, requeueAfter, nil
 }
 
 func (mgr *lbManager) updateIngressStatus(ingress *networking.Ingress, lb *loadbalancer.LoadBalancer) error {
@@ -164,10 +524,10 @@ func (mgr *lbManager) updateIngressStatus(ingress *networking.Ingress, lb *loadb
 }
 
 // https://github.dev/oracle/oci-cloud-controller-manager
-func (mgr *lbManager) createLoadBalancer(ctx context.Context, spec *ingress.IngressLBSpec) (*loadbalancer.LoadBalancer, error) {
+func (mgr *lbManager) createLoadBalancer(ctx context.Context, conf configholder.ConfigHolder, spec *ingress.IngressLBSpec) (*loadbalancer.LoadBalancer, error) {
 	logger := mgr.logger.With("loadBalancerName", spec.Name)
 	createDetails := loadbalancer.CreateLoadBalancerDetails{
-		CompartmentId: utils.PtrToString(mgr.conf.GetCompartmentId()),
+		CompartmentId: utils.PtrToString(conf.GetCompartmentId()),
 		DisplayName:   &spec.Name,
 		ShapeName:     &spec.Shape,
 		IsPrivate:     &spec.Internal,
@@ -178,12 +538,8 @@ func (mgr *lbManager) createLoadBalancer(ctx context.Context, spec *ingress.Ingr
 		Certificates:  spec.Certificates,
 		// IpMode:                  loadbalancer.CreateLoadBalancerDetailsIpModeIpv4,
 		NetworkSecurityGroupIds: spec.NetworkSecurityGroupIds,
-		FreeformTags: map[string]string{
-			"IngressName":      spec.Ingress.Name,
-			"IngressNamespace": spec.Ingress.Namespace,
-			"IngressUID":       string(spec.Ingress.UID),
-		},
-		RuleSets: spec.RuleSets,
+		FreeformTags:            specOwnerTags(spec),
+		RuleSets:                spec.RuleSets,
 	}
 	listeners := map[string]loadbalancer.ListenerDetails{}
 	//XXX: Workaround #1:
@@ -216,6 +572,7 @@ func (mgr *lbManager) createLoadBalancer(ctx context.Context, spec *ingress.Ingr
 			},
 		}
 	}
+	mgr.recorder.Eventf(spec.Object(), corev1.EventTypeNormal, ReasonCreatingLoadBalancer, "Creating load balancer %s", spec.Name)
 	logger.Info("Create a new LB: " + regexp.MustCompile(`-----BEGIN[\s\w\\+/=-]+-----END`).ReplaceAllString(utils.Jsonify(createDetails), "-----BEGIN ***** -----END"))
 	wrID, err := mgr.client.LoadBalancer().CreateLoadBalancer(ctx, createDetails)
 	if err != nil {
@@ -224,6 +581,7 @@ func (mgr *lbManager) createLoadBalancer(ctx context.Context, spec *ingress.Ingr
 	logger.With("wrID", wrID).Info("Awaiting work request completion")
 	wr, err := mgr.client.LoadBalancer().AwaitWorkRequest(ctx, wrID)
 	if err != nil {
+		mgr.recorder.Eventf(spec.Object(), corev1.EventTypeWarning, ReasonWorkRequestFailed, "Work request %s failed: create load balancer %s: %s", wrID, spec.Name, err)
 		return nil, errors.Wrap(err, "awaiting load balancer")
 	}
 
@@ -234,6 +592,7 @@ func (mgr *lbManager) createLoadBalancer(ctx context.Context, spec *ingress.Ingr
 	lbOcid := *lb.Id
 	logger = logger.With("loadBalancerID", lbOcid)
 	logger.Info("LB created")
+	mgr.recorder.Eventf(spec.Object(), corev1.EventTypeNormal, ReasonCreatedLoadBalancer, "Created load balancer %s (%s)", lbOcid, spec.Name)
 	return lb, nil
 }
 
@@ -260,6 +619,14 @@ func (mgr *lbManager) updateLoadBalancer(ctx context.Context, lb *loadbalancer.L
 	if err := ad.Run(UpdateAction, "routingpolicy"); err != nil {
 		return nil, err
 	}
+	// Set only now, as failures of the first UpdateLoadBalancer() call above are expected
+	spec.OnActionApplied = func(action oci.Action, err error) {
+		if err != nil {
+			mgr.recorder.Eventf(spec.Object(), corev1.EventTypeWarning, ReasonWorkRequestFailed, "Failed to %s %s %q: %s", action.Type(), action.Entity(), action.Name(), err)
+		} else {
+			mgr.recorder.Eventf(spec.Object(), corev1.EventTypeNormal, ReasonWorkRequestSucceeded, "Applied %s %s %q", action.Type(), action.Entity(), action.Name())
+		}
+	}
 	if err := mgr.dummyCp.UpdateLoadBalancer(ctx, lb, &spec.LBSpec); err != nil { // Listener, BackendSet
 		return nil, err
 	}
@@ -296,7 +663,7 @@ func (mgr *lbManager) enqueueRoutingPoliciesActions(ad *ActionDispatcher, lb *lo
 	for policyName_ := range toBeCreated {
 		policyName := policyName_
 		requiredPolicy := spec.RoutingPolicies[policyName]
-		ad.AddFunc(CreateAction, "routingpolicy", func() error {
+		ad.AddFunc(CreateAction, "routingpolicy", policyName, func() error {
 			logger.Infof("Creating routingpolicy %q | %+v", policyName, requiredPolicy.Rules)
 			createRoutingPolicyDetails := loadbalancer.CreateRoutingPolicyDetails{
 				Name:                     utils.PtrToString(policyName),
@@ -305,14 +672,14 @@ func (mgr *lbManager) enqueueRoutingPoliciesActions(ad *ActionDispatcher, lb *lo
 			}
 			logger.Debugf("CreateRoutingPolicyDetails: %s", utils.Jsonify(createRoutingPolicyDetails))
 			wrID, err := mgr.client.LoadBalancer().CreateRoutingPolicy(ctx, lbOcid, createRoutingPolicyDetails)
-			return mgr.awaitRequest(ctx, wrID, err, func() { patchLbInfo(policyName, true) }, "create routingpolicy %q", policyName)
+			return mgr.awaitRequest(ctx, spec.Object(), wrID, err, func() { patchLbInfo(policyName, true) }, "create routingpolicy %q", policyName)
 		})
 	}
 
 	for policyName_ := range toBeUpdated {
 		policyName := policyName_
 		requiredPolicy := spec.RoutingPolicies[policyName]
-		ad.AddFunc(UpdateAction, "routingpolicy", func() error {
+		ad.AddFunc(UpdateAction, "routingpolicy", policyName, func() error {
 			logger.Infof("Updating existing routingpolicy %q", policyName)
 			updateRoutingPolicyDetails := loadbalancer.UpdateRoutingPolicyDetails{
 				Rules:                    requiredPolicy.Rules,
@@ -320,16 +687,16 @@ func (mgr *lbManager) enqueueRoutingPoliciesActions(ad *ActionDispatcher, lb *lo
 			}
 			logger.Debugf("UpdateRoutingPolicyDetails: %s", utils.Jsonify(updateRoutingPolicyDetails))
 			wrID, err := mgr.client.LoadBalancer().UpdateRoutingPolicy(ctx, lbOcid, policyName, updateRoutingPolicyDetails)
-			return mgr.awaitRequest(ctx, wrID, err, func() { patchLbInfo(policyName, true) }, "update routingpolicy %q", policyName)
+			return mgr.awaitRequest(ctx, spec.Object(), wrID, err, func() { patchLbInfo(policyName, true) }, "update routingpolicy %q", policyName)
 		})
 	}
 
 	for policyName_ := range toBeRemoved {
 		policyName := policyName_
-		ad.AddFunc(DeleteAction, "routingpolicy", func() error {
+		ad.AddFunc(DeleteAction, "routingpolicy", policyName, func() error {
 			logger.Infof("Deleting existing routingpolicy %q", policyName)
 			wrID, err := mgr.client.LoadBalancer().DeleteRoutingPolicy(ctx, lbOcid, policyName)
-			return mgr.awaitRequest(ctx, wrID, err, func() { patchLbInfo(policyName, false) }, "delete routingpolicy %q", policyName)
+			return mgr.awaitRequest(ctx, spec.Object(), wrID, err, func() { patchLbInfo(policyName, false) }, "delete routingpolicy %q", policyName)
 		})
 	}
 
@@ -359,7 +726,7 @@ func (mgr *lbManager) enqueueRuleSetsActions(ad *ActionDispatcher, lb *loadbalan
 	for ruleSetName_ := range toBeCreated {
 		ruleSetName := ruleSetName_
 		requiredRuleSet := spec.RuleSets[ruleSetName]
-		ad.AddFunc(CreateAction, "ruleSet", func() error {
+		ad.AddFunc(CreateAction, "ruleSet", ruleSetName, func() error {
 			logger.Infof("Creating ruleSet %q | %+v", ruleSetName, requiredRuleSet.Items)
 			createRuleSetDetails := loadbalancer.CreateRuleSetDetails{
 				Name:  utils.PtrToString(ruleSetName),
@@ -367,28 +734,28 @@ func (mgr *lbManager) enqueueRuleSetsActions(ad *ActionDispatcher, lb *loadbalan
 			}
 			logger.Debugf("CreateRuleSetDetails: %s", utils.Jsonify(createRuleSetDetails))
 			wrID, err := mgr.client.LoadBalancer().CreateRuleSet(ctx, lbOcid, createRuleSetDetails)
-			return mgr.awaitRequest(ctx, wrID, err, func() { patchLbInfo(ruleSetName, true) }, "create RuleSet %q", ruleSetName)
+			return mgr.awaitRequest(ctx, spec.Object(), wrID, err, func() { patchLbInfo(ruleSetName, true) }, "create RuleSet %q", ruleSetName)
 		})
 	}
 
 	for ruleSetName_ := range toBeUpdated {
 		ruleSetName := ruleSetName_
 		requiredRuleSet := spec.RuleSets[ruleSetName]
-		ad.AddFunc(UpdateAction, "ruleSet", func() error {
+		ad.AddFunc(UpdateAction, "ruleSet", ruleSetName, func() error {
 			logger.Infof("Updating existing ruleSet %q", ruleSetName)
 			updateRuleSetDetails := loadbalancer.UpdateRuleSetDetails(requiredRuleSet)
 			logger.Debugf("UpdateRuleSetDetails: %s", utils.Jsonify(updateRuleSetDetails))
 			wrID, err := mgr.client.LoadBalancer().UpdateRuleSet(ctx, lbOcid, ruleSetName, updateRuleSetDetails)
-			return mgr.awaitRequest(ctx, wrID, err, func() { patchLbInfo(ruleSetName, true) }, "update ruleSet %q", ruleSetName)
+			return mgr.awaitRequest(ctx, spec.Object(), wrID, err, func() { patchLbInfo(ruleSetName, true) }, "update ruleSet %q", ruleSetName)
 		})
 	}
 
 	for ruleSetName_ := range toBeRemoved {
 		ruleSetName := ruleSetName_
-		ad.AddFunc(DeleteAction, "ruleSet", func() error {
+		ad.AddFunc(DeleteAction, "ruleSet", ruleSetName, func() error {
 			logger.Infof("Deleting existing ruleSet %q", ruleSetName)
 			wrID, err := mgr.client.LoadBalancer().DeleteRuleSet(ctx, lbOcid, ruleSetName)
-			return mgr.awaitRequest(ctx, wrID, err, func() { patchLbInfo(ruleSetName, false) }, "delete ruleSet %q", ruleSetName)
+			return mgr.awaitRequest(ctx, spec.Object(), wrID, err, func() { patchLbInfo(ruleSetName, false) }, "delete ruleSet %q", ruleSetName)
 		})
 	}
 }
@@ -415,20 +782,20 @@ func (mgr *lbManager) enqueueHostnameActions(ad *ActionDispatcher, lb *loadbalan
 
 	for hostnameName_ := range toBeCreated {
 		hostnameName := hostnameName_
-		ad.AddFunc(CreateAction, "hostname", func() error {
+		ad.AddFunc(CreateAction, "hostname", hostnameName, func() error {
 			logger.Infof("Creating hostname %q", hostnameName)
 			wrID, err := mgr.client.LoadBalancer().CreateHostname(ctx, lbOcid, spec.HostnameDetails[hostnameName])
-			return mgr.awaitRequest(ctx, wrID, err, func() { patchLbInfo(hostnameName, true) }, "create hostname %q", hostnameName)
+			return mgr.awaitRequest(ctx, spec.Object(), wrID, err, func() { patchLbInfo(hostnameName, true) }, "create hostname %q", hostnameName)
 		})
 	}
 
 	for hostnameName_ := range toBeRemoved {
 		hostnameName := hostnameName_
-		ad.AddFunc(DeleteAction, "hostname", func() error {
+		ad.AddFunc(DeleteAction, "hostname", hostnameName, func() error {
 			logger.Infof("Removing hostname %q", hostnameName)
 			// TODO check if it is used in any listener, if so remove the listener.  It will be created back later when called updateListeners()
 			wrID, err := mgr.client.LoadBalancer().DeleteHostname(ctx, lbOcid, hostnameName)
-			return mgr.awaitRequest(ctx, wrID, err, func() { patchLbInfo(hostnameName, false) }, "delete hostname %q", hostnameName)
+			return mgr.awaitRequest(ctx, spec.Object(), wrID, err, func() { patchLbInfo(hostnameName, false) }, "delete hostname %q", hostnameName)
 		})
 	}
 
@@ -467,31 +834,40 @@ func (mgr *lbManager) enqueueCertificateActions(ad *ActionDispatcher, lb *loadba
 	for certName_ := range toBeCreated {
 		certName := certName_
 		requiredCert := spec.Certificates[certName]
-		ad.AddFunc(CreateAction, "certificate", func() error {
+		ad.AddFunc(CreateAction, "certificate", certName, func() error {
 			// stringify certificate
 			logger.Infof("Creating certificate %q", certName)
 			wrID, err := mgr.client.LoadBalancer().CreateCertificate(ctx, lbOcid, requiredCert)
-			return mgr.awaitRequest(ctx, wrID, err, func() { patchLbInfo(certName, true) }, "create certificate %q", certName)
+			return mgr.awaitRequest(ctx, spec.Object(), wrID, err, func() {
+				patchLbInfo(certName, true)
+				if toBeRemoved.Len() > 0 {
+					// certificate names are derived from contents. A new one along with stale ones means rotation.
+					mgr.recorder.Eventf(spec.Object(), corev1.EventTypeNormal, ReasonCertificateRotated, "Certificate %q created to replace %v", certName, toBeRemoved.List())
+				}
+			}, "create certificate %q", certName)
 		})
 	}
 	for certName_ := range toBeRemoved {
 		certName := certName_
-		ad.AddFunc(DeleteAction, "certificate", func() error {
+		ad.AddFunc(DeleteAction, "certificate", certName, func() error {
 			logger.Infof("Deleting existing certificate %q", certName)
 			wrID, err := mgr.client.LoadBalancer().DeleteCertificate(ctx, lbOcid, certName)
-			return mgr.awaitRequest(ctx, wrID, err, func() { patchLbInfo(certName, false) }, "delete certificate %q", certName)
+			return mgr.awaitRequest(ctx, spec.Object(), wrID, err, func() { patchLbInfo(certName, false) }, "delete certificate %q", certName)
 		})
 	}
 }
 
-func (mgr *lbManager) awaitRequest(ctx context.Context, wrID string, err error, onSuccess func(), fmt string, args ...interface{}) error {
+func (mgr *lbManager) awaitRequest(ctx context.Context, obj k8sclient.Object, wrID string, err error, onSuccess func(), format string, args ...interface{}) error {
 	if err != nil {
-		return errors.Wrapf(err, fmt, args...)
+		mgr.recorder.Eventf(obj, corev1.EventTypeWarning, ReasonWorkRequestFailed, "Failed to %s: %s", fmt.Sprintf(format, args...), err)
+		return errors.Wrapf(err, format, args...)
 	}
 	_, err = mgr.client.LoadBalancer().AwaitWorkRequest(ctx, wrID)
 	if err != nil {
-		return errors.Wrapf(err, "await:"+fmt, args...)
+		mgr.recorder.Eventf(obj, corev1.EventTypeWarning, ReasonWorkRequestFailed, "Work request %s failed: %s: %s", wrID, fmt.Sprintf(format, args...), err)
+		return errors.Wrapf(err, "await:"+format, args...)
 	}
+	mgr.recorder.Eventf(obj, corev1.EventTypeNormal, ReasonWorkRequestSucceeded, "Applied %s", fmt.Sprintf(format, args...))
 	if onSuccess != nil {
 		onSuccess()
 	}
@@ -0,0 +1,36 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/apis/v1alpha1"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/configholder"
+	"github.com/oracle/oci-go-sdk/v46/common"
+	"github.com/oracle/oci-go-sdk/v46/loadbalancer"
+	"github.com/stretchr/testify/assert"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCheckAdoptable(t *testing.T) {
+	conf := configholder.WithIngressClassParameters(nil, &v1alpha1.IngressClassParametersSpec{CompartmentID: "ocid1.compartment.oc1..ours"})
+	lb := func(compartment string, tags map[string]string) *loadbalancer.LoadBalancer {
+		return &loadbalancer.LoadBalancer{Id: common.String("ocid1.loadbalancer.oc1..lb"), DisplayName: common.String("lb"), CompartmentId: common.String(compartment), FreeformTags: tags}
+	}
+	ing := &networking.Ingress{}
+	optedIn := &networking.Ingress{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{oci.IngressAnnotationPrefix + oci.AnnotationAdoptLoadBalancer: "true"}}}
+
+	assert.NoError(t, checkAdoptable(conf, ing, lb("ocid1.compartment.oc1..ours", map[string]string{FreeformTagIngressUID: "gone"})))
+	assert.NoError(t, checkAdoptable(conf, ing, lb("ocid1.compartment.oc1..ours", map[string]string{FreeformTagLoadBalancerGroup: "web"})))
+	assert.Error(t, checkAdoptable(conf, ing, lb("ocid1.compartment.oc1..ours", nil)), "untagged load balancer, eg: of a Service")
+	assert.NoError(t, checkAdoptable(conf, optedIn, lb("ocid1.compartment.oc1..ours", nil)))
+	assert.Error(t, checkAdoptable(conf, optedIn, lb("ocid1.compartment.oc1..theirs", nil)), "other compartment")
+	assert.Error(t, checkAdoptable(conf, ing, lb("ocid1.compartment.oc1..theirs", map[string]string{FreeformTagIngressUID: "gone"})), "other compartment")
+
+	ClusterID = "cluster-ours"
+	defer func() { ClusterID = "" }()
+	assert.NoError(t, checkAdoptable(conf, ing, lb("ocid1.compartment.oc1..ours", map[string]string{FreeformTagIngressUID: "gone", FreeformTagClusterID: "cluster-ours"})))
+	assert.NoError(t, checkAdoptable(conf, ing, lb("ocid1.compartment.oc1..ours", map[string]string{FreeformTagIngressUID: "gone"})), "tagged before the cluster was identified")
+	assert.Error(t, checkAdoptable(conf, optedIn, lb("ocid1.compartment.oc1..ours", map[string]string{FreeformTagIngressUID: "gone", FreeformTagClusterID: "cluster-theirs"})), "other cluster")
+}
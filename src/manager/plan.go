@@ -0,0 +1,90 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/ingress"
+	"github.com/oracle/oci-go-sdk/v46/loadbalancer"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PlanOnly makes the controller only publish the changes it would apply on load balancers, for every ingress.
+// A single ingress can be put in plan mode with the oci-plan-only annotation.
+var PlanOnly = false
+
+// ErrPlanOnly is returned by DeleteIngress() in plan mode. The load balancer and the finalizer of the ingress are kept.
+var ErrPlanOnly = errors.New("plan mode: load balancer is not deleted")
+
+// maxPlanEventLength limits the plan published as an event. Full plan is always logged.
+const maxPlanEventLength = 1024
+
+// IsPlanOnly tells whether changes for the ingress (or gateway) are only planned, not applied
+func IsPlanOnly(obj oci.AnnotatedObject) bool {
+	switch oci.GetAnnotationWithLowercase(obj, oci.AnnotationPlanOnly) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return PlanOnly
+}
+
+// planLoadBalancer lists the changes updateLoadBalancer() would apply to bring lb to spec, in the order they would be applied.
+// lb is nil if the load balancer doesn't exist yet. No mutating OCI API is called.
+func (mgr *lbManager) planLoadBalancer(ctx context.Context, lb *loadbalancer.LoadBalancer, spec *ingress.IngressLBSpec) []string {
+	var plan []string
+	if lb == nil {
+		plan = append(plan, fmt.Sprintf("create load balancer %q shape=%s", spec.Name, spec.Shape))
+		// Plan against an empty load balancer. Items other than routing policies are created along with it.
+		lb = &loadbalancer.LoadBalancer{Id: &spec.Name, DisplayName: &spec.Name, ShapeName: &spec.Shape, NetworkSecurityGroupIds: spec.NetworkSecurityGroupIds}
+		if spec.IsFlexibleShape() {
+			lb.ShapeDetails = &loadbalancer.ShapeDetails{MinimumBandwidthInMbps: spec.FlexMin, MaximumBandwidthInMbps: spec.FlexMax}
+		}
+	} else if !specOwnsLoadBalancer(spec, lb) {
+		plan = append(plan, fmt.Sprintf("update freeform tags of load balancer %q", *lb.Id))
+	}
+	logger := mgr.logger.With("loadBalancerID", *lb.Id).With("loadBalancerName", lb.DisplayName)
+
+	ad := &ActionDispatcher{ctx: ctx, logger: logger}
+	mgr.enqueueRoutingPoliciesActions(ad, lb, spec)
+	mgr.enqueueRuleSetsActions(ad, lb, spec)
+	mgr.enqueueHostnameActions(ad, lb, spec)
+	mgr.enqueueCertificateActions(ad, lb, spec)
+
+	changes := oci.GetLoadBalancerChanges(ctx, logger, lb, &spec.LBSpec)
+	if changes.ShapeChanged {
+		plan = append(plan, fmt.Sprintf("update shape to %s", spec.Shape))
+	}
+	if changes.NetworkSecurityGroupsChanged {
+		plan = append(plan, fmt.Sprintf("update network security groups to %v", spec.NetworkSecurityGroupIds))
+	}
+	for _, action := range changes.Actions {
+		plan = append(plan, fmt.Sprintf("%s %s %q", action.Type(), action.Entity(), action.Name()))
+	}
+	plan = append(plan, ad.Plan(CreateAction)...)
+	// Routing policies are updated first, but Plan() doesn't mark actions done. So updates are planned at once.
+	plan = append(plan, ad.Plan(UpdateAction)...)
+	plan = append(plan, ad.Plan(DeleteAction)...)
+	return plan
+}
+
+// publishPlan logs the plan and records it as an event on the ingress (or gateway)
+func (mgr *lbManager) publishPlan(obj k8sclient.Object, plan []string) {
+	logger := mgr.logger.With(objectKind(obj), fmt.Sprintf("%s/%s", obj.GetNamespace(), obj.GetName()))
+	if len(plan) == 0 {
+		logger.Info("Plan: no changes")
+		mgr.recorder.Event(obj, corev1.EventTypeNormal, ReasonPlanned, "Plan: no changes")
+		return
+	}
+	logger.With("changes", len(plan)).Info("Plan:\n  " + strings.Join(plan, "\n  "))
+	msg := fmt.Sprintf("Plan: %d change(s): %s", len(plan), strings.Join(plan, "; "))
+	if len(msg) > maxPlanEventLength {
+		msg = msg[:maxPlanEventLength-3] + "..."
+	}
+	mgr.recorder.Event(obj, corev1.EventTypeNormal, ReasonPlanned, msg)
+}
@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	ociclient "github.com/nom3ad/oci-lb-ingress-controller/pkg/oci/client"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const metricsNamespace = "oci_lb_ingress"
+
+var (
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "reconcile_duration_seconds",
+		Help:      "Duration of ingress reconciles, partitioned by ingress and result.",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 12),
+	}, []string{"namespace", "ingress", "result"})
+
+	managedLoadBalancers = &loadBalancerStateCollector{
+		desc: prometheus.NewDesc(metricsNamespace+"_managed_load_balancers",
+			"Number of load balancers managed by the controller, partitioned by lifecycle state.", []string{"state"}, nil),
+		states:        map[string]string{},
+		loadBalancers: map[types.NamespacedName]string{},
+	}
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(reconcileDuration, managedLoadBalancers)
+	ctrlmetrics.Registry.MustRegister(ociclient.Collectors()...)
+}
+
+// ObserveReconcile records the duration of a reconcile of the ingress
+func ObserveReconcile(ingress types.NamespacedName, startTime time.Time, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	reconcileDuration.WithLabelValues(ingress.Namespace, ingress.Name, result).Observe(time.Since(startTime).Seconds())
+}
+
+// ForgetIngress drops the per ingress series of a deleted ingress
+func ForgetIngress(ingress types.NamespacedName) {
+	for _, result := range []string{"success", "error"} {
+		reconcileDuration.DeleteLabelValues(ingress.Namespace, ingress.Name, result)
+	}
+	managedLoadBalancers.forget(ingress)
+}
+
+// SetLoadBalancerState records the last known lifecycle state of the load balancer of the ingress
+func SetLoadBalancerState(ingress types.NamespacedName, loadBalancerID, state string) {
+	managedLoadBalancers.set(ingress, loadBalancerID, state)
+}
+
+// loadBalancerStateCollector counts load balancers by their last known lifecycle state at scrape time.
+// A load balancer shared by a group of ingresses is counted once, as long as one of them is left.
+type loadBalancerStateCollector struct {
+	desc          *prometheus.Desc
+	mu            sync.Mutex
+	states        map[string]string               // by load balancer OCID
+	loadBalancers map[types.NamespacedName]string // load balancer OCID by ingress
+}
+
+func (c *loadBalancerStateCollector) set(ingress types.NamespacedName, loadBalancerID, state string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loadBalancers[ingress] = loadBalancerID
+	c.states[loadBalancerID] = state
+	c.prune()
+}
+
+func (c *loadBalancerStateCollector) forget(ingress types.NamespacedName) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.loadBalancers, ingress)
+	c.prune()
+}
+
+// prune drops states of load balancers no ingress uses anymore. Caller must hold the lock.
+func (c *loadBalancerStateCollector) prune() {
+	used := map[string]bool{}
+	for _, id := range c.loadBalancers {
+		used[id] = true
+	}
+	for id := range c.states {
+		if !used[id] {
+			delete(c.states, id)
+		}
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *loadBalancerStateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect implements prometheus.Collector
+func (c *loadBalancerStateCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	counts := map[string]int{}
+	for _, state := range c.states {
+		counts[state]++
+	}
+	c.mu.Unlock()
+	for state, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), state)
+	}
+}
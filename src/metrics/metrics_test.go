@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestLoadBalancerStateCollectorCountsSharedLoadBalancersOnce(t *testing.T) {
+	c := &loadBalancerStateCollector{states: map[string]string{}, loadBalancers: map[types.NamespacedName]string{}}
+	web := types.NamespacedName{Namespace: "team-a", Name: "web"}
+	api := types.NamespacedName{Namespace: "team-b", Name: "api"}
+
+	c.set(web, "ocid1.loadbalancer.oc1..shared", "ACTIVE")
+	c.set(api, "ocid1.loadbalancer.oc1..shared", "ACTIVE")
+	assert.Equal(t, map[string]string{"ocid1.loadbalancer.oc1..shared": "ACTIVE"}, c.states)
+
+	c.forget(web)
+	assert.Len(t, c.states, 1, "still used by another member")
+
+	// Left the group for a load balancer of its own
+	c.set(api, "ocid1.loadbalancer.oc1..own", "ACTIVE")
+	assert.Equal(t, map[string]string{"ocid1.loadbalancer.oc1..own": "ACTIVE"}, c.states)
+
+	c.forget(api)
+	assert.Empty(t, c.states)
+}
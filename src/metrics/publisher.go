@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/oracle/oci-go-sdk/v46/common"
+	"github.com/oracle/oci-go-sdk/v46/monitoring"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// maxMetricObjectsPerRequest is the limit of metric objects accepted by a single PostMetricData call
+const maxMetricObjectsPerRequest = 50
+
+// Publisher publishes metric data points to a metrics backend
+type Publisher interface {
+	Publish(ctx context.Context, data []monitoring.MetricDataDetails) error
+}
+
+// ociMonitoringPublisher publishes metric data points to the OCI Monitoring custom metrics API
+type ociMonitoringPublisher struct {
+	client monitoring.MonitoringClient
+	logger *zap.SugaredLogger
+}
+
+// NewOCIMonitoringPublisher returns a Publisher posting to the telemetry ingestion endpoint of the configured region.
+// If endpoint is set, it is used instead.
+func NewOCIMonitoringPublisher(cp common.ConfigurationProvider, endpoint string, logger *zap.Logger) (Publisher, error) {
+	client, err := monitoring.NewMonitoringClientWithConfigurationProvider(cp)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewMonitoringClientWithConfigurationProvider")
+	}
+	if endpoint == "" {
+		region, err := cp.Region()
+		if err != nil {
+			return nil, errors.Wrap(err, "Couldn't get region")
+		}
+		// metric data can only be posted to the ingestion endpoint
+		endpoint = common.StringToRegion(region).EndpointForTemplate("telemetry-ingestion", "https://telemetry-ingestion.{region}.{secondLevelDomain}")
+	}
+	client.Host = endpoint
+	return &ociMonitoringPublisher{client: client, logger: logger.Sugar().Named("oci-monitoring")}, nil
+}
+
+// Publish posts data in batches. Metric objects failing validation are logged, but not treated as an error.
+func (p *ociMonitoringPublisher) Publish(ctx context.Context, data []monitoring.MetricDataDetails) error {
+	for start := 0; start < len(data); start += maxMetricObjectsPerRequest {
+		end := start + maxMetricObjectsPerRequest
+		if end > len(data) {
+			end = len(data)
+		}
+		resp, err := p.client.PostMetricData(ctx, monitoring.PostMetricDataRequest{
+			PostMetricDataDetails: monitoring.PostMetricDataDetails{
+				MetricData:     data[start:end],
+				BatchAtomicity: monitoring.PostMetricDataDetailsBatchAtomicityNonAtomic,
+			},
+		})
+		if err != nil {
+			return errors.Wrapf(err, "post metric data [%d:%d]", start, end)
+		}
+		if resp.FailedMetricsCount != nil && *resp.FailedMetricsCount > 0 {
+			for _, failed := range resp.FailedMetrics {
+				p.logger.With("metric", failed.MetricData.Name, "message", failed.Message).Warn("Metric object rejected by OCI Monitoring")
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"context"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	providercfg "github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci/config"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/utils"
+	"github.com/oracle/oci-go-sdk/v46/common"
+	"github.com/oracle/oci-go-sdk/v46/monitoring"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// OCIMonitoringPushInterval is the interval between two pushes of metrics to OCI Monitoring
+var OCIMonitoringPushInterval = 1 * time.Minute
+
+// pushedMetricsPrefix selects the metric families pushed. Those are the ones of the controller and of the OCI client.
+const pushedMetricsPrefix = "oci_"
+
+// reservedNamespacePrefixes are prefixes of OCI Monitoring namespaces custom metrics can't be published to
+var reservedNamespacePrefixes = []string{"oci_", "oracle_"}
+
+// ValidateMonitoringConfig reports a metrics config OCI Monitoring would reject every push of
+func ValidateMonitoringConfig(config providercfg.MetricsConfig) error {
+	if config.Namespace == "" {
+		return errors.New("metrics.namespace is required to push metrics to OCI Monitoring")
+	}
+	for _, prefix := range reservedNamespacePrefixes {
+		if strings.HasPrefix(strings.ToLower(config.Namespace), prefix) {
+			return errors.Errorf("metrics.namespace %q must not start with %q, it is reserved by OCI Monitoring", config.Namespace, prefix)
+		}
+	}
+	return nil
+}
+
+// MonitoringPusher periodically gathers the controller metrics and publishes them as OCI Monitoring custom metrics.
+// Every replica pushes its own metrics, distinguished by the "instance" dimension.
+type MonitoringPusher struct {
+	gatherer  prometheus.Gatherer
+	publisher Publisher
+	config    providercfg.MetricsConfig
+	instance  string
+	interval  time.Duration
+	logger    *zap.SugaredLogger
+}
+
+// NewMonitoringPusher returns a pusher gathering from the controller-runtime metrics registry.
+// compartmentID is used if the metrics config doesn't have one.
+func NewMonitoringPusher(publisher Publisher, config providercfg.MetricsConfig, compartmentID string, logger *zap.Logger) *MonitoringPusher {
+	if config.CompartmentID == "" {
+		config.CompartmentID = compartmentID
+	}
+	instance, _ := os.Hostname()
+	return &MonitoringPusher{
+		gatherer:  ctrlmetrics.Registry,
+		publisher: publisher,
+		config:    config,
+		instance:  instance,
+		interval:  OCIMonitoringPushInterval,
+		logger:    logger.Sugar().Named("monitoring-pusher"),
+	}
+}
+
+// Start implements manager.Runnable
+func (p *MonitoringPusher) Start(ctx context.Context) error {
+	p.logger.With("namespace", p.config.Namespace, "compartment", p.config.CompartmentID, "interval", p.interval).Info("Starting OCI Monitoring metrics pusher")
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := p.Push(ctx); err != nil {
+			p.logger.With(zap.Error(err)).Error("Failed to push metrics")
+		}
+	}, p.interval)
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable
+func (p *MonitoringPusher) NeedLeaderElection() bool {
+	return false
+}
+
+// Push gathers and publishes metrics once
+func (p *MonitoringPusher) Push(ctx context.Context) error {
+	families, err := p.gatherer.Gather()
+	if err != nil {
+		return errors.Wrap(err, "Couldn't gather metrics")
+	}
+	data := p.toMetricData(families, time.Now())
+	if len(data) == 0 {
+		return nil
+	}
+	p.logger.Debugf("Publishing %d metric objects", len(data))
+	return p.publisher.Publish(ctx, data)
+}
+
+// toMetricData converts prometheus metric families to OCI metric objects.
+// Counters and gauges are sent as is. Histograms and summaries are sent as their _sum and _count,
+// as OCI Monitoring has no notion of buckets. Counter values are cumulative, use rate() in MQL queries.
+func (p *MonitoringPusher) toMetricData(families []*dto.MetricFamily, now time.Time) []monitoring.MetricDataDetails {
+	var data []monitoring.MetricDataDetails
+	add := func(name string, labels []*dto.LabelPair, value float64) {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return
+		}
+		dimensions := map[string]string{"instance": p.instance}
+		for _, l := range labels {
+			if l.GetValue() != "" {
+				dimensions[l.GetName()] = l.GetValue()
+			}
+		}
+		details := monitoring.MetricDataDetails{
+			Namespace:     utils.PtrToString(p.config.Namespace),
+			CompartmentId: utils.PtrToString(p.config.CompartmentID),
+			Name:          utils.PtrToString(p.config.Prefix + name),
+			Dimensions:    dimensions,
+			Datapoints:    []monitoring.Datapoint{{Timestamp: &common.SDKTime{Time: now}, Value: &value}},
+		}
+		if p.config.ResourceGroup != "" {
+			details.ResourceGroup = utils.PtrToString(p.config.ResourceGroup)
+		}
+		data = append(data, details)
+	}
+	for _, family := range families {
+		name := family.GetName()
+		if !strings.HasPrefix(name, pushedMetricsPrefix) {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				add(name, m.GetLabel(), m.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				add(name, m.GetLabel(), m.GetGauge().GetValue())
+			case dto.MetricType_UNTYPED:
+				add(name, m.GetLabel(), m.GetUntyped().GetValue())
+			case dto.MetricType_HISTOGRAM:
+				add(name+"_sum", m.GetLabel(), m.GetHistogram().GetSampleSum())
+				add(name+"_count", m.GetLabel(), float64(m.GetHistogram().GetSampleCount()))
+			case dto.MetricType_SUMMARY:
+				add(name+"_sum", m.GetLabel(), m.GetSummary().GetSampleSum())
+				add(name+"_count", m.GetLabel(), float64(m.GetSummary().GetSampleCount()))
+			}
+		}
+	}
+	return data
+}
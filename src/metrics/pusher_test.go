@@ -0,0 +1,132 @@
+package metrics
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	providercfg "github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci/config"
+	"github.com/oracle/oci-go-sdk/v46/common"
+	"github.com/oracle/oci-go-sdk/v46/monitoring"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeMonitoringServer records PostMetricData requests
+type fakeMonitoringServer struct {
+	mu       sync.Mutex
+	requests []monitoring.PostMetricDataDetails
+}
+
+func (s *fakeMonitoringServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/20180401/metrics" {
+		http.Error(w, fmt.Sprintf("unexpected request %s %s", r.Method, r.URL.Path), http.StatusNotFound)
+		return
+	}
+	if r.Header.Get("Authorization") == "" {
+		http.Error(w, "request is not signed", http.StatusUnauthorized)
+		return
+	}
+	details := monitoring.PostMetricDataDetails{}
+	if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	s.requests = append(s.requests, details)
+	s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, `{"failedMetricsCount": 0, "failedMetrics": []}`)
+}
+
+func newTestPublisher(t *testing.T, endpoint string) Publisher {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	cp := common.NewRawConfigurationProvider("ocid1.tenancy.oc1..test", "ocid1.user.oc1..test", "us-phoenix-1", "aa:bb", string(keyPEM), nil)
+	publisher, err := NewOCIMonitoringPublisher(cp, endpoint, zap.NewNop())
+	require.NoError(t, err)
+	return publisher
+}
+
+func TestMonitoringPusher(t *testing.T) {
+	fake := &fakeMonitoringServer{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	registry := prometheus.NewRegistry()
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "oci_requests_total"}, []string{"verb", "resource"})
+	duration := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "oci_request_duration_seconds"})
+	ignored := prometheus.NewGauge(prometheus.GaugeOpts{Name: "go_goroutines"})
+	registry.MustRegister(requests, duration, ignored)
+	requests.WithLabelValues("get", "load_balancer").Add(3)
+	duration.Observe(0.5)
+	duration.Observe(1.5)
+	ignored.Set(10)
+
+	pusher := NewMonitoringPusher(newTestPublisher(t, server.URL), providercfg.MetricsConfig{Namespace: "lb_ingress", Prefix: "test_"}, "ocid1.compartment.oc1..test", zap.NewNop())
+	pusher.gatherer = registry
+	pusher.instance = "controller-0"
+	require.NoError(t, pusher.Push(context.Background()))
+
+	require.Len(t, fake.requests, 1)
+	values := map[string]float64{}
+	dimensions := map[string]map[string]string{}
+	for _, m := range fake.requests[0].MetricData {
+		assert.Equal(t, "lb_ingress", *m.Namespace)
+		assert.Equal(t, "ocid1.compartment.oc1..test", *m.CompartmentId)
+		assert.Nil(t, m.ResourceGroup)
+		assert.Equal(t, "controller-0", m.Dimensions["instance"])
+		require.Len(t, m.Datapoints, 1)
+		values[*m.Name] = *m.Datapoints[0].Value
+		dimensions[*m.Name] = m.Dimensions
+	}
+	assert.Equal(t, map[string]float64{
+		"test_oci_requests_total":                 3,
+		"test_oci_request_duration_seconds_sum":   2,
+		"test_oci_request_duration_seconds_count": 2,
+	}, values)
+	assert.Equal(t, map[string]string{"instance": "controller-0", "verb": "get", "resource": "load_balancer"}, dimensions["test_oci_requests_total"])
+}
+
+func TestOCIMonitoringPublisherBatches(t *testing.T) {
+	fake := &fakeMonitoringServer{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	data := make([]monitoring.MetricDataDetails, 120)
+	for i := range data {
+		value := float64(i)
+		data[i] = monitoring.MetricDataDetails{
+			Namespace:     common.String("lb_ingress"),
+			CompartmentId: common.String("ocid1.compartment.oc1..test"),
+			Name:          common.String(fmt.Sprintf("metric_%d", i)),
+			Dimensions:    map[string]string{"instance": "controller-0"},
+			Datapoints:    []monitoring.Datapoint{{Timestamp: &common.SDKTime{}, Value: &value}},
+		}
+	}
+	require.NoError(t, newTestPublisher(t, server.URL).Publish(context.Background(), data))
+
+	require.Len(t, fake.requests, 3)
+	assert.Len(t, fake.requests[0].MetricData, maxMetricObjectsPerRequest)
+	assert.Len(t, fake.requests[1].MetricData, maxMetricObjectsPerRequest)
+	assert.Len(t, fake.requests[2].MetricData, 20)
+	assert.Equal(t, "metric_119", *fake.requests[2].MetricData[19].Name)
+}
+
+func TestValidateMonitoringConfig(t *testing.T) {
+	assert.NoError(t, ValidateMonitoringConfig(providercfg.MetricsConfig{Namespace: "lb_ingress_controller"}))
+	assert.Error(t, ValidateMonitoringConfig(providercfg.MetricsConfig{}))
+	assert.Error(t, ValidateMonitoringConfig(providercfg.MetricsConfig{Namespace: "oci_lb_ingress_controller"}))
+	assert.Error(t, ValidateMonitoringConfig(providercfg.MetricsConfig{Namespace: "Oracle_lb"}))
+}
@@ -0,0 +1,209 @@
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	providercfg "github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci/config"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/configholder"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/ingress"
+	"github.com/oracle/oci-go-sdk/v46/loadbalancer"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+)
+
+// Redacted replaces key material in rendered certificates
+const Redacted = "<redacted>"
+
+// Placeholders used for values that are only known with access to OCI
+const (
+	PlaceholderCompartmentID = "ocid1.compartment.oc1..placeholder"
+	PlaceholderSubnetID      = "ocid1.subnet.oc1..placeholder"
+)
+
+// Spec is the OCI load balancer an ingress translates to
+type Spec struct {
+	Ingress         string                                     `json:"ingress"`
+	Name            string                                     `json:"name"`
+	Shape           string                                     `json:"shape"`
+	FlexMin         *int                                       `json:"flexMin,omitempty"`
+	FlexMax         *int                                       `json:"flexMax,omitempty"`
+	Internal        bool                                       `json:"internal"`
+	Subnets         []string                                   `json:"subnets"`
+	LoadBalancerIP  string                                     `json:"loadBalancerIP,omitempty"`
+	Listeners       map[string]loadbalancer.ListenerDetails    `json:"listeners"`
+	BackendSets     map[string]loadbalancer.BackendSetDetails  `json:"backendSets"`
+	RoutingPolicies map[string]loadbalancer.RoutingPolicy      `json:"routingPolicies"`
+	RuleSets        map[string]loadbalancer.RuleSetDetails     `json:"ruleSets"`
+	Hostnames       map[string]loadbalancer.HostnameDetails    `json:"hostnames"`
+	Certificates    map[string]loadbalancer.CertificateDetails `json:"certificates"`
+}
+
+// Options of Render()
+type Options struct {
+	// Subnets are used unless set by ingress annotations. Placeholder is used if empty, as subnet discovery requires OCI access.
+	Subnets []string
+}
+
+// Render translates every Ingress found in the manifests into the load balancer spec the controller would apply.
+// Services, Nodes and Secrets referenced by ingresses must be present in the manifests too, Nodes being Ready. So must be
+// EndpointSlices of services, for ingresses targeting pods and for services with externalTrafficPolicy=Local.
+// No cluster or OCI access is needed.
+func Render(manifests []io.Reader, opts Options, logger *zap.Logger) ([]Spec, error) {
+	var objects []runtime.Object
+	for _, r := range manifests {
+		objs, err := decodeManifests(r)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, objs...)
+	}
+	var ingresses []*networking.Ingress
+	for _, obj := range objects {
+		// as kubectl does, namespaced objects without a namespace go to the default one
+		if _, isNode := obj.(*corev1.Node); !isNode && obj.(metav1.Object).GetNamespace() == "" {
+			obj.(metav1.Object).SetNamespace(corev1.NamespaceDefault)
+		}
+		if ing, ok := obj.(*networking.Ingress); ok {
+			ingresses = append(ingresses, ing)
+		}
+	}
+	if len(ingresses) == 0 {
+		return nil, errors.New("no ingress found in manifests")
+	}
+	sort.Slice(ingresses, func(i, j int) bool {
+		return ingresses[i].Namespace+"/"+ingresses[i].Name < ingresses[j].Namespace+"/"+ingresses[j].Name
+	})
+
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithRuntimeObjects(objects...).Build()
+	lbConf := &providercfg.LoadBalancerConfig{Subnet1: PlaceholderSubnetID}
+	if len(opts.Subnets) > 0 {
+		lbConf.Subnet1 = opts.Subnets[0]
+	}
+	if len(opts.Subnets) > 1 {
+		lbConf.Subnet2 = opts.Subnets[1]
+	}
+	conf := configholder.NewConfigHolder(&providercfg.Config{CompartmentID: PlaceholderCompartmentID, LoadBalancer: lbConf})
+
+	var specs []Spec
+	for _, ing := range ingresses {
+		// OCI client is only needed for subnet discovery, which never happens as subnets are always configured
+		spec, err := ingress.NewIngressLBSpec(conf, ing, nil, k8sClient, logger)
+		if err != nil {
+			return nil, errors.Wrapf(err, "render ingress %s/%s", ing.Namespace, ing.Name)
+		}
+		specs = append(specs, newSpec(spec))
+	}
+	return specs, nil
+}
+
+func newSpec(spec *ingress.IngressLBSpec) Spec {
+	certificates := map[string]loadbalancer.CertificateDetails{}
+	for name, cert := range spec.Certificates {
+		if cert.PrivateKey != nil {
+			cert.PrivateKey = redacted()
+		}
+		if cert.Passphrase != nil {
+			cert.Passphrase = redacted()
+		}
+		certificates[name] = cert
+	}
+	return Spec{
+		Ingress:         spec.Ingress.Namespace + "/" + spec.Ingress.Name,
+		Name:            spec.Name,
+		Shape:           spec.Shape,
+		FlexMin:         spec.FlexMin,
+		FlexMax:         spec.FlexMax,
+		Internal:        spec.Internal,
+		Subnets:         spec.Subnets,
+		LoadBalancerIP:  spec.LoadBalancerIP,
+		Listeners:       spec.Listeners,
+		BackendSets:     spec.BackendSets,
+		RoutingPolicies: spec.RoutingPolicies,
+		RuleSets:        spec.RuleSets,
+		Hostnames:       spec.HostnameDetails,
+		Certificates:    certificates,
+	}
+}
+
+func redacted() *string {
+	s := Redacted
+	return &s
+}
+
+// decodeManifests reads a multi-document YAML or JSON stream. Items of List kinds are flattened.
+func decodeManifests(r io.Reader) ([]runtime.Object, error) {
+	decoder := scheme.Codecs.UniversalDeserializer()
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(r))
+	var objects []runtime.Object
+	var decode func(doc []byte) error
+	decode = func(doc []byte) error {
+		obj, gvk, err := decoder.Decode(doc, nil, nil)
+		if err != nil {
+			return errors.Wrap(err, "decode manifest")
+		}
+		if list, ok := obj.(*corev1.List); ok {
+			for _, item := range list.Items {
+				if err := decode(item.Raw); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		switch obj.(type) {
+		case *networking.Ingress, *corev1.Service, *corev1.Node, *corev1.Secret, *discoveryv1.EndpointSlice:
+			objects = append(objects, obj)
+		default:
+			return errors.Errorf("unsupported kind %s", gvk)
+		}
+		return nil
+	}
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "read manifest")
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		if err := decode(doc); err != nil {
+			return nil, err
+		}
+	}
+	return objects, nil
+}
+
+// Write prints specs in the given format, "json" or "yaml"
+func Write(w io.Writer, specs []Spec, format string) error {
+	data, err := json.MarshalIndent(specs, "", "  ")
+	if err != nil {
+		return err
+	}
+	switch format {
+	case "json":
+	case "yaml":
+		if data, err = yaml.JSONToYAML(data); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+	_, err = fmt.Fprintln(w, string(bytes.TrimSpace(data)))
+	return err
+}
@@ -0,0 +1,167 @@
+package render
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testIngress = `
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: web
+  annotations:
+    kubernetes.io/ingress.class: oci
+spec:
+  tls:
+  - hosts: [www.example.com]
+    secretName: web-tls
+  rules:
+  - host: www.example.com
+    http:
+      paths:
+      - path: /
+        pathType: Prefix
+        backend:
+          service:
+            name: web
+            port:
+              number: 80
+`
+
+const testService = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  type: NodePort
+  ports:
+  - port: 80
+    protocol: TCP
+    nodePort: 30080
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Node
+  metadata:
+    name: node-1
+  status:
+    addresses:
+    - type: InternalIP
+      address: 10.0.0.10
+    conditions:
+    - type: Ready
+      status: "True"
+`
+
+func testSecret(t *testing.T) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "www.example.com"},
+		DNSNames:     []string{"www.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return fmt.Sprintf(`
+apiVersion: v1
+kind: Secret
+metadata:
+  name: web-tls
+type: kubernetes.io/tls
+data:
+  tls.crt: %s
+  tls.key: %s
+`, base64.StdEncoding.EncodeToString(certPEM), base64.StdEncoding.EncodeToString(keyPEM))
+}
+
+func TestRender(t *testing.T) {
+	manifests := []io.Reader{strings.NewReader(testIngress), strings.NewReader(testService), strings.NewReader(testSecret(t))}
+	specs, err := Render(manifests, Options{}, zap.NewNop())
+	require.NoError(t, err)
+	require.Len(t, specs, 1)
+
+	spec := specs[0]
+	assert.Equal(t, "default/web", spec.Ingress)
+	assert.Equal(t, []string{PlaceholderSubnetID}, spec.Subnets)
+	assert.Contains(t, spec.Hostnames, "www.example.com")
+	assert.Contains(t, spec.Listeners, "wwwDOTexampleDOTcom")
+	require.Len(t, spec.Certificates, 1)
+	for _, cert := range spec.Certificates {
+		assert.Equal(t, Redacted, *cert.PrivateKey)
+		assert.Contains(t, *cert.PublicCertificate, "BEGIN CERTIFICATE")
+	}
+	backends := 0
+	for _, bs := range spec.BackendSets {
+		for _, b := range bs.Backends {
+			assert.Equal(t, "10.0.0.10", *b.IpAddress)
+			assert.Equal(t, 30080, *b.Port)
+			backends++
+		}
+	}
+	assert.Equal(t, 1, backends)
+
+	out := &bytes.Buffer{}
+	require.NoError(t, Write(out, specs, "json"))
+	assert.NotContains(t, out.String(), "PRIVATE KEY")
+	var decoded []Spec
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, spec.Name, decoded[0].Name)
+}
+
+const testEndpointSlice = `
+apiVersion: discovery.k8s.io/v1
+kind: EndpointSlice
+metadata:
+  name: web-abcde
+  labels:
+    kubernetes.io/service-name: web
+addressType: IPv4
+ports:
+- port: 8080
+  protocol: TCP
+endpoints:
+- addresses: [10.244.0.5]
+  conditions:
+    ready: true
+`
+
+func TestRenderPodBackends(t *testing.T) {
+	ing := strings.Replace(testIngress, "kubernetes.io/ingress.class: oci", "kubernetes.io/ingress.class: oci\n    ingress.beta.kubernetes.io/oci-backend-target: pod", 1)
+	manifests := []io.Reader{strings.NewReader(ing), strings.NewReader(testService), strings.NewReader(testSecret(t)), strings.NewReader(testEndpointSlice)}
+	specs, err := Render(manifests, Options{}, zap.NewNop())
+	require.NoError(t, err)
+	require.Len(t, specs, 1)
+
+	var backends []string
+	for _, bs := range specs[0].BackendSets {
+		for _, b := range bs.Backends {
+			backends = append(backends, fmt.Sprintf("%s:%d", *b.IpAddress, *b.Port))
+		}
+	}
+	assert.Equal(t, []string{"10.244.0.5:8080"}, backends)
+}
@@ -0,0 +1,93 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/nom3ad/oci-lb-ingress-controller/pkg/cloudprovider/providers/oci"
+	"github.com/nom3ad/oci-lb-ingress-controller/src/ingress"
+	"go.uber.org/zap"
+	admissionv1 "k8s.io/api/admission/v1"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// IngressValidationPath is the path the ingress validating webhook is served on
+const IngressValidationPath = "/validate-networking-k8s-io-v1-ingress"
+
+// ingressValidator rejects ingresses of our class that the controller would fail to translate into a load balancer
+type ingressValidator struct {
+	decoder *admission.Decoder
+	logger  *zap.SugaredLogger
+}
+
+// NewIngressValidator returns the admission webhook validating ingresses
+func NewIngressValidator(logger *zap.Logger) *admission.Webhook {
+	return &admission.Webhook{Handler: &ingressValidator{logger: logger.Sugar().Named("webhook")}}
+}
+
+// InjectDecoder is called by the webhook server
+func (v *ingressValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+// Handle validates ingresses of our class. Ingresses of other classes, ingresses being deleted and updates leaving
+// what users set unchanged are always allowed.
+func (v *ingressValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	ing := &networking.Ingress{}
+	if err := v.decoder.Decode(req, ing); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if !ingress.IsOCILoadbalancerIngress(ing, v.logger.Desugar()) {
+		return admission.Allowed("")
+	}
+	if ing.DeletionTimestamp != nil {
+		// Controller must be able to remove its finalizer, however invalid the ingress is
+		return admission.Allowed("")
+	}
+	if req.Operation == admissionv1.Update {
+		old := &networking.Ingress{}
+		if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if !userChanged(old, ing) {
+			// Patches of the controller (finalizer, sync results, draining backends) must not be rejected on an ingress
+			// created before the webhook was deployed, or before its rules were tightened
+			return admission.Allowed("")
+		}
+	}
+	if ing.Namespace == "" {
+		ing.Namespace = req.Namespace
+	}
+	if errs := ingress.ValidateIngress(ing); len(errs) > 0 {
+		v.logger.With("ingress", req.Namespace+"/"+req.Name, "operation", req.Operation).Infof("Rejected ingress: %s", errs.ToAggregate())
+		// Invalid status lists every offending field, as kubectl shows for built-in validation errors
+		status := apierrors.NewInvalid(schema.GroupKind{Group: networking.GroupName, Kind: "Ingress"}, req.Name, errs).ErrStatus
+		return admission.Response{AdmissionResponse: admissionv1.AdmissionResponse{Allowed: false, Result: &status}}
+	}
+	return admission.Allowed("").WithWarnings(ingress.WildcardHostWarnings(ing)...)
+}
+
+// userChanged tells whether the spec or the annotations set by users differ between old and ing
+func userChanged(old, ing *networking.Ingress) bool {
+	if !equality.Semantic.DeepEqual(old.Spec, ing.Spec) {
+		return true
+	}
+	return !equality.Semantic.DeepEqual(userAnnotations(old), userAnnotations(ing))
+}
+
+// userAnnotations returns the annotations of ing, without the ones maintained by the controller
+func userAnnotations(ing *networking.Ingress) map[string]string {
+	annotations := map[string]string{}
+	for k, v := range ing.Annotations {
+		annotations[k] = v
+	}
+	for _, name := range oci.ControllerIngressAnnotations {
+		delete(annotations, oci.IngressAnnotationPrefix+name)
+	}
+	return annotations
+}